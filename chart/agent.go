@@ -0,0 +1,188 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"raja.aiml/ai.explorer/llm"
+)
+
+// -------------------- Running a Chart --------------------
+
+// ReportFile is the combined report a run writes in its output dir.
+const ReportFile = "report.md"
+
+// A step is done once the model's answer is saved, and failed when the call
+// or the save fails. It is skipped, without a call, when a step it builds on
+// was not done or the run was cancelled.
+const (
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// StepResult is what carrying out one chart step produced.
+type StepResult struct {
+	ID       string
+	Title    string
+	Status   string // Error says why a step was not done
+	Output   string // Path of the saved result
+	Text     string
+	Duration time.Duration
+	Error    string
+}
+
+// Result summarizes a run, with steps in the order they ran.
+type Result struct {
+	Goal   string
+	Report string // Path of the combined report
+	Steps  []StepResult
+}
+
+// Failed lists the steps that were not done, in the order the agent reached them.
+func (r *Result) Failed() []string {
+	var ids []string
+	for _, s := range r.Steps {
+		if s.Status != StatusDone {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// Agent carries out the steps of a chart toward a goal, one LLM call per step.
+type Agent struct {
+	Client   llm.Generator
+	Target   llm.Target       // Recorded in each result's metadata
+	OnResult func(StepResult) // Called as each step finishes
+}
+
+// Run walks the steps of g in topological order. Each step is prompted with the
+// goal and the results of its predecessors, and its result is saved in outDir
+// as <id>.md. A failed step skips the steps that build on it. The report with
+// the annotated diagram is written even when steps fail; Run returns an error
+// when the run cannot start or ctx is cancelled.
+func (a *Agent) Run(ctx context.Context, g *Graph, goal, outDir string) (*Result, error) {
+	order, err := g.Order()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output dir '%s': %w", outDir, err)
+	}
+
+	res := &Result{Goal: goal, Report: filepath.Join(outDir, ReportFile)}
+	results := map[string]StepResult{}
+	for _, n := range order {
+		var step StepResult
+		if blocked := blockedBy(g, n.ID, results); blocked != "" {
+			step = StepResult{ID: n.ID, Title: n.Title, Status: StatusSkipped, Error: fmt.Sprintf("builds on %s, which did not succeed", blocked)}
+		} else if err := ctx.Err(); err != nil {
+			step = StepResult{ID: n.ID, Title: n.Title, Status: StatusSkipped, Error: err.Error()}
+		} else {
+			step = a.runStep(ctx, g, n, goal, outDir, results)
+		}
+		results[n.ID] = step
+		res.Steps = append(res.Steps, step)
+		if a.OnResult != nil {
+			a.OnResult(step)
+		}
+	}
+
+	if err := os.WriteFile(res.Report, []byte(Report(g, res)), 0644); err != nil {
+		return res, fmt.Errorf("error writing report: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (a *Agent) runStep(ctx context.Context, g *Graph, n Node, goal, outDir string, results map[string]StepResult) StepResult {
+	start := time.Now()
+	res := StepResult{ID: n.ID, Title: n.Title, Output: filepath.Join(outDir, n.ID+".md")}
+	fail := func(err error) StepResult {
+		res.Status, res.Error, res.Duration = StatusFailed, err.Error(), time.Since(start)
+		return res
+	}
+
+	var earlier []StepResult
+	for _, id := range g.Predecessors(n.ID) {
+		earlier = append(earlier, results[id])
+	}
+	text := StepPrompt(goal, n, earlier)
+	if err := os.WriteFile(filepath.Join(outDir, n.ID+".prompt.txt"), []byte(text), 0644); err != nil {
+		return fail(err)
+	}
+	resp, err := a.Client.Generate(ctx, text)
+	if err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(res.Output, []byte(resp.Text), 0644); err != nil {
+		return fail(err)
+	}
+	if err := llm.SaveMetadata(res.Output, llm.NewResponseMetadata(a.Target, resp)); err != nil {
+		return fail(err)
+	}
+	res.Status, res.Text, res.Duration = StatusDone, resp.Text, time.Since(start)
+	return res
+}
+
+// blockedBy returns the first predecessor of id that did not succeed.
+func blockedBy(g *Graph, id string, results map[string]StepResult) string {
+	for _, p := range g.Predecessors(id) {
+		if results[p].Status != StatusDone {
+			return p
+		}
+	}
+	return ""
+}
+
+// StepPrompt asks the model to carry out step n toward goal, building on the
+// results of the steps before it.
+func StepPrompt(goal string, n Node, earlier []StepResult) string {
+	var b strings.Builder
+	b.WriteString("You are working through a plan one step at a time.\n\n")
+	fmt.Fprintf(&b, "Goal:\n<<<\n%s\n>>>\n\n", strings.TrimSpace(goal))
+	fmt.Fprintf(&b, "Current step (%s): %s\n", n.Phase, n.Title)
+	if n.Description != "" {
+		fmt.Fprintf(&b, "What this step does: %s\n", n.Description)
+	}
+	if len(earlier) > 0 {
+		b.WriteString("\nResults of the steps this one builds on:\n")
+		for _, r := range earlier {
+			fmt.Fprintf(&b, "\n%s:\n<<<\n%s\n>>>\n", r.Title, strings.TrimSpace(r.Text))
+		}
+	}
+	b.WriteString("\nCarry out the current step for the goal, building on the earlier results " +
+		"without repeating them. Reply with the result of this step only.\n")
+	return b.String()
+}
+
+// Report combines the results of a run with the chart annotated by step status.
+func Report(g *Graph, res *Result) string {
+	statuses := map[string]string{}
+	for _, s := range res.Steps {
+		statuses[s.ID] = s.Status
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", strings.TrimSpace(res.Goal))
+	fmt.Fprintf(&b, "```mermaid\n%s```\n", g.Mermaid(statuses))
+	for _, s := range res.Steps {
+		n, _ := g.Node(s.ID)
+		fmt.Fprintf(&b, "\n## %s %s\n\n", statusIcons[s.Status], strings.TrimSpace(n.Emoji+" "+n.Title))
+		switch s.Status {
+		case StatusDone:
+			fmt.Fprintf(&b, "%s\n", strings.TrimSpace(s.Text))
+		case StatusFailed:
+			fmt.Fprintf(&b, "_Failed: %s_\n", s.Error)
+		default:
+			fmt.Fprintf(&b, "_Skipped: %s_\n", s.Error)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package chart
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// stepClient answers each step with its title and records the prompts it is sent.
+type stepClient struct {
+	prompts []string
+	fail    string // Title of a step whose call fails
+}
+
+func (c *stepClient) Generate(_ context.Context, prompt string) (*llm.Response, error) {
+	c.prompts = append(c.prompts, prompt)
+	line := prompt[strings.Index(prompt, "Current step"):]
+	title := strings.TrimSpace(line[strings.Index(line, ":")+1 : strings.Index(line, "\n")])
+	if title == c.fail {
+		return nil, errors.New("model unavailable")
+	}
+	return &llm.Response{Text: "result of " + title}, nil
+}
+
+func TestAgent_Run(t *testing.T) {
+	g, err := NewGraph(planConfig())
+	require.NoError(t, err)
+	client := &stepClient{}
+	dir := t.TempDir()
+	var reported []string
+
+	agent := &Agent{Client: client, Target: llm.Target{Provider: "fake", Model: "chart"}, OnResult: func(r StepResult) {
+		reported = append(reported, r.ID+" "+r.Status)
+	}}
+	res, err := agent.Run(context.Background(), g, "Ship the release", dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A done", "C done", "B done", "D done"}, reported)
+	assert.Empty(t, res.Failed())
+	require.Len(t, client.prompts, 4)
+	assert.Contains(t, client.prompts[0], "Goal:\n<<<\nShip the release\n>>>")
+	assert.Contains(t, client.prompts[0], "Current step (Plan): List\n")
+	assert.NotContains(t, client.prompts[0], "Results of the steps")
+	assert.Contains(t, client.prompts[2], "What this step does: Use \"buckets\"")
+	assert.Contains(t, client.prompts[3], "Estimate:\n<<<\nresult of Estimate\n>>>\n\nSort:\n<<<\nresult of Sort\n>>>")
+
+	data, err := os.ReadFile(filepath.Join(dir, "D.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "result of Schedule", string(data))
+	assert.FileExists(t, filepath.Join(dir, "D.meta.json"))
+	assert.FileExists(t, filepath.Join(dir, "D.prompt.txt"))
+
+	report, err := os.ReadFile(res.Report)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(report), "# Ship the release\n\n```mermaid\nflowchart LR\n"))
+	assert.Contains(t, string(report), "\n## ✅ ✍️ Schedule\n\nresult of Schedule\n")
+}
+
+func TestAgent_Run_SkipsStepsAfterAFailure(t *testing.T) {
+	g, err := NewGraph(planConfig())
+	require.NoError(t, err)
+	client := &stepClient{fail: "Estimate"}
+	dir := t.TempDir()
+
+	res, err := (&Agent{Client: client}).Run(context.Background(), g, "Ship the release", dir)
+	require.NoError(t, err)
+
+	statuses := map[string]string{}
+	for _, s := range res.Steps {
+		statuses[s.ID] = s.Status
+	}
+	assert.Equal(t, map[string]string{"A": StatusDone, "C": StatusFailed, "B": StatusDone, "D": StatusSkipped}, statuses)
+	assert.Equal(t, []string{"C", "D"}, res.Failed())
+	assert.Len(t, client.prompts, 3, "the skipped step is not sent")
+
+	report, err := os.ReadFile(res.Report)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "C([\"❌ Estimate\"])")
+	assert.Contains(t, string(report), "## ❌ Estimate\n\n_Failed: model unavailable_\n")
+	assert.Contains(t, string(report), "## ⏭️ ✍️ Schedule\n\n_Skipped: builds on C, which did not succeed_\n")
+}
+
+func TestAgent_Run_Cancelled(t *testing.T) {
+	g, err := NewGraph(planConfig())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := (&Agent{Client: &stepClient{}}).Run(ctx, g, "Ship the release", t.TempDir())
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, res)
+	assert.Equal(t, []string{"A", "C", "B", "D"}, res.Failed())
+	assert.FileExists(t, res.Report)
+}
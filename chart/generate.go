@@ -0,0 +1,289 @@
+package chart
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Generation --------------------
+
+// DefaultStyle is the node style of generated charts, as in resources/configs/flowchart.yaml.
+var DefaultStyle = map[string]string{
+	"subtaskBox": "fill:#FFFBE6,stroke:#607D8B,stroke-width:2px,color:#333",
+}
+
+// ConfigSchema is the JSON Schema of the chart config the model is asked for.
+func ConfigSchema() map[string]any {
+	text := func(max int) map[string]any {
+		return map[string]any{"type": "string", "minLength": 1, "maxLength": max}
+	}
+	direction := map[string]any{"type": "string", "enum": []any{"LR", "RL", "TB", "BT"}}
+	step := map[string]any{
+		"type":     "object",
+		"required": []any{"id", "emoji", "title", "description"},
+		"properties": map[string]any{
+			"id":          map[string]any{"type": "string", "pattern": `^[A-Za-z][A-Za-z0-9_]*$`},
+			"emoji":       text(8),
+			"title":       text(40),
+			"description": text(80),
+		},
+	}
+	phase := map[string]any{
+		"type":     "object",
+		"required": []any{"title", "emoji", "steps"},
+		"properties": map[string]any{
+			"title":     text(40),
+			"emoji":     text(8),
+			"direction": direction,
+			"steps":     map[string]any{"type": "array", "minItems": 1, "items": step},
+		},
+	}
+	link := map[string]any{
+		"type":     "object",
+		"required": []any{"from", "to"},
+		"properties": map[string]any{
+			"from": map[string]any{"type": "string"},
+			"to":   map[string]any{"type": "string"},
+		},
+	}
+	links := map[string]any{"type": "array", "items": link}
+	return map[string]any{
+		"type":     "object",
+		"required": []any{"planning_phase", "planning_links", "execution_phase", "execution_links", "transition_link"},
+		"properties": map[string]any{
+			"flow_direction":  direction,
+			"planning_phase":  phase,
+			"planning_links":  links,
+			"execution_phase": phase,
+			"execution_links": links,
+			"transition_link": link,
+		},
+	}
+}
+
+// GeneratePrompt asks for a two-phase plan that reaches goal.
+func GeneratePrompt(goal string) string {
+	return fmt.Sprintf("You design step-by-step flowcharts. Plan how to reach the goal below in two phases: "+
+		"a planning phase and an execution phase, each with 3 to 6 steps in the order they are done.\n\n"+
+		"Goal:\n<<<\n%s\n>>>\n\n"+
+		"Give every step a unique short id (A, B, C, ... across both phases), one emoji, a title of a few words "+
+		"and a one-line description. Link each step to the step that follows it within its phase, "+
+		"and add a transition link from the last planning step to the first execution step.\n",
+		strings.TrimSpace(goal))
+}
+
+// GenerateConfig asks gen for a chart config that reaches goal, repairs its
+// links and validates it as a step graph. It returns the repairs made.
+func GenerateConfig(ctx context.Context, gen llm.JSONGenerator, goal string, maxRepairs int) (promptConfig.ChartConfig, []string, error) {
+	var cfg promptConfig.ChartConfig
+	schema, err := llm.SchemaFromMap(ConfigSchema())
+	if err != nil {
+		return cfg, nil, err
+	}
+	doc, err := gen.ChatJSON(ctx, GeneratePrompt(goal), schema, maxRepairs)
+	if err != nil {
+		return cfg, nil, err
+	}
+	if err := json.Unmarshal([]byte(doc), &cfg); err != nil {
+		return cfg, nil, fmt.Errorf("invalid chart config: %w", err)
+	}
+
+	if cfg.FlowDirection == "" {
+		cfg.FlowDirection = "LR"
+	}
+	for _, phase := range []*promptConfig.Phase{&cfg.PlanningPhase, &cfg.ExecutionPhase} {
+		if phase.Direction == "" {
+			phase.Direction = "TB"
+		}
+	}
+	if cfg.Style == nil {
+		cfg.Style = DefaultStyle
+	}
+	repairs := RepairLinks(&cfg)
+	if _, err := NewGraph(cfg); err != nil {
+		return cfg, repairs, fmt.Errorf("invalid chart config: %w", err)
+	}
+	return cfg, repairs, nil
+}
+
+// RepairLinks drops the links of cfg that are self-links, duplicates, name
+// unknown steps or close a cycle, and links the last planning step to the first
+// execution step when the transition link is missing or invalid. It returns a
+// description of each repair.
+func RepairLinks(cfg *promptConfig.ChartConfig) []string {
+	known := map[string]bool{}
+	for _, phase := range []promptConfig.Phase{cfg.PlanningPhase, cfg.ExecutionPhase} {
+		for _, s := range phase.Steps {
+			known[s.ID] = true
+		}
+	}
+	var repairs []string
+	next := map[string][]string{} // Links kept so far, to detect cycles
+	keep := func(l promptConfig.Link) bool {
+		reason := ""
+		switch {
+		case !known[l.From]:
+			reason = fmt.Sprintf("unknown step %q", l.From)
+		case !known[l.To]:
+			reason = fmt.Sprintf("unknown step %q", l.To)
+		case l.From == l.To:
+			reason = "links a step to itself"
+		case slices.Contains(next[l.From], l.To):
+			reason = "duplicate"
+		case reaches(next, l.To, l.From):
+			reason = "closes a cycle"
+		}
+		if reason != "" {
+			repairs = append(repairs, fmt.Sprintf("dropped link %s → %s: %s", l.From, l.To, reason))
+			return false
+		}
+		next[l.From] = append(next[l.From], l.To)
+		return true
+	}
+	filter := func(links []promptConfig.Link) []promptConfig.Link {
+		kept := []promptConfig.Link{}
+		for _, l := range links {
+			if keep(l) {
+				kept = append(kept, l)
+			}
+		}
+		return kept
+	}
+	cfg.PlanningLinks = filter(cfg.PlanningLinks)
+	cfg.ExecutionLinks = filter(cfg.ExecutionLinks)
+
+	if t := cfg.TransitionLink; t != (promptConfig.Link{}) && keep(t) {
+		return repairs
+	}
+	cfg.TransitionLink = promptConfig.Link{}
+	planning, execution := cfg.PlanningPhase.Steps, cfg.ExecutionPhase.Steps
+	if len(planning) == 0 || len(execution) == 0 {
+		return repairs
+	}
+	t := promptConfig.Link{From: planning[len(planning)-1].ID, To: execution[0].ID}
+	if keep(t) {
+		cfg.TransitionLink = t
+		repairs = append(repairs, fmt.Sprintf("added transition link %s → %s", t.From, t.To))
+	}
+	return repairs
+}
+
+// MarshalConfig writes cfg as YAML laid out like resources/configs/flowchart.yaml.
+func MarshalConfig(cfg promptConfig.ChartConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	return unescapeEmoji(buf.Bytes())
+}
+
+// unescapeEmoji turns the \UXXXXXXXX escapes the YAML encoder writes for
+// characters outside the Basic Multilingual Plane, such as most emoji, back
+// into the characters. The encoder only escapes inside double-quoted scalars,
+// so those are located by parsing data and the rest is copied as is.
+func unescapeEmoji(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	lineStart := make([]int, len(lines)+1)
+	for i, line := range lines {
+		lineStart[i+1] = lineStart[i] + len(line)
+	}
+	var starts []int
+	walkScalars(&doc, func(n *yaml.Node) {
+		if n.Style&yaml.DoubleQuotedStyle != 0 && n.Line > 0 && n.Line <= len(lines) {
+			starts = append(starts, lineStart[n.Line-1]+columnOffset(lines[n.Line-1], n.Column))
+		}
+	})
+	slices.Sort(starts)
+
+	out := make([]byte, 0, len(data))
+	at := 0
+	for _, start := range starts {
+		if start < at || start >= len(data) || data[start] != '"' {
+			continue
+		}
+		out = append(out, data[at:start+1]...)
+		at = unescapeQuoted(&out, data, start+1)
+	}
+	return append(out, data[at:]...), nil
+}
+
+// unescapeQuoted copies the double-quoted scalar body starting at data[i] to
+// out, decoding \U escapes, and returns the index just past its closing quote.
+func unescapeQuoted(out *[]byte, data []byte, i int) int {
+	for ; i < len(data); i++ {
+		switch {
+		case data[i] == '"':
+			*out = append(*out, '"')
+			return i + 1
+		case data[i] != '\\' || i+1 == len(data):
+			*out = append(*out, data[i])
+			continue
+		}
+		if data[i+1] == 'U' && i+10 <= len(data) {
+			if r, err := strconv.ParseUint(string(data[i+2:i+10]), 16, 32); err == nil {
+				*out = utf8.AppendRune(*out, rune(r))
+				i += 9
+				continue
+			}
+		}
+		*out = append(*out, data[i], data[i+1]) // Keep other escapes, including \\ and \", whole
+		i++
+	}
+	return i
+}
+
+// columnOffset converts a 1-based column, counted in characters as the YAML
+// parser reports it, to a byte offset in line.
+func columnOffset(line []byte, column int) int {
+	offset := 0
+	for c := 1; c < column && offset < len(line); c++ {
+		_, size := utf8.DecodeRune(line[offset:])
+		offset += size
+	}
+	return offset
+}
+
+func walkScalars(n *yaml.Node, f func(*yaml.Node)) {
+	if n.Kind == yaml.ScalarNode {
+		f(n)
+	}
+	for _, c := range n.Content {
+		walkScalars(c, f)
+	}
+}
+
+// reaches reports whether to can be reached from from along next.
+func reaches(next map[string][]string, from, to string) bool {
+	seen := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, next[id]...)
+	}
+	return false
+}
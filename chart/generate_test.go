@@ -0,0 +1,125 @@
+package chart
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// fixedPlan returns doc as the model's JSON answer and records the prompt.
+type fixedPlan struct {
+	doc    string
+	err    error
+	prompt string
+}
+
+func (p *fixedPlan) ChatJSON(_ context.Context, prompt string, schema *llm.Schema, _ int) (string, error) {
+	p.prompt = prompt
+	if p.err != nil {
+		return "", p.err
+	}
+	if errs := schema.Validate([]byte(p.doc)); len(errs) > 0 {
+		return "", &llm.SchemaValidationError{Errors: errs, Response: p.doc}
+	}
+	return p.doc, nil
+}
+
+const planJSON = `{
+  "planning_phase": {"title": "Prepare", "emoji": "🧭", "steps": [
+    {"id": "A", "emoji": "📋", "title": "Gather Accounts", "description": "List the access needed"},
+    {"id": "B", "emoji": "🧑‍🤝‍🧑", "title": "Pick a Buddy", "description": "Choose a teammate"}
+  ]},
+  "planning_links": [{"from": "A", "to": "B"}, {"from": "B", "to": "Z"}],
+  "execution_phase": {"title": "First Week", "emoji": "🚀", "direction": "LR", "steps": [
+    {"id": "C", "emoji": "💻", "title": "Set Up Laptop", "description": "Clone the repos"}
+  ]},
+  "execution_links": [],
+  "transition_link": {"from": "", "to": ""}
+}`
+
+func TestGenerateConfig(t *testing.T) {
+	plan := &fixedPlan{doc: planJSON}
+	cfg, repairs, err := GenerateConfig(context.Background(), plan, "onboard a new engineer", 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, plan.prompt, "Goal:\n<<<\nonboard a new engineer\n>>>")
+	assert.Equal(t, []string{`dropped link B → Z: unknown step "Z"`, "added transition link B → C"}, repairs)
+	assert.Equal(t, "LR", cfg.FlowDirection)
+	assert.Equal(t, "TB", cfg.PlanningPhase.Direction)
+	assert.Equal(t, "LR", cfg.ExecutionPhase.Direction)
+	assert.Equal(t, DefaultStyle, cfg.Style)
+	assert.Equal(t, []promptConfig.Link{{From: "A", To: "B"}}, cfg.PlanningLinks)
+	assert.Equal(t, promptConfig.Link{From: "B", To: "C"}, cfg.TransitionLink)
+	assert.Equal(t, promptConfig.Step{ID: "B", Emoji: "🧑‍🤝‍🧑", Title: "Pick a Buddy", Description: "Choose a teammate"}, cfg.PlanningPhase.Steps[1])
+}
+
+func TestGenerateConfig_Errors(t *testing.T) {
+	_, _, err := GenerateConfig(context.Background(), &fixedPlan{err: errors.New("model unavailable")}, "goal", 0)
+	assert.EqualError(t, err, "model unavailable")
+
+	_, _, err = GenerateConfig(context.Background(), &fixedPlan{doc: `{"planning_phase": {}}`}, "goal", 0)
+	var invalid *llm.SchemaValidationError
+	assert.ErrorAs(t, err, &invalid)
+
+	duplicate := `{
+	  "planning_phase": {"title": "P", "emoji": "🧭", "steps": [{"id": "A", "emoji": "1", "title": "One", "description": "d"}]},
+	  "planning_links": [],
+	  "execution_phase": {"title": "E", "emoji": "🚀", "steps": [{"id": "A", "emoji": "2", "title": "Two", "description": "d"}]},
+	  "execution_links": [],
+	  "transition_link": {"from": "A", "to": "A"}
+	}`
+	_, _, err = GenerateConfig(context.Background(), &fixedPlan{doc: duplicate}, "goal", 0)
+	assert.EqualError(t, err, `invalid chart config: duplicate step id "A"`)
+}
+
+func TestRepairLinks(t *testing.T) {
+	cfg := promptConfig.ChartConfig{
+		PlanningPhase:  promptConfig.Phase{Steps: []promptConfig.Step{{ID: "A"}, {ID: "B"}, {ID: "C"}}},
+		PlanningLinks:  []promptConfig.Link{{From: "A", To: "B"}, {From: "A", To: "B"}, {From: "B", To: "B"}, {From: "B", To: "C"}, {From: "C", To: "A"}},
+		ExecutionPhase: promptConfig.Phase{Steps: []promptConfig.Step{{ID: "D"}}},
+		ExecutionLinks: []promptConfig.Link{{From: "X", To: "D"}},
+		TransitionLink: promptConfig.Link{From: "D", To: "D"},
+	}
+
+	repairs := RepairLinks(&cfg)
+
+	assert.Equal(t, []string{
+		"dropped link A → B: duplicate",
+		"dropped link B → B: links a step to itself",
+		"dropped link C → A: closes a cycle",
+		`dropped link X → D: unknown step "X"`,
+		"dropped link D → D: links a step to itself",
+		"added transition link C → D",
+	}, repairs)
+	assert.Equal(t, []promptConfig.Link{{From: "A", To: "B"}, {From: "B", To: "C"}}, cfg.PlanningLinks)
+	assert.Empty(t, cfg.ExecutionLinks)
+	assert.Equal(t, promptConfig.Link{From: "C", To: "D"}, cfg.TransitionLink)
+	_, err := NewGraph(cfg)
+	assert.NoError(t, err)
+}
+
+func TestMarshalConfig(t *testing.T) {
+	cfg := planConfig()
+	cfg.Style = DefaultStyle
+	cfg.PlanningPhase.Emoji = "🧑‍🤝‍🧑"
+	cfg.PlanningPhase.Steps[0].Description = `C:\Users and \U not an escape`
+	cfg.PlanningPhase.Steps[1].Description = `Write \U0001F680 to escape 🚀 in "quoted" YAML`
+	cfg.ExecutionPhase.Steps[0].Description = `Plain text that spells out \U0001F680`
+
+	data, err := MarshalConfig(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  emoji: \"🧑‍🤝‍🧑\"\n")
+	assert.Contains(t, string(data), `description: "Write \\U0001F680 to escape 🚀 in \"quoted\" YAML"`)
+	assert.Contains(t, string(data), `description: Plain text that spells out \U0001F680`, "escapes outside double quotes are text")
+
+	var back promptConfig.ChartConfig
+	require.NoError(t, yaml.Unmarshal(data, &back))
+	assert.Equal(t, cfg, back)
+}
@@ -0,0 +1,202 @@
+package chart
+
+import (
+	"fmt"
+	"strings"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Step Graph --------------------
+
+// Node is a step of a chart together with the phase it belongs to.
+type Node struct {
+	promptConfig.Step
+	Phase string // Title of the step's phase
+}
+
+// Graph is the step graph a chart config describes: the steps of its planning
+// and execution phases, linked by the phase links and the transition link.
+type Graph struct {
+	Config promptConfig.ChartConfig
+	Nodes  []Node // In config order
+	Links  []promptConfig.Link
+
+	index map[string]int
+}
+
+// NewGraph builds the step graph of cfg, rejecting duplicate or missing step
+// ids, links to unknown steps and cycles.
+func NewGraph(cfg promptConfig.ChartConfig) (*Graph, error) {
+	g := &Graph{Config: cfg, index: map[string]int{}}
+	for _, phase := range []promptConfig.Phase{cfg.PlanningPhase, cfg.ExecutionPhase} {
+		for _, s := range phase.Steps {
+			if s.ID == "" {
+				return nil, fmt.Errorf("step %q in %s has no id", s.Title, phase.Title)
+			}
+			if _, ok := g.index[s.ID]; ok {
+				return nil, fmt.Errorf("duplicate step id %q", s.ID)
+			}
+			g.index[s.ID] = len(g.Nodes)
+			g.Nodes = append(g.Nodes, Node{Step: s, Phase: phase.Title})
+		}
+	}
+	if len(g.Nodes) == 0 {
+		return nil, fmt.Errorf("the chart has no steps")
+	}
+
+	links := append(append([]promptConfig.Link{}, cfg.PlanningLinks...), cfg.ExecutionLinks...)
+	if cfg.TransitionLink != (promptConfig.Link{}) {
+		links = append(links, cfg.TransitionLink)
+	}
+	for _, l := range links {
+		for _, id := range []string{l.From, l.To} {
+			if _, ok := g.index[id]; !ok {
+				return nil, fmt.Errorf("link %s → %s: unknown step %q", l.From, l.To, id)
+			}
+		}
+		g.Links = append(g.Links, l)
+	}
+	if _, err := g.Order(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Node returns the step with id.
+func (g *Graph) Node(id string) (Node, bool) {
+	i, ok := g.index[id]
+	if !ok {
+		return Node{}, false
+	}
+	return g.Nodes[i], true
+}
+
+// Predecessors returns the ids of the steps linked to id, in config order.
+func (g *Graph) Predecessors(id string) []string {
+	var ids []string
+	for _, n := range g.Nodes {
+		for _, l := range g.Links {
+			if l.From == n.ID && l.To == id {
+				ids = append(ids, n.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// Order returns the steps in topological order, keeping config order among
+// steps that are ready at the same time.
+func (g *Graph) Order() ([]Node, error) {
+	pending := make([]int, len(g.Nodes))
+	for _, l := range g.Links {
+		pending[g.index[l.To]]++
+	}
+	done := make([]bool, len(g.Nodes))
+	var order []Node
+	for len(order) < len(g.Nodes) {
+		next := -1
+		for i := range g.Nodes {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, n := range g.Nodes {
+				if !done[i] {
+					cycle = append(cycle, n.ID)
+				}
+			}
+			return nil, fmt.Errorf("steps form a cycle: %s", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		order = append(order, g.Nodes[next])
+		for _, l := range g.Links {
+			if l.From == g.Nodes[next].ID {
+				pending[g.index[l.To]]--
+			}
+		}
+	}
+	return order, nil
+}
+
+// -------------------- Mermaid --------------------
+
+// statusClasses style the nodes of an annotated diagram by step status.
+var statusClasses = map[string]string{
+	StatusDone:    "fill:#E8F5E9,stroke:#2E7D32,stroke-width:2px,color:#333",
+	StatusFailed:  "fill:#FFEBEE,stroke:#C62828,stroke-width:2px,color:#333",
+	StatusSkipped: "fill:#ECEFF1,stroke:#90A4AE,stroke-dasharray:4 3,color:#666",
+}
+
+// statusIcons prefix the node labels of an annotated diagram.
+var statusIcons = map[string]string{
+	StatusDone:    "✅",
+	StatusFailed:  "❌",
+	StatusSkipped: "⏭️",
+}
+
+// Mermaid renders the graph as a Mermaid flowchart laid out like the chart
+// template. Steps with a status in statuses are labelled and styled with it.
+func (g *Graph) Mermaid(statuses map[string]string) string {
+	direction := g.Config.FlowDirection
+	if direction == "" {
+		direction = "LR"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "flowchart %s\n", direction)
+	for _, sub := range []struct {
+		name  string
+		phase promptConfig.Phase
+	}{{"Planning", g.Config.PlanningPhase}, {"Execution", g.Config.ExecutionPhase}} {
+		if len(sub.phase.Steps) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "    subgraph %s [%s]\n", sub.name, strings.TrimSpace(sub.phase.Emoji+" "+sub.phase.Title))
+		if sub.phase.Direction != "" {
+			fmt.Fprintf(&b, "        direction %s\n", sub.phase.Direction)
+		}
+		for _, s := range sub.phase.Steps {
+			fmt.Fprintf(&b, "        %s([\"%s\"])\n", s.ID, nodeLabel(s, statuses[s.ID]))
+		}
+		b.WriteString("    end\n")
+	}
+	for _, l := range g.Links {
+		fmt.Fprintf(&b, "    %s --> %s\n", l.From, l.To)
+	}
+
+	if style := g.Config.Style["subtaskBox"]; style != "" {
+		ids := make([]string, len(g.Nodes))
+		for i, n := range g.Nodes {
+			ids[i] = n.ID
+		}
+		fmt.Fprintf(&b, "    class %s subtaskBox\n", strings.Join(ids, ","))
+		fmt.Fprintf(&b, "    classDef subtaskBox %s;\n", style)
+	}
+	for _, status := range []string{StatusDone, StatusFailed, StatusSkipped} {
+		var ids []string
+		for _, n := range g.Nodes {
+			if statuses[n.ID] == status {
+				ids = append(ids, n.ID)
+			}
+		}
+		if len(ids) > 0 {
+			fmt.Fprintf(&b, "    class %s %s\n", strings.Join(ids, ","), status)
+			fmt.Fprintf(&b, "    classDef %s %s;\n", status, statusClasses[status])
+		}
+	}
+	return b.String()
+}
+
+// nodeLabel is the quoted text of a step's node, so it may not contain double quotes.
+func nodeLabel(s promptConfig.Step, status string) string {
+	label := strings.TrimSpace(strings.Join([]string{statusIcons[status], s.Emoji, s.Title}, " "))
+	label = strings.Join(strings.Fields(label), " ")
+	if s.Description != "" {
+		label += "<br><sub>" + s.Description + "</sub>"
+	}
+	return strings.ReplaceAll(label, `"`, "'")
+}
@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// planConfig is a chart with a branch: A feeds B and C, which both feed D.
+func planConfig() promptConfig.ChartConfig {
+	return promptConfig.ChartConfig{
+		FlowDirection: "LR",
+		PlanningPhase: promptConfig.Phase{Title: "Plan", Steps: []promptConfig.Step{
+			{ID: "A", Title: "List"}, {ID: "C", Title: "Estimate"}, {ID: "B", Title: "Sort", Description: `Use "buckets"`},
+		}},
+		PlanningLinks:  []promptConfig.Link{{From: "A", To: "B"}, {From: "A", To: "C"}},
+		ExecutionPhase: promptConfig.Phase{Title: "Do", Emoji: "🚀", Steps: []promptConfig.Step{{ID: "D", Emoji: "✍️", Title: "Schedule"}}},
+		ExecutionLinks: []promptConfig.Link{{From: "C", To: "D"}},
+		TransitionLink: promptConfig.Link{From: "B", To: "D"},
+	}
+}
+
+func ids(nodes []Node) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.ID)
+	}
+	return out
+}
+
+func TestNewGraph(t *testing.T) {
+	g, err := NewGraph(planConfig())
+	require.NoError(t, err)
+
+	order, err := g.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "C", "B", "D"}, ids(order))
+	assert.Equal(t, []string{"C", "B"}, g.Predecessors("D"))
+	assert.Empty(t, g.Predecessors("A"))
+
+	n, ok := g.Node("D")
+	require.True(t, ok)
+	assert.Equal(t, "Do", n.Phase)
+}
+
+func TestNewGraph_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*promptConfig.ChartConfig)
+		err    string
+	}{
+		{"no steps", func(c *promptConfig.ChartConfig) { *c = promptConfig.ChartConfig{} }, "the chart has no steps"},
+		{"missing id", func(c *promptConfig.ChartConfig) { c.ExecutionPhase.Steps[0].ID = "" }, `step "Schedule" in Do has no id`},
+		{"duplicate id", func(c *promptConfig.ChartConfig) { c.ExecutionPhase.Steps[0].ID = "A" }, `duplicate step id "A"`},
+		{"unknown step", func(c *promptConfig.ChartConfig) { c.TransitionLink.To = "Z" }, `link B → Z: unknown step "Z"`},
+		{"cycle", func(c *promptConfig.ChartConfig) {
+			c.ExecutionLinks = append(c.ExecutionLinks, promptConfig.Link{From: "D", To: "A"})
+		}, "steps form a cycle: A, C, B, D"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := planConfig()
+			tt.modify(&cfg)
+			_, err := NewGraph(cfg)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	g, err := NewGraph(planConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, `flowchart LR
+    subgraph Planning [Plan]
+        A(["✅ List"])
+        C(["❌ Estimate"])
+        B(["Sort<br><sub>Use 'buckets'</sub>"])
+    end
+    subgraph Execution [🚀 Do]
+        D(["⏭️ ✍️ Schedule"])
+    end
+    A --> B
+    A --> C
+    C --> D
+    B --> D
+    class A done
+    classDef done fill:#E8F5E9,stroke:#2E7D32,stroke-width:2px,color:#333;
+    class C failed
+    classDef failed fill:#FFEBEE,stroke:#C62828,stroke-width:2px,color:#333;
+    class D skipped
+    classDef skipped fill:#ECEFF1,stroke:#90A4AE,stroke-dasharray:4 3,color:#666;
+`, g.Mermaid(map[string]string{"A": StatusDone, "C": StatusFailed, "D": StatusSkipped}))
+}
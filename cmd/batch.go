@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// Batch flags
+var (
+	batchOutputPath  string
+	batchConcurrency int
+	batchRateLimits  map[string]int
+)
+
+// BatchRunner executes a JSONL batch of prompts.
+type BatchRunner struct {
+	Out          io.Writer
+	NewGenerator func(req llm.BatchRequest, stream llm.StreamHandler) (llm.Generator, error)
+}
+
+func (r *BatchRunner) Run(inputPath string) {
+	reqs, err := llm.ReadBatchRequests(inputPath)
+	if err != nil {
+		log.Fatalf("Batch error: %v", err)
+	}
+
+	outPath := batchOutputPath
+	if outPath == "" {
+		outPath = defaultBatchOutputPath(inputPath)
+	}
+	done, err := llm.CompletedBatchIDs(outPath)
+	if err != nil {
+		log.Fatalf("Batch error: reading previous results: %v", err)
+	}
+
+	out, err := os.OpenFile(outPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatalf("Batch error: %v", err)
+	}
+	defer out.Close()
+
+	fmt.Fprintf(r.Out, "Running %d requests (%d already done) with %d workers...\n", len(reqs), countDone(reqs, done), batchConcurrency)
+	defaults := flagTarget()
+	executor := &llm.BatchExecutor{
+		Concurrency:     batchConcurrency,
+		RateLimits:      batchRateLimits,
+		DefaultProvider: defaults.Provider,
+		DefaultModel:    defaults.Model,
+		NewGenerator:    r.NewGenerator,
+		OnResult: func(res llm.BatchResult) {
+			if res.Partial {
+				fmt.Fprintf(r.Out, "  … %s (%s:%s): interrupted after %d characters\n", res.ID, res.Provider, res.Model, len(res.Response))
+				return
+			}
+			if res.Error != "" {
+				fmt.Fprintf(r.Out, "  ✗ %s (%s:%s): %s\n", res.ID, res.Provider, res.Model, res.Error)
+				return
+			}
+			fmt.Fprintf(r.Out, "  ✓ %s (%s:%s) %dms\n", res.ID, res.Provider, res.Model, res.LatencyMS)
+		},
+	}
+
+	summary, err := executor.Run(runContext, reqs, done, out)
+	fmt.Fprintf(r.Out, "Done: %d succeeded, %d failed, %d skipped. Results: %s\n",
+		summary.Succeeded, summary.Failed, summary.Skipped, outPath)
+	if interrupted(err) {
+		fmt.Fprintf(r.Out, "Interrupted; %d in-flight requests recorded as partial. Re-run to resume.\n", summary.Interrupted)
+		exit(ExitInterrupted)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Batch aborted: %v", err)
+	}
+}
+
+// newBatchGenerator builds a client for one batch request, streaming to stream
+// and applying its provider/model/temperature overrides to the CLI flags.
+func newBatchGenerator(req llm.BatchRequest, stream llm.StreamHandler) (llm.Generator, error) {
+	cfg := llmConfigFromFlags()
+	cfg.Provider = req.Provider
+	cfg.Model.Name = req.Model
+	if req.Temperature != nil {
+		cfg.Model.Temperature = *req.Temperature
+	}
+	return newLLMClientFromConfig(cfg, llm.WithStreamHandler(stream))
+}
+
+// defaultBatchOutputPath derives "<input>.results.jsonl" from the input path.
+func defaultBatchOutputPath(inputPath string) string {
+	return strings.TrimSuffix(inputPath, ".jsonl") + ".results.jsonl"
+}
+
+func countDone(reqs []llm.BatchRequest, done map[string]bool) int {
+	n := 0
+	for _, req := range reqs {
+		if done[req.ID] {
+			n++
+		}
+	}
+	return n
+}
+
+var llmBatchCmd = &cobra.Command{
+	Use:   "batch <requests.jsonl>",
+	Short: "Run a JSONL file of prompts through the LLM with a worker pool",
+	Long: `Each input line is a JSON object:
+  {"id": "git-1", "prompt": "...", "provider": "ollama", "model": "phi4", "temperature": 0.7}
+Use "prompt_file" instead of "prompt" to read the prompt from a file.
+Results are appended to the output JSONL; ids that already succeeded are skipped on re-runs.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runner := &BatchRunner{Out: os.Stdout, NewGenerator: newBatchGenerator}
+		runner.Run(args[0])
+	},
+}
+
+func init() {
+	llmBatchCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "Default LLM provider")
+	llmBatchCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "Default LLM model")
+	llmBatchCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Default temperature")
+	llmBatchCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout per request")
+	llmBatchCmd.Flags().StringVarP(&batchOutputPath, "output", "o", "", "Results JSONL (default <input>.results.jsonl)")
+	llmBatchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "c", 4, "Number of concurrent requests")
+	llmBatchCmd.Flags().StringToIntVar(&batchRateLimits, "rate-limit", nil, "Requests per minute per provider, e.g. openai=60 (repeatable)")
+	addGenerationFlags(llmBatchCmd)
+	addTimeoutFlags(llmBatchCmd)
+	llmCmd.AddCommand(llmBatchCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+)
+
+type echoGenerator struct{ calls *int }
+
+func (g echoGenerator) Generate(_ context.Context, prompt string) (*llm.Response, error) {
+	*g.calls++
+	return &llm.Response{Text: strings.ToUpper(prompt)}, nil
+}
+
+func TestBatchRunnerRun_Resumes(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "requests.jsonl")
+	writeFile(t, input, `{"id":"a","prompt":"alpha"}
+{"id":"b","prompt":"beta","provider":"openai","model":"gpt-4o"}
+`)
+	batchOutputPath, batchConcurrency = "", 2
+	providerName, modelName = "ollama", "phi4"
+
+	calls := 0
+	var out bytes.Buffer
+	runner := &BatchRunner{
+		Out:          &out,
+		NewGenerator: func(llm.BatchRequest, llm.StreamHandler) (llm.Generator, error) { return echoGenerator{&calls}, nil },
+	}
+
+	runner.Run(input)
+	assert.Equal(t, 2, calls)
+	assert.Contains(t, out.String(), "✓ a (ollama:phi4)")
+	assert.Contains(t, out.String(), "✓ b (openai:gpt-4o)")
+
+	resultsPath := filepath.Join(dir, "requests.results.jsonl")
+	data, err := os.ReadFile(resultsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"response":"ALPHA"`)
+
+	out.Reset()
+	runner.Run(input)
+	assert.Equal(t, 2, calls, "completed ids must be skipped")
+	assert.Contains(t, out.String(), "0 succeeded, 0 failed, 2 skipped")
+}
+
+func TestBatchRunnerRun_DefaultsFromConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "requests.jsonl")
+	writeFile(t, input, `{"id":"a","prompt":"alpha"}`+"\n")
+	config := filepath.Join(dir, "config.yaml")
+	writeFile(t, config, "provider: fake\nmodel:\n  name: tiny\n")
+	t.Cleanup(func() { fileConfig, fileSet = llmConfig.Config{}, fileSettings{} })
+	require.NoError(t, loadLLMConfig(config))
+	batchOutputPath, batchConcurrency = "", 1
+	providerName, modelName = "ollama", "phi4"
+
+	calls := 0
+	var out bytes.Buffer
+	runner := &BatchRunner{
+		Out:          &out,
+		NewGenerator: func(llm.BatchRequest, llm.StreamHandler) (llm.Generator, error) { return echoGenerator{&calls}, nil },
+	}
+
+	runner.Run(input)
+	assert.Contains(t, out.String(), "✓ a (fake:tiny)", "requests without a target use the config file's")
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// newEmbedder builds the embedder used by the semantic cache and answer similarity (overridable in tests).
+var newEmbedder = func() (wrapper.Embedder, error) {
+	return wrapper.NewOpenAIEmbedder()
+}
+
+// openSemanticCache loads the semantic cache index configured by the CLI flags.
+func openSemanticCache() (*llm.SemanticCache, error) {
+	embedder, err := newEmbedder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	return llm.NewSemanticCache(cachePath, embedder, cacheThreshold)
+}
+
+// withSemanticCache wraps an LLM call so near-identical prompts reuse cached answers.
+// Cache failures are logged and never prevent the underlying call.
+func withSemanticCache(out io.Writer, run func(prompt string) (string, error)) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		// Answers also depend on attached files, tool results and the schema,
+		// which are not part of the key.
+		if !useSemanticCache || len(attachPaths) > 0 || len(toolNames) > 0 || jsonSchemaPath != "" {
+			return run(prompt)
+		}
+
+		ctx := runContext
+		model := cacheKey()
+
+		cache, err := openSemanticCache()
+		if err != nil {
+			log.Printf("[cache] disabled: %v", err)
+			return run(prompt)
+		}
+
+		hit, err := cache.Lookup(ctx, model, prompt)
+		if err != nil {
+			log.Printf("[cache] lookup failed: %v", err)
+		} else if hit != nil {
+			if hit.Exact {
+				fmt.Fprintln(out, "served from cache (exact match)")
+			} else {
+				fmt.Fprintf(out, "served from semantic cache (%.2f)\n", hit.Score)
+			}
+			return hit.Entry.Answer, nil
+		}
+
+		resp, err := run(prompt)
+		if err != nil {
+			return "", err
+		}
+		if err := cache.Store(ctx, model, prompt, resp); err != nil {
+			log.Printf("[cache] store failed: %v", err)
+		}
+		return resp, nil
+	}
+}
+
+// cacheKey names the model and the generation settings an answer is cached
+// under, e.g. "ollama:phi4?max_tokens=512&temperature=0.8".
+func cacheKey() string {
+	cfg := llmConfigFromFlags()
+	m := cfg.Model
+	params := url.Values{}
+	for name, value := range map[string]any{
+		"temperature":        m.Temperature,
+		"max_tokens":         m.MaxTokens,
+		"top_p":              m.TopP,
+		"top_k":              m.TopK,
+		"stop":               m.Stop,
+		"seed":               m.Seed,
+		"frequency_penalty":  m.FrequencyPenalty,
+		"presence_penalty":   m.PresencePenalty,
+		"repetition_penalty": m.RepetitionPenalty,
+		"options":            m.Options,
+		"auto_continue":      cfg.Client.AutoContinue,
+	} {
+		if v := fmt.Sprint(value); v != "0" && v != "[]" && v != "map[]" {
+			params.Set(name, v)
+		}
+	}
+	key := cfg.Provider + ":" + m.Name
+	if len(params) > 0 {
+		key += "?" + params.Encode()
+	}
+	return key
+}
+
+// CacheRunner executes cache maintenance subcommands.
+type CacheRunner struct {
+	Out io.Writer
+}
+
+func (r *CacheRunner) RunRebuild() {
+	cache, err := openSemanticCache()
+	if err != nil {
+		log.Fatalf("Cache error: %v", err)
+	}
+
+	fmt.Fprintf(r.Out, "Rebuilding semantic cache index: %s\n", cachePath)
+	n, err := cache.Rebuild(runContext)
+	if err != nil {
+		log.Fatalf("Rebuild error: %v", err)
+	}
+	fmt.Fprintf(r.Out, "Indexed %d entries\n", n)
+}
+
+func (r *CacheRunner) RunClear() {
+	if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Clear error: %v", err)
+	}
+	fmt.Fprintf(r.Out, "Cleared semantic cache: %s\n", cachePath)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local semantic response cache",
+}
+
+var cacheRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Re-embed all cached prompts and rewrite the index",
+	Run: func(cmd *cobra.Command, args []string) {
+		(&CacheRunner{Out: os.Stdout}).RunRebuild()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the semantic cache index",
+	Run: func(cmd *cobra.Command, args []string) {
+		(&CacheRunner{Out: os.Stdout}).RunClear()
+	},
+}
+
+// addCacheFlags registers the semantic cache flags on an LLM-calling command.
+func addCacheFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&useSemanticCache, "semantic-cache", false, "Reuse cached answers for near-identical prompts")
+	c.Flags().Float64Var(&cacheThreshold, "cache-threshold", llm.DefaultCacheThreshold, "Minimum cosine similarity for a semantic cache hit")
+	c.Flags().StringVar(&cachePath, "cache-path", DefaultCachePath, "Semantic cache index file")
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cachePath, "cache-path", DefaultCachePath, "Semantic cache index file")
+	cacheCmd.AddCommand(cacheRebuildCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
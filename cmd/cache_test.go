@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// constEmbedder maps every input to the same vector.
+type constEmbedder struct{}
+
+func (constEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i := range inputs {
+		out[i] = []float32{1, 0}
+	}
+	return out, nil
+}
+
+func TestWithSemanticCache(t *testing.T) {
+	origEmbedder := newEmbedder
+	newEmbedder = func() (wrapper.Embedder, error) { return constEmbedder{}, nil }
+	t.Cleanup(func() {
+		newEmbedder = origEmbedder
+		useSemanticCache = false
+	})
+
+	useSemanticCache = true
+	cacheThreshold = 0.9
+	cachePath = filepath.Join(t.TempDir(), "cache.json")
+	providerName, modelName = "ollama", "phi4"
+
+	calls := 0
+	run := func(prompt string) (string, error) {
+		calls++
+		return "fresh answer", nil
+	}
+
+	var out bytes.Buffer
+	resp, err := withSemanticCache(&out, run)("Explain git")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh answer", resp)
+	assert.Equal(t, 1, calls)
+
+	resp, err = withSemanticCache(&out, run)("Explain   git")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh answer", resp)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, out.String(), "served from cache (exact match)")
+
+	resp, err = withSemanticCache(&out, run)("Explain git, please")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh answer", resp)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, out.String(), "served from semantic cache (1.00)")
+}
+
+func TestWithSemanticCache_KeyedByGenerationSettings(t *testing.T) {
+	origEmbedder := newEmbedder
+	newEmbedder = func() (wrapper.Embedder, error) { return constEmbedder{}, nil }
+	t.Cleanup(func() {
+		newEmbedder = origEmbedder
+		useSemanticCache, maxTokens, toolNames = false, 0, nil
+	})
+
+	useSemanticCache = true
+	cacheThreshold = 0.9
+	cachePath = filepath.Join(t.TempDir(), "cache.json")
+	providerName, modelName, temperature, maxTokens = "ollama", "phi4", 0.8, 0
+
+	calls := 0
+	run := func(prompt string) (string, error) {
+		calls++
+		return "fresh answer", nil
+	}
+
+	var out bytes.Buffer
+	assert.Equal(t, "ollama:phi4?temperature=0.8", cacheKey())
+	_, err := withSemanticCache(&out, run)("Explain git")
+	require.NoError(t, err)
+
+	maxTokens = 64
+	assert.Equal(t, "ollama:phi4?max_tokens=64&temperature=0.8", cacheKey())
+	_, err = withSemanticCache(&out, run)("Explain git")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "a different max-tokens is a different answer")
+
+	toolNames = []string{"calculator"}
+	_, err = withSemanticCache(&out, run)("Explain git")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "answers that used tools are not cached")
+	assert.NotContains(t, out.String(), "served from")
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/chart"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Chart flags
+var (
+	chartGoal       string
+	chartOutputDir  string
+	chartConfigPath string
+)
+
+// ChartRunner carries out the steps of a flowchart config toward a goal.
+type ChartRunner struct {
+	Out       io.Writer
+	NewClient func() (llm.Generator, error)
+}
+
+// Run walks the chart's steps and reports whether every step succeeded.
+func (r *ChartRunner) Run(configFile string) bool {
+	cfg, err := promptConfig.ReadChartConfig(configFile)
+	if err != nil {
+		log.Fatalf("Error reading chart config: %v", err)
+	}
+	graph, err := chart.NewGraph(cfg)
+	if err != nil {
+		log.Fatalf("Chart error in '%s': %v", configFile, err)
+	}
+	client, err := r.NewClient()
+	if err != nil {
+		log.Fatalf("Error creating LLM client: %v", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(configFile), filepath.Ext(configFile))
+	outDir := paths.GetChartRunDir(name, chartOutputDir)
+
+	fmt.Fprintf(r.Out, "Running chart %s (%d steps) into %s...\n", name, len(graph.Nodes), outDir)
+	agent := &chart.Agent{
+		Client: client,
+		Target: flagTarget(),
+		OnResult: func(res chart.StepResult) {
+			switch res.Status {
+			case chart.StatusDone:
+				fmt.Fprintf(r.Out, "  ✓ %s %s %s → %s\n", res.ID, res.Title, res.Duration.Round(time.Millisecond), res.Output)
+			default:
+				fmt.Fprintf(r.Out, "  ✗ %s %s %s: %s\n", res.ID, res.Title, res.Status, res.Error)
+			}
+		},
+	}
+
+	res, err := agent.Run(runContext, graph, chartGoal, outDir)
+	if interrupted(err) {
+		fmt.Fprintf(r.Out, "Interrupted; the report of the finished steps is in %s\n", res.Report)
+		exit(ExitInterrupted)
+		return false
+	}
+	if err != nil {
+		log.Fatalf("Chart error: %v", err)
+	}
+	if failed := res.Failed(); len(failed) > 0 {
+		fmt.Fprintf(r.Out, "Chart incomplete: %s did not finish. Report: %s\n", strings.Join(failed, ", "), res.Report)
+		return false
+	}
+	fmt.Fprintf(r.Out, "Chart complete. Report: %s\n", res.Report)
+	return true
+}
+
+// newChartClient builds the client every step of a chart run uses.
+func newChartClient() (llm.Generator, error) {
+	return newLLMClient()
+}
+
+// ChartGenerateRunner asks the model for a flowchart config that reaches a goal.
+type ChartGenerateRunner struct {
+	Out       io.Writer
+	NewClient func() (llm.JSONGenerator, error)
+}
+
+// Run generates the config and writes it to --output, or to Out when none is given.
+func (r *ChartGenerateRunner) Run(goal string) {
+	client, err := r.NewClient()
+	if err != nil {
+		log.Fatalf("Error creating LLM client: %v", err)
+	}
+	cfg, repairs, err := chart.GenerateConfig(runContext, client, goal, jsonRepairs)
+	if interrupted(err) {
+		exit(ExitInterrupted)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Chart generation error: %v", err)
+	}
+	for _, repair := range repairs {
+		log.Printf("[chart] repaired: %s", repair)
+	}
+
+	data, err := chart.MarshalConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error encoding chart config: %v", err)
+	}
+	if chartConfigPath == "" {
+		fmt.Fprint(r.Out, string(data))
+		return
+	}
+	paths.EnsureDirectoryExists(chartConfigPath)
+	if err := os.WriteFile(chartConfigPath, data, 0644); err != nil {
+		log.Fatalf("Save error: %v", err)
+	}
+	fmt.Fprintf(r.Out, "Chart config saved to: %s\n", chartConfigPath)
+}
+
+// newChartGenerator builds the client that writes chart configs.
+func newChartGenerator() (llm.JSONGenerator, error) {
+	return newLLMClient()
+}
+
+var chartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Work with flowchart configs",
+}
+
+var chartRunCmd = &cobra.Command{
+	Use:   "run <config.yaml>",
+	Short: "Carry out a flowchart's steps toward a goal",
+	Long: `Walks the steps of a flowchart config in link order. Each step is sent to the
+model with its title and description, the goal, and the results of the steps
+linked to it. Each result is saved as <id>.md in the output dir, with a
+report.md that combines them under a Mermaid diagram annotated with the status
+of each step. A failed step skips the steps that build on it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &ChartRunner{Out: os.Stdout, NewClient: newChartClient}
+		if !runner.Run(args[0]) {
+			return fmt.Errorf("chart run did not complete")
+		}
+		return nil
+	},
+}
+
+var chartGenerateCmd = &cobra.Command{
+	Use:   "generate <goal>",
+	Short: "Generate a flowchart config for a goal",
+	Long: `Asks the model for a two-phase plan as JSON matching the flowchart config
+schema: phases of steps with an emoji, title and description, and the links
+between them. Links to unknown steps, duplicate links and links that would form
+a cycle are dropped, and a missing transition link is added, before the plan is
+checked like chart run checks it and written as YAML that the prompt command
+and chart run accept.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		(&ChartGenerateRunner{Out: os.Stdout, NewClient: newChartGenerator}).Run(args[0])
+	},
+}
+
+func init() {
+	chartRunCmd.Flags().StringVar(&chartGoal, "goal", "", "What the plan should achieve (required)")
+	chartRunCmd.Flags().StringVarP(&chartOutputDir, "output", "o", "", "Output dir (default resources/output/charts/<config name>)")
+	chartRunCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "LLM provider")
+	chartRunCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model")
+	chartRunCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature")
+	chartRunCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout per step")
+	addGenerationFlags(chartRunCmd)
+	addTimeoutFlags(chartRunCmd)
+	_ = chartRunCmd.MarkFlagRequired("goal")
+
+	chartGenerateCmd.Flags().StringVarP(&chartConfigPath, "output", "o", "", "Config file to write (default print to stdout)")
+	chartGenerateCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "LLM provider")
+	chartGenerateCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model")
+	chartGenerateCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature")
+	chartGenerateCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout duration")
+	chartGenerateCmd.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when the plan fails schema validation")
+	addGenerationFlags(chartGenerateCmd)
+	addTimeoutFlags(chartGenerateCmd)
+
+	chartCmd.AddCommand(chartRunCmd)
+	chartCmd.AddCommand(chartGenerateCmd)
+	rootCmd.AddCommand(chartCmd)
+}
@@ -7,6 +7,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
 )
 
 type ChatRunner struct {
@@ -25,22 +28,130 @@ func (r *ChatRunner) Run() {
 		log.Fatalf("Prompt read error: %v", err)
 	}
 
+	schema, err := chatSchema(templatePath)
+	if err != nil {
+		log.Fatalf("Schema error: %v", err)
+	}
+	if schema != nil && sampleCount > 1 {
+		log.Fatal("Schema error: --samples cannot be combined with structured output")
+	}
+
 	fmt.Fprintln(r.Out, "Calling LLM...")
-	resp, err := runLLMInteraction(text)
+	stream := NewResponseStream(r.Out)
+	var resp string
+	if schema != nil {
+		resp, err = runStructuredLLMInteraction(text, schema)
+	} else {
+		saveWhileStreaming(stream, responseFilePath)
+		defer stream.Close()
+		resp, err = answerInteraction(r.Out, stream)(text)
+	}
+	if interrupted(err) {
+		savePartialResponse(r.Out, stream, responseFilePath, saveResponse)
+		return
+	}
 	if err != nil {
 		log.Fatalf("LLM error: %v", err)
 	}
 
-	fmt.Fprintf(r.Out, "\nLLM Response:\n%s\n", resp)
+	if ensureCoverage && schema == nil {
+		resp, err = r.ensureCoverage(stream, text, resp)
+		if interrupted(err) {
+			savePartialResponse(r.Out, stream, responseFilePath, saveResponse)
+			return
+		}
+		if err != nil {
+			log.Fatalf("LLM error: %v", err)
+		}
+	}
+
+	printResponse(r.Out, stream, resp)
+
+	if refineRounds > 0 && schema == nil {
+		resp, err = r.refine(stream, text, resp)
+		if interrupted(err) {
+			fmt.Fprintln(r.Out, "Refinement interrupted; keeping the latest complete draft.")
+			savePartialText(r.Out, resp, responseFilePath, saveResponse)
+			return
+		}
+		if err != nil {
+			log.Fatalf("Refine error: %v", err)
+		}
+	}
 
 	if topic != "" {
-		fmt.Fprintf(r.Out, "Saving response to: %s\n", responseFilePath)
-		if err := saveResponse(resp, responseFilePath); err != nil {
+		r.save(stream, resp, schema)
+	}
+}
+
+// save writes the answer, its metadata and, for structured answers, its JSON.
+func (r *ChatRunner) save(stream *ResponseStream, resp string, schema *llm.Schema) {
+	fmt.Fprintf(r.Out, "Saving response to: %s\n", responseFilePath)
+	if err := saveResponse(resp, responseFilePath); err != nil {
+		log.Fatalf("Save error: %v", err)
+	}
+	saveResponseMetadata(r.Out, stream, responseFilePath)
+	if schema != nil {
+		jsonPath := jsonPathFor(responseFilePath)
+		fmt.Fprintf(r.Out, "Saving JSON to: %s\n", jsonPath)
+		if err := saveResponse(resp, jsonPath); err != nil {
 			log.Fatalf("Save error: %v", err)
 		}
 	}
 }
 
+// refine critiques and revises the answer. Revisions are not streamed; the
+// final one is printed once refinement ends.
+func (r *ChatRunner) refine(stream *ResponseStream, prompt, answer string) (string, error) {
+	cfg, err := promptConfig.ReadTopicConfig(configPath)
+	if err != nil {
+		return answer, fmt.Errorf("error reading config: %w", err)
+	}
+	critic, err := newCritic()
+	if err != nil {
+		return answer, err
+	}
+	fmt.Fprintf(r.Out, "Refining the answer (up to %d round(s))...\n", refineRounds)
+	revisions := NewResponseStream(io.Discard)
+	final, err := refineAnswer(r.Out, critic, streamLLMInteraction(revisions), llm.RefineRules(cfg), prompt, answer, refineDir(responseFilePath))
+	if last := revisions.Last(); last != nil {
+		stream.last = last
+	}
+	if err == nil && final != answer {
+		fmt.Fprintf(r.Out, "\nRevised Response:\n%s\n", final)
+	}
+	return final, err
+}
+
+// ensureCoverage extends the answer with sections for topic concepts it missed,
+// streaming the added sections after the answer.
+func (r *ChatRunner) ensureCoverage(stream *ResponseStream, prompt, answer string) (string, error) {
+	cfg, err := promptConfig.ReadTopicConfig(configPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading config: %w", err)
+	}
+	if stream.Started() {
+		stream.endLine()
+	}
+	fmt.Fprintln(r.Out, "Checking concept coverage...")
+	return completeCoverage(r.Out, prompt, answer, cfg.Concepts, streamLLMInteraction(stream))
+}
+
+// chatSchema returns the --json-schema file if given, else the template's output_schema.
+func chatSchema(tmplPath string) (*llm.Schema, error) {
+	if jsonSchemaPath != "" {
+		return llm.LoadSchema(jsonSchemaPath)
+	}
+	tpl, err := promptConfig.ReadTemplate(tmplPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template: %w", err)
+	}
+	if len(tpl.OutputSchema) == 0 {
+		return nil, nil
+	}
+	return llm.SchemaFromMap(tpl.OutputSchema)
+}
+
 var chatCmd = &cobra.Command{
 	Use:   "chat",
 	Short: "Generate a prompt and call LLM",
@@ -54,6 +165,17 @@ func init() {
 	chatCmd.Flags().StringVarP(&providerName, "provider", "p", DefaultProvider, "LLM provider")
 	chatCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model name")
 	chatCmd.Flags().StringVarP(&outputPath, "promptOutput", "o", DefaultPromptPath, "Prompt output path")
+	chatCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout duration")
+	chatCmd.Flags().StringVarP(&responseFilePath, "save", "s", "", "Answer output path (default resources/output/<topic>/answer.md)")
+	addGenerationFlags(chatCmd)
+	addTimeoutFlags(chatCmd)
+	addAttachFlags(chatCmd)
+	addStructuredOutputFlags(chatCmd)
+	addCacheFlags(chatCmd)
+	addCoverageFlags(chatCmd)
+	addRefineFlags(chatCmd)
+	addSampleFlags(chatCmd)
+	addToolFlags(chatCmd)
 	chatCmd.MarkFlagRequired("topic")
 	rootCmd.AddCommand(chatCmd)
 }
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// Compare flags
+var (
+	compareTargets      []string
+	compareOutputPath   string
+	compareFormat       string
+	compareNoSimilarity bool
+)
+
+// CompareRunner sends one prompt to several targets and writes a side-by-side report.
+type CompareRunner struct {
+	Out          io.Writer
+	NewGenerator func(target llm.Target) (llm.Generator, error)
+	NewEmbedder  func() (wrapper.Embedder, error)
+}
+
+func (r *CompareRunner) Run(promptFile string) {
+	targets, err := llm.ParseTargets(compareTargets)
+	if err != nil {
+		log.Fatalf("Compare error: %v", err)
+	}
+	if len(targets) < 2 {
+		log.Fatal("Compare error: at least two --target values are required")
+	}
+	prompt, err := getPrompt(promptFile)
+	if err != nil {
+		log.Fatalf("Compare error: %v", err)
+	}
+
+	ctx := runContext
+	fmt.Fprintf(r.Out, "Comparing %d targets...\n", len(targets))
+	cmp := llm.CompareTargets(ctx, prompt, targets, r.NewGenerator)
+	for _, e := range cmp.Entries {
+		if e.Error != "" {
+			fmt.Fprintf(r.Out, "  ✗ %s: %s\n", e.Target, e.Error)
+			continue
+		}
+		fmt.Fprintf(r.Out, "  ✓ %s %dms\n", e.Target, e.Latency.Milliseconds())
+	}
+
+	if !compareNoSimilarity {
+		if err := r.addSimilarity(ctx, cmp); err != nil {
+			log.Printf("[compare] similarity skipped: %v", err)
+		}
+	}
+
+	report, err := renderComparison(cmp, compareFormatFor(compareOutputPath, compareFormat))
+	if err != nil {
+		log.Fatalf("Compare error: %v", err)
+	}
+	if compareOutputPath == "" {
+		fmt.Fprintln(r.Out, report)
+		return
+	}
+	if err := saveResponse(report, compareOutputPath); err != nil {
+		log.Fatalf("Failed to save report: %v", err)
+	}
+	fmt.Fprintf(r.Out, "Report saved to: %s\n", compareOutputPath)
+}
+
+func (r *CompareRunner) addSimilarity(ctx context.Context, cmp *llm.Comparison) error {
+	embedder, err := r.NewEmbedder()
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	return cmp.AddSimilarity(ctx, llm.NewSimilarityService(embedder))
+}
+
+// compareFormatFor picks the report format: the --format flag wins, otherwise
+// an .html/.htm output path selects HTML and anything else markdown.
+func compareFormatFor(path, format string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	}
+	return "md"
+}
+
+func renderComparison(cmp *llm.Comparison, format string) (string, error) {
+	switch format {
+	case "md", "markdown":
+		return cmp.Markdown(), nil
+	case "html":
+		return cmp.HTML()
+	}
+	return "", fmt.Errorf("unsupported report format: %s", format)
+}
+
+// newCompareGenerator builds a client for one compared target.
+func newCompareGenerator(target llm.Target) (llm.Generator, error) {
+	cfg := llmConfigFromFlags()
+	cfg.Provider = target.Provider
+	cfg.Model.Name = target.Model
+	cfg.Model.Options = targetOptions(target, cfg.Model.Options)
+	return newLLMClientFromConfig(cfg)
+}
+
+// targetOptions returns the passthrough options that apply to target. A key
+// scoped as "<provider>:<key>" (e.g. ollama:num_ctx) applies only to targets
+// of that provider name or type, and wins over the same key left unscoped,
+// which applies to every target.
+func targetOptions(target llm.Target, opts map[string]any) map[string]any {
+	if len(opts) == 0 {
+		return opts
+	}
+	kept := make(map[string]any, len(opts))
+	for k, v := range opts {
+		if !strings.Contains(k, ":") {
+			kept[k] = v
+		}
+	}
+	for k, v := range opts {
+		scope, key, ok := strings.Cut(k, ":")
+		if ok && (scope == target.Provider || scope == llm.ProviderType(target.Provider)) {
+			kept[key] = v
+		}
+	}
+	return kept
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Send one prompt to several provider:model targets and compare the answers",
+	Long: `Runs every --target concurrently and reports the answers side by side with
+latency, token counts, cost, and the pairwise embedding similarity of the answers.
+
+  topic-explorer compare --prompt prompt.txt --target ollama:phi4 --target openai:gpt-4o -o report.html
+
+An --option scoped as provider:key applies only to the targets of that provider
+name or type, so Ollama-only options do not reach the other providers:
+
+  topic-explorer compare --target ollama:phi4 --target openai:gpt-4o --option ollama:num_ctx=8192`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runner := &CompareRunner{Out: os.Stdout, NewGenerator: newCompareGenerator, NewEmbedder: newEmbedder}
+		runner.Run(promptPath)
+	},
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&promptPath, "prompt", "p", DefaultPromptPath, "Prompt file")
+	compareCmd.Flags().StringArrayVar(&compareTargets, "target", nil, "Target as provider:model (repeatable)")
+	compareCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature for every target")
+	compareCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout per target")
+	compareCmd.Flags().StringVarP(&compareOutputPath, "output", "o", "", "Report file (default stdout)")
+	compareCmd.Flags().StringVar(&compareFormat, "format", "", "Report format: md or html (default from --output extension)")
+	compareCmd.Flags().BoolVar(&compareNoSimilarity, "no-similarity", false, "Skip the embedding similarity matrix")
+	addGenerationFlags(compareCmd)
+	addTimeoutFlags(compareCmd)
+	rootCmd.AddCommand(compareCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+type targetGenerator struct{ target llm.Target }
+
+func (g targetGenerator) Generate(_ context.Context, prompt string) (*llm.Response, error) {
+	return &llm.Response{Text: g.target.Model + " says " + prompt}, nil
+}
+
+func TestCompareRunnerRun_WritesHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	prompt := filepath.Join(dir, "prompt.txt")
+	writeFile(t, prompt, "hello")
+	compareTargets = []string{"ollama:phi4", "openai:gpt-4o"}
+	compareOutputPath = filepath.Join(dir, "report.html")
+	compareFormat, compareNoSimilarity = "", false
+	t.Cleanup(func() { compareTargets, compareOutputPath = nil, "" })
+
+	var out bytes.Buffer
+	runner := &CompareRunner{
+		Out:          &out,
+		NewGenerator: func(target llm.Target) (llm.Generator, error) { return targetGenerator{target}, nil },
+		NewEmbedder:  func() (wrapper.Embedder, error) { return constEmbedder{}, nil },
+	}
+	runner.Run(prompt)
+
+	assert.Contains(t, out.String(), "✓ ollama:phi4")
+	assert.Contains(t, out.String(), "✓ openai:gpt-4o")
+	data, err := os.ReadFile(compareOutputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "phi4 says hello")
+	assert.Contains(t, string(data), "gpt-4o says hello")
+	assert.Contains(t, string(data), "<td>1.000</td>")
+}
+
+func TestTargetOptions(t *testing.T) {
+	opts := map[string]any{"num_ctx": "4096", "ollama:num_ctx": "8192", "ollama:num_gpu": "1", "fake:latency": "5ms"}
+
+	assert.Equal(t, map[string]any{"num_ctx": "8192", "num_gpu": "1"}, targetOptions(llm.Target{Provider: "ollama", Model: "phi4"}, opts))
+	assert.Equal(t, map[string]any{"num_ctx": "4096"}, targetOptions(llm.Target{Provider: "openai", Model: "gpt-4o"}, opts))
+	assert.Equal(t, map[string]any{"num_ctx": "4096", "latency": "5ms"}, targetOptions(llm.Target{Provider: "fake", Model: "git"}, opts))
+	assert.Nil(t, targetOptions(llm.Target{Provider: "openai"}, nil))
+
+	providerOptions = map[string]string{"ollama:num_ctx": "8192"}
+	t.Cleanup(func() { providerOptions = nil })
+	_, err := newCompareGenerator(llm.Target{Provider: "echo", Model: "any"})
+	assert.NoError(t, err, "options scoped to another provider are not passed on")
+}
+
+func TestCompareFormatFor(t *testing.T) {
+	assert.Equal(t, "html", compareFormatFor("out/report.HTML", ""))
+	assert.Equal(t, "md", compareFormatFor("report.md", ""))
+	assert.Equal(t, "md", compareFormatFor("", ""))
+	assert.Equal(t, "html", compareFormatFor("report.md", "HTML"))
+
+	_, err := renderComparison(&llm.Comparison{}, "pdf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Coverage flags
+var (
+	coverageThreshold float64
+	ensureCoverage    bool
+)
+
+// CoverageRunner reports which topic concepts an answer covers.
+type CoverageRunner struct {
+	Out io.Writer
+}
+
+func (r *CoverageRunner) Run(answerPath string) {
+	answer, err := getPrompt(answerPath)
+	if err != nil {
+		log.Fatalf("Coverage error: %v", err)
+	}
+	cfg, err := promptConfig.ReadTopicConfig(configPath)
+	if err != nil {
+		log.Fatalf("Coverage error: reading config '%s': %v", configPath, err)
+	}
+
+	fmt.Fprintf(r.Out, "Checking %d concepts against %s...\n", len(cfg.Concepts), answerPath)
+	report, err := checkCoverage(cfg.Concepts, answer)
+	if err != nil {
+		log.Fatalf("Coverage error: %v", err)
+	}
+	fmt.Fprint(r.Out, report)
+}
+
+// checkCoverage matches concepts to the answer's sections using the configured embedder.
+func checkCoverage(concepts []string, answer string) (*llm.CoverageReport, error) {
+	embedder, err := newEmbedder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	service := llm.NewSimilarityService(embedder)
+	return llm.CheckCoverage(runContext, service, concepts, answer, coverageThreshold)
+}
+
+// completeCoverage asks the model to add sections for concepts the answer missed
+// and returns the extended answer. Coverage failures leave the answer unchanged.
+func completeCoverage(out io.Writer, prompt, answer string, concepts []string, run func(prompt string) (string, error)) (string, error) {
+	report, err := checkCoverage(concepts, answer)
+	if err != nil {
+		log.Printf("[coverage] skipped: %v", err)
+		return answer, nil
+	}
+	missing := report.Missing()
+	if len(missing) == 0 {
+		fmt.Fprintf(out, "All %d concepts covered.\n", len(concepts))
+		return answer, nil
+	}
+
+	fmt.Fprintf(out, "Missing %d of %d concepts; asking the model to fill them in...\n", len(missing), len(concepts))
+	extra, err := run(llm.CoveragePrompt(prompt, answer, missing))
+	if err != nil {
+		return "", err
+	}
+	return answer + "\n\n" + extra, nil
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage <answer.md>",
+	Short: "Check that an answer covers every concept in its topic config",
+	Long: `Splits the answer into markdown sections, embeds them together with the
+config's concepts, and reports the best-matching section and score for each
+concept. Concepts scoring below --threshold are flagged.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		(&CoverageRunner{Out: os.Stdout}).Run(args[0])
+	},
+}
+
+// addCoverageFlags registers the coverage completion flags on chat.
+func addCoverageFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&ensureCoverage, "ensure-coverage", false, "Ask the model to cover concepts the answer missed")
+	c.Flags().Float64Var(&coverageThreshold, "coverage-threshold", llm.DefaultCoverageThreshold, "Similarity a concept needs to count as covered")
+}
+
+func init() {
+	coverageCmd.Flags().StringVarP(&configPath, "config", "c", "", "Topic config listing the concepts (required)")
+	coverageCmd.Flags().Float64Var(&coverageThreshold, "threshold", llm.DefaultCoverageThreshold, "Similarity a concept needs to count as covered")
+	coverageCmd.MarkFlagRequired("config")
+	rootCmd.AddCommand(coverageCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// stagingEmbedder only recognizes text about the staging area.
+type stagingEmbedder struct{}
+
+func (stagingEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		if strings.Contains(strings.ToLower(in), "staging") {
+			out[i] = []float32{1, 0}
+		} else {
+			out[i] = []float32{0, 1}
+		}
+	}
+	return out, nil
+}
+
+func TestCompleteCoverage(t *testing.T) {
+	origEmbedder := newEmbedder
+	newEmbedder = func() (wrapper.Embedder, error) { return stagingEmbedder{}, nil }
+	t.Cleanup(func() { newEmbedder = origEmbedder })
+	coverageThreshold = 0.9
+
+	var asked string
+	run := func(prompt string) (string, error) {
+		asked = prompt
+		return "## Staging Area\nPick what goes in the next commit.", nil
+	}
+
+	var out bytes.Buffer
+	answer, err := completeCoverage(&out, "Explain Git", "# Repository\nAll history.", []string{"Repository", "Staging Area"}, run)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Missing 1 of 2 concepts")
+	assert.Contains(t, asked, "- Staging Area")
+	assert.True(t, strings.HasPrefix(answer, "# Repository"))
+	assert.Contains(t, answer, "## Staging Area")
+
+	out.Reset()
+	asked = ""
+	answer, err = completeCoverage(&out, "Explain Git", "# Staging\nStaging area.", []string{"Staging Area"}, run)
+	require.NoError(t, err)
+	assert.Equal(t, "# Staging\nStaging area.", answer)
+	assert.Empty(t, asked)
+	assert.Contains(t, out.String(), "All 1 concepts covered")
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"log"
@@ -22,7 +21,7 @@ func (r *EmbeddingRunner) RunGet(inputs []string) {
 		log.Fatal("At least one input string is required")
 	}
 
-	ctx := context.Background()
+	ctx := runContext
 	embedder, err := wrapper.NewOpenAIEmbedder()
 	if err != nil {
 		log.Fatalf("Failed to initialize embedder: %v", err)
@@ -41,7 +40,7 @@ func (r *EmbeddingRunner) RunGet(inputs []string) {
 }
 
 func (r *EmbeddingRunner) RunCompare(a, b string) {
-	ctx := context.Background()
+	ctx := runContext
 	embedder, err := wrapper.NewOpenAIEmbedder()
 	if err != nil {
 		log.Fatalf("Failed to initialize embedder: %v", err)
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Eval flags
+var (
+	evalJudge      string
+	evalThreshold  float64
+	evalOutputPath string
+	evalJSON       bool
+)
+
+// EvalRunner grades an answer against its topic config with a judge model.
+type EvalRunner struct {
+	Out      io.Writer
+	NewJudge func(target llm.Target) (llm.JSONGenerator, error)
+}
+
+// Run prints the scorecard (or its JSON) and returns it; callers decide the exit status.
+func (r *EvalRunner) Run(answerPath string) *llm.Scorecard {
+	answer, err := getPrompt(answerPath)
+	if err != nil {
+		log.Fatalf("Eval error: %v", err)
+	}
+	cfg, err := promptConfig.ReadTopicConfig(configPath)
+	if err != nil {
+		log.Fatalf("Eval error: reading config '%s': %v", configPath, err)
+	}
+	target, err := llm.ParseTarget(evalJudge)
+	if err != nil {
+		log.Fatalf("Eval error: %v", err)
+	}
+	judge, err := r.NewJudge(target)
+	if err != nil {
+		log.Fatalf("Eval error: %v", err)
+	}
+
+	rubric := llm.BuildRubric(cfg)
+	if !evalJSON {
+		fmt.Fprintf(r.Out, "Judging %s against %d rubric items with %s...\n", answerPath, len(rubric.Items), target)
+	}
+	card, err := llm.Evaluate(runContext, judge, rubric, answer, evalThreshold, jsonRepairs)
+	if err != nil {
+		log.Fatalf("Eval error: %v", err)
+	}
+	card.Answer = answerPath
+	card.Judge = target.String()
+
+	data, err := json.MarshalIndent(card, "", "  ")
+	if err != nil {
+		log.Fatalf("Eval error: %v", err)
+	}
+	if evalJSON {
+		fmt.Fprintln(r.Out, string(data))
+	} else {
+		fmt.Fprint(r.Out, card)
+	}
+	if evalOutputPath != "" {
+		if err := saveResponse(string(data)+"\n", evalOutputPath); err != nil {
+			log.Fatalf("Failed to save scorecard: %v", err)
+		}
+		if !evalJSON {
+			fmt.Fprintf(r.Out, "Scorecard saved to: %s\n", evalOutputPath)
+		}
+	}
+	return card
+}
+
+// newJudge builds a zero-temperature client for the judge model.
+func newJudge(target llm.Target) (llm.JSONGenerator, error) {
+	cfg := llmConfigFromFlags()
+	cfg.Provider = target.Provider
+	cfg.Model.Name = target.Model
+	cfg.Model.Temperature = 0
+	return newLLMClientFromConfig(cfg)
+}
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <answer.md>",
+	Short: "Score an answer against its topic config with a judge model",
+	Long: `Builds a rubric from the config's explanation_requirements, constraints,
+output_format and tone, asks the judge to score each item from 1 to 5 with a
+justification, and prints a scorecard. Exits non-zero when the mean score is
+below --threshold.
+
+  topic-explorer eval resources/output/git/answer.md --config resources/configs/git.yaml --judge openai:gpt-4o`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		card := (&EvalRunner{Out: os.Stdout, NewJudge: newJudge}).Run(args[0])
+		if !card.Passed {
+			return fmt.Errorf("score %.2f is below threshold %.2f", card.Score, card.Threshold)
+		}
+		return nil
+	},
+}
+
+func init() {
+	evalCmd.Flags().StringVarP(&configPath, "config", "c", "", "Topic config file the answer was generated from (required)")
+	evalCmd.Flags().StringVarP(&evalJudge, "judge", "j", "openai:gpt-4o", "Judge model as provider:model")
+	evalCmd.Flags().Float64Var(&evalThreshold, "threshold", llm.DefaultEvalThreshold, "Minimum mean score (1-5) to pass")
+	evalCmd.Flags().StringVarP(&evalOutputPath, "output", "o", "", "Write the scorecard JSON to this file")
+	evalCmd.Flags().BoolVar(&evalJSON, "json", false, "Print the scorecard as JSON instead of a table")
+	evalCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout for the judge call")
+	addTimeoutFlags(evalCmd)
+	evalCmd.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when the verdict fails validation")
+	evalCmd.MarkFlagRequired("config")
+	rootCmd.AddCommand(evalCmd)
+}
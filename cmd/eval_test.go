@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+)
+
+type fixedJudge struct{ verdict string }
+
+func (j fixedJudge) ChatJSON(context.Context, string, *llm.Schema, int) (string, error) {
+	return j.verdict, nil
+}
+
+func TestEvalRunnerRun(t *testing.T) {
+	dir := t.TempDir()
+	answer := filepath.Join(dir, "answer.md")
+	writeFile(t, answer, "# Git for students")
+	configPath = filepath.Join(dir, "git.yaml")
+	writeFile(t, configPath, "topic: Git\nconstraints: [\"Avoid jargon\"]\ntone: friendly\n")
+	evalJudge, evalThreshold, evalJSON = "openai:gpt-4o", 4, false
+	evalOutputPath = filepath.Join(dir, "scorecard.json")
+	t.Cleanup(func() { configPath, evalOutputPath = "", "" })
+
+	var judged llm.Target
+	var out bytes.Buffer
+	runner := &EvalRunner{Out: &out, NewJudge: func(target llm.Target) (llm.JSONGenerator, error) {
+		judged = target
+		return fixedJudge{`{"scores":[{"id":"constraint-1","score":5,"justification":"plain words"},{"id":"tone","score":2,"justification":"dry"}]}`}, nil
+	}}
+	card := runner.Run(answer)
+
+	assert.Equal(t, llm.Target{Provider: "openai", Model: "gpt-4o"}, judged)
+	assert.False(t, card.Passed)
+	assert.Contains(t, out.String(), "Score: 3.50/5 (threshold 4.00) FAIL")
+
+	data, err := os.ReadFile(evalOutputPath)
+	require.NoError(t, err)
+	var saved llm.Scorecard
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, "openai:gpt-4o", saved.Judge)
+	assert.Equal(t, answer, saved.Answer)
+	assert.Len(t, saved.Items, 2)
+}
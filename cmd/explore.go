@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/explore"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Exploration flags
+var (
+	exploreDepth       int
+	exploreBreadth     int
+	exploreConcurrency int
+	exploreOutputDir   string
+)
+
+// ExploreRunner grows a knowledge tree from a topic config.
+type ExploreRunner struct {
+	Out       io.Writer
+	NewClient func() (explore.Client, error)
+}
+
+// Run explores topic and reports whether every topic of the tree was saved.
+func (r *ExploreRunner) Run(topic string) bool {
+	cfgPath := paths.GetConfigPath(topic, configPath)
+	cfg, err := promptConfig.ReadTopicConfig(cfgPath)
+	if err != nil {
+		log.Fatalf("Error reading config '%s': %v", cfgPath, err)
+	}
+	tpl, err := promptConfig.ReadTemplate(paths.GetTemplatePath(templatePath))
+	if err != nil {
+		log.Fatalf("Error reading template: %v", err)
+	}
+	client, err := r.NewClient()
+	if err != nil {
+		log.Fatalf("Error creating LLM client: %v", err)
+	}
+	outDir := paths.GetExploreDir(topic, exploreOutputDir)
+
+	fmt.Fprintf(r.Out, "Exploring %s (depth %d, breadth %d) into %s...\n", cfg.Topic, exploreDepth, exploreBreadth, outDir)
+	explorer := &explore.Explorer{
+		Client:      client,
+		Target:      flagTarget(),
+		Template:    tpl.Template,
+		Depth:       exploreDepth,
+		Breadth:     exploreBreadth,
+		Concurrency: exploreConcurrency,
+		MaxRepairs:  jsonRepairs,
+		OnNode: func(n *explore.Node) {
+			if n.Status == explore.StatusDone {
+				fmt.Fprintf(r.Out, "  ✓ %s → %s\n", n.Title, filepath.Join(outDir, n.Dir, explore.AnswerFile))
+			} else {
+				fmt.Fprintf(r.Out, "  ✗ %s %s: %s\n", n.Title, n.Status, n.Error)
+			}
+		},
+	}
+
+	tree, err := explorer.Explore(runContext, cfg, outDir)
+	if tree != nil {
+		if werr := explore.WriteTree(tree, outDir); werr != nil {
+			log.Fatalf("Explore error: %v", werr)
+		}
+	}
+	index := filepath.Join(outDir, explore.IndexFile)
+	if interrupted(err) {
+		fmt.Fprintf(r.Out, "Interrupted; the index of the saved topics is in %s\n", index)
+		exit(ExitInterrupted)
+		return false
+	}
+	if err != nil {
+		log.Fatalf("Explore stopped: %v. Index of the saved topics: %s", err, index)
+	}
+
+	saved, total := 0, 0
+	tree.Walk(func(n *explore.Node) {
+		total++
+		if n.Status == explore.StatusDone {
+			saved++
+		}
+	})
+	fmt.Fprintf(r.Out, "Explored %d of %d topics. Index: %s\n", saved, total, index)
+	return saved == total
+}
+
+// newExploreClient builds the client that explains topics and plans their subtopics.
+func newExploreClient() (explore.Client, error) {
+	return newLLMClient()
+}
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore <topic>",
+	Short: "Explore a topic and its subtopics into a knowledge tree",
+	Long: `Generates the explanation of a topic, asks the model for its most important
+subtopics as JSON, and explores each of them in turn with a topic config
+derived from the parent's: the audience, learning stage, tone and presentation
+carry over, the concepts and requirements are the subtopic's own.
+
+Each topic is saved in its own directory under the output dir, nested under its
+parent's, with its config, prompt and answer. index.md lists the tree, and each
+answer ends with links to its parent, subtopics and related topics.
+
+--depth and --breadth bound the tree, --concurrency the topics generated at
+once, and --max-cost the spend of the whole run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &ExploreRunner{Out: os.Stdout, NewClient: newExploreClient}
+		if !runner.Run(args[0]) {
+			return fmt.Errorf("some topics were not explored")
+		}
+		return nil
+	},
+}
+
+func init() {
+	exploreCmd.Flags().IntVar(&exploreDepth, "depth", 1, "Levels of subtopics below the topic")
+	exploreCmd.Flags().IntVar(&exploreBreadth, "breadth", 3, "Subtopics per topic")
+	exploreCmd.Flags().IntVar(&exploreConcurrency, "concurrency", 2, "Topics generated at once")
+	exploreCmd.Flags().StringVarP(&configPath, "config", "c", "", "Topic config (default resources/configs/<topic>.yaml)")
+	exploreCmd.Flags().StringVar(&templatePath, "template", "", "Topic prompt template (default resources/templates/topic.yaml)")
+	exploreCmd.Flags().StringVarP(&exploreOutputDir, "output", "o", "", "Output dir (default resources/output/explore/<topic>)")
+	exploreCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "LLM provider")
+	exploreCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model")
+	exploreCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature")
+	exploreCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout per call")
+	exploreCmd.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when a subtopic list fails schema validation")
+	addGenerationFlags(exploreCmd)
+	addTimeoutFlags(exploreCmd)
+
+	rootCmd.AddCommand(exploreCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/explore"
+	"raja.aiml/ai.explorer/llm"
+)
+
+const exploreSubtopics = `{"subtopics": [
+  {"title": "Branching", "purpose": "parallel work", "concepts": ["**Branch**: a movable pointer", "**HEAD**: the current branch"],
+   "explanation_requirements": ["Show a diagram"]},
+  {"title": "Staging Area", "purpose": "building commits", "concepts": ["**Index**: the next commit", "**git add**: staging changes"],
+   "explanation_requirements": ["Show git status output"]}]}`
+
+// exploreClient answers every topic the same way and proposes the same subtopics.
+type exploreClient struct{ fixedJudge }
+
+func (exploreClient) Generate(context.Context, string) (*llm.Response, error) {
+	return &llm.Response{Text: "# An explanation"}, nil
+}
+
+func TestExploreRunner(t *testing.T) {
+	dir := t.TempDir()
+	configPath = filepath.Join(dir, "git.yaml")
+	writeFile(t, configPath, "topic: Git\naudience: students\nlearning_stage: beginner\ntone: friendly\n")
+	templatePath = filepath.Join(dir, "topic.yaml")
+	writeFile(t, templatePath, "template: \"Explain {{ topic }} to {{ audience }}.\"\n")
+	exploreOutputDir = filepath.Join(dir, "tree")
+	exploreDepth, exploreBreadth, exploreConcurrency = 1, 2, 2
+	t.Cleanup(func() { configPath, templatePath, exploreOutputDir = "", "", "" })
+
+	var out bytes.Buffer
+	runner := &ExploreRunner{Out: &out, NewClient: func() (explore.Client, error) {
+		return exploreClient{fixedJudge{exploreSubtopics}}, nil
+	}}
+	assert.True(t, runner.Run("git"))
+
+	assert.Contains(t, out.String(), "Exploring Git (depth 1, breadth 2) into "+exploreOutputDir)
+	assert.Contains(t, out.String(), "  ✓ Branching → "+filepath.Join(exploreOutputDir, "branching", "answer.md"))
+	assert.Contains(t, out.String(), "Explored 3 of 3 topics. Index: "+filepath.Join(exploreOutputDir, "index.md"))
+
+	index, err := os.ReadFile(filepath.Join(exploreOutputDir, "index.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "  - [Staging Area](staging-area/answer.md)\n")
+
+	prompt, err := os.ReadFile(filepath.Join(exploreOutputDir, "staging-area", "prompt.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Explain Staging Area to students.", strings.TrimSpace(string(prompt)))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/knowledge"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/paths"
+)
+
+// Knowledge graph flags
+var (
+	graphConfigDir    string
+	graphOutputDir    string
+	graphFormats      []string
+	graphMinOverlap   int
+	graphThreshold    float64
+	graphNoSimilarity bool
+)
+
+// graphFiles maps each export format to the file it is saved as.
+var graphFiles = map[string]string{
+	"graphml": "graph.graphml",
+	"json":    "graph.json",
+	"mermaid": "graph.mmd",
+}
+
+// GraphRunner builds the knowledge graph of the saved topics and exports it.
+type GraphRunner struct {
+	Out         io.Writer
+	NewEmbedder func() (wrapper.Embedder, error)
+}
+
+// Run scans the topic configs and outputDirs and writes the graph in each format.
+func (r *GraphRunner) Run(outputDirs []string) {
+	for _, format := range graphFormats {
+		if _, ok := graphFiles[format]; !ok {
+			log.Fatalf("Graph error: unsupported format %q (want graphml, json or mermaid)", format)
+		}
+	}
+
+	fmt.Fprintf(r.Out, "Scanning %s and %s...\n", graphConfigDir, strings.Join(outputDirs, ", "))
+	g, err := knowledge.Scan(graphConfigDir, outputDirs...)
+	if err != nil {
+		log.Fatalf("Graph error: %v", err)
+	}
+	g.LinkOverlaps(graphMinOverlap)
+	if !graphNoSimilarity {
+		if err := r.linkSimilar(g); err != nil {
+			if interrupted(err) {
+				exit(ExitInterrupted)
+				return
+			}
+			log.Printf("[graph] similarity skipped: %v", err)
+		}
+	}
+	fmt.Fprintf(r.Out, "Graph: %d topics, %d concepts, %d edges\n",
+		g.Count(knowledge.KindTopic), g.Count(knowledge.KindConcept), len(g.Edges))
+
+	outDir := paths.GetGraphDir(graphOutputDir)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		log.Fatalf("Error creating output dir '%s': %v", outDir, err)
+	}
+	for _, format := range graphFormats {
+		data, err := exportGraph(g, format)
+		if err != nil {
+			log.Fatalf("Graph error: %v", err)
+		}
+		path := filepath.Join(outDir, graphFiles[format])
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Fatalf("Save error: %v", err)
+		}
+		fmt.Fprintf(r.Out, "Graph saved to: %s\n", path)
+	}
+}
+
+func (r *GraphRunner) linkSimilar(g *knowledge.Graph) error {
+	embedder, err := r.NewEmbedder()
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	return g.LinkSimilar(runContext, llm.NewSimilarityService(embedder), graphThreshold)
+}
+
+func exportGraph(g *knowledge.Graph, format string) ([]byte, error) {
+	switch format {
+	case "graphml":
+		return g.GraphML()
+	case "json":
+		return g.JSON()
+	}
+	return []byte(g.Mermaid()), nil
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Work with the knowledge graph of explored topics",
+}
+
+var graphBuildCmd = &cobra.Command{
+	Use:   "build [output dir ...]",
+	Short: "Build a knowledge graph of topics and concepts",
+	Long: `Scans the topic configs and the output dirs (default resources/output) and
+builds a graph with a node for every topic and every concept. Edges link each
+topic to its concepts and to the subtopics an explore run nested under it,
+topics sharing at least --min-overlap concepts, and topics or concepts whose
+embeddings have a cosine similarity of at least --threshold.
+
+The graph is saved as GraphML (for Gephi or yEd), JSON (nodes and edges) and
+a Mermaid flowchart.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = []string{paths.OutputDir}
+		}
+		(&GraphRunner{Out: os.Stdout, NewEmbedder: newEmbedder}).Run(args)
+	},
+}
+
+func init() {
+	graphBuildCmd.Flags().StringVar(&graphConfigDir, "configs", paths.ConfigDir, "Directory of topic configs")
+	graphBuildCmd.Flags().StringVarP(&graphOutputDir, "output", "o", "", "Output dir (default resources/output/graph)")
+	graphBuildCmd.Flags().StringSliceVar(&graphFormats, "format", []string{"graphml", "json", "mermaid"}, "Export formats: graphml, json, mermaid")
+	graphBuildCmd.Flags().IntVar(&graphMinOverlap, "min-overlap", 1, "Shared concepts needed to link two topics")
+	graphBuildCmd.Flags().Float64Var(&graphThreshold, "threshold", 0.8, "Cosine similarity needed to link two topics or concepts")
+	graphBuildCmd.Flags().BoolVar(&graphNoSimilarity, "no-similarity", false, "Skip the embedding similarity edges")
+
+	graphCmd.AddCommand(graphBuildCmd)
+	rootCmd.AddCommand(graphCmd)
+}
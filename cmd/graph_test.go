@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func graphRunner(out *bytes.Buffer, embedder wrapper.Embedder) *GraphRunner {
+	return &GraphRunner{Out: out, NewEmbedder: func() (wrapper.Embedder, error) {
+		if embedder == nil {
+			return nil, errors.New("OPENAI_API_KEY is not set")
+		}
+		return embedder, nil
+	}}
+}
+
+func TestGraphRunner(t *testing.T) {
+	dir := t.TempDir()
+	graphConfigDir = filepath.Join(dir, "configs")
+	require.NoError(t, os.Mkdir(graphConfigDir, 0755))
+	writeFile(t, filepath.Join(graphConfigDir, "git.yaml"),
+		"audience: students\ntopic: Git\nconcepts:\n  - \"**Staging Area**: Picking changes\"\n  - \"**Commits**: Snapshots\"\n")
+	writeFile(t, filepath.Join(graphConfigDir, "svn.yaml"),
+		"audience: students\ntopic: Subversion\npurpose: commits without staging\nconcepts:\n  - \"**Commits**: Revisions\"\n")
+	graphOutputDir = filepath.Join(dir, "graph")
+	graphFormats, graphMinOverlap, graphThreshold, graphNoSimilarity = []string{"json", "mermaid"}, 1, 0.9, false
+	t.Cleanup(func() { graphConfigDir, graphOutputDir, graphNoSimilarity = "", "", false })
+
+	var out bytes.Buffer
+	graphRunner(&out, stagingEmbedder{}).Run([]string{dir})
+
+	assert.Contains(t, out.String(), "Graph: 2 topics, 2 concepts, 5 edges\n")
+	assert.Contains(t, out.String(), "Graph saved to: "+filepath.Join(graphOutputDir, "graph.json"))
+	assert.NoFileExists(t, filepath.Join(graphOutputDir, "graph.graphml"))
+
+	data, err := os.ReadFile(filepath.Join(graphOutputDir, "graph.json"))
+	require.NoError(t, err)
+	var doc struct {
+		Edges []struct{ Kind string } `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	var kinds []string
+	for _, e := range doc.Edges {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []string{"concept", "concept", "concept", "overlap", "similar"}, kinds)
+
+	mermaid, err := os.ReadFile(filepath.Join(graphOutputDir, "graph.mmd"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mermaid), "n1 ===|1 shared| n4\n")
+
+	out.Reset()
+	graphRunner(&out, nil).Run([]string{dir})
+	assert.Contains(t, out.String(), "Graph: 2 topics, 2 concepts, 4 edges\n", "similarity is skipped without an embedder")
+}
@@ -1,12 +1,19 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
 	"raja.aiml/ai.explorer/paths"
 
 	llmConfig "raja.aiml/ai.explorer/config/llm"
@@ -26,29 +33,417 @@ func saveResponse(response, path string) error {
 	return os.WriteFile(path, []byte(response), 0644)
 }
 
-// runLLMInteraction initializes the LLM client and returns the response for the given prompt.
-func runLLMInteraction(prompt string) (string, error) {
+// fileConfig holds settings loaded from the --llm-config file.
+var fileConfig llmConfig.Config
+
+// fileSet records which settings the --llm-config file sets, so a setting
+// given its zero value (e.g. temperature: 0) still replaces the flag default.
+var fileSet fileSettings
+
+// fileSettings mirrors the model and client settings of llmConfig.Config with
+// pointer fields; nil means the file leaves the setting out.
+type fileSettings struct {
+	Provider *string
+	Model    struct {
+		Name              *string
+		Temperature       *float64
+		MaxTokens         *int     `yaml:"max_tokens"`
+		TopP              *float64 `yaml:"top_p"`
+		TopK              *int     `yaml:"top_k"`
+		Seed              *int     `yaml:"seed"`
+		FrequencyPenalty  *float64 `yaml:"frequency_penalty"`
+		PresencePenalty   *float64 `yaml:"presence_penalty"`
+		RepetitionPenalty *float64 `yaml:"repetition_penalty"`
+	}
+	Client struct {
+		Timeout           *time.Duration
+		FirstTokenTimeout *time.Duration `yaml:"first_token_timeout"`
+		IdleTimeout       *time.Duration `yaml:"idle_timeout"`
+		VerboseLogging    *bool          `yaml:"verbose_logging"`
+		AutoContinue      *int           `yaml:"auto_continue"`
+		MaxToolIterations *int           `yaml:"max_tool_iterations"`
+	}
+}
+
+// usageTracker accumulates token usage and cost across the calls of one run.
+var usageTracker = llm.NewUsageTracker(0)
+
+// providerHTTPClient routes provider calls through a record/replay cassette when set.
+var providerHTTPClient *http.Client
+
+// setupCassette installs the --record or --replay cassette, if either is given.
+func setupCassette(record, replay string) error {
+	providerHTTPClient = nil
+	dir, mode := record, wrapper.CassetteRecord
+	if replay != "" {
+		dir, mode = replay, wrapper.CassetteReplay
+	}
+	if dir == "" {
+		return nil
+	}
+	cassette, err := wrapper.NewCassette(dir, mode, nil)
+	if err != nil {
+		return err
+	}
+	providerHTTPClient = cassette.Client()
+	return nil
+}
+
+// loadLLMConfig reads the LLM config file, if present, and registers its providers.
+func loadLLMConfig(path string) error {
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	cfg, err := llmConfig.ConfigLoader(path)
+	if err != nil {
+		return err
+	}
+	if err := llm.RegisterConfiguredProviders(cfg); err != nil {
+		return fmt.Errorf("invalid provider config in %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var set fileSettings
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+	fileConfig, fileSet = cfg, set
+	return nil
+}
+
+// newLLMClient builds an LLM client from the CLI flags, with the --attach files
+// and --tool tools.
+func newLLMClient(opts ...llm.Option) (*llm.Client, error) {
+	if len(attachPaths) > 0 {
+		attachments, err := llm.LoadAttachments(attachPaths)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, llm.WithAttachments(attachments...))
+	}
+	tools, err := toolRegistry()
+	if err != nil {
+		return nil, err
+	}
+	if tools != nil {
+		opts = append(opts, llm.WithTools(tools), llm.WithToolReport(logToolCall))
+	}
+	client, err := newLLMClientFromConfig(llmConfigFromFlags(), opts...)
+	if err == nil && tools != nil && !client.SupportsTools() {
+		log.Printf("[tool] %s does not support tool calling; answering without tools", client.Target())
+	}
+	return client, err
+}
+
+// flagChanged reports whether a flag of the running command was set on the
+// command line; the root command points it at the command's flags.
+var flagChanged = func(name string) bool { return false }
+
+// llmConfigFromFlags assembles the client configuration from the --llm-config
+// file and the CLI flags. Flags set on the command line win; the file's
+// settings replace the flag defaults.
+func llmConfigFromFlags() llmConfig.Config {
+	file, set := fileConfig, fileSet
 	cfg := llmConfig.Config{
-		Provider: providerName,
+		Provider: pick("provider", providerName, set.Provider),
 		Model: llmConfig.ModelConfig{
-			Name:        modelName,
-			Temperature: temperature,
+			Name:              pick("model", modelName, set.Model.Name),
+			Temperature:       pick("temperature", temperature, set.Model.Temperature),
+			MaxTokens:         pick("max-tokens", maxTokens, set.Model.MaxTokens),
+			TopP:              pick("top-p", topP, set.Model.TopP),
+			TopK:              pick("top-k", topK, set.Model.TopK),
+			Stop:              stopSequences,
+			Seed:              pick("seed", seed, set.Model.Seed),
+			FrequencyPenalty:  pick("frequency-penalty", frequencyPenalty, set.Model.FrequencyPenalty),
+			PresencePenalty:   pick("presence-penalty", presencePenalty, set.Model.PresencePenalty),
+			RepetitionPenalty: pick("repetition-penalty", repetitionPenalty, set.Model.RepetitionPenalty),
+			Vision:            visionModel || file.Model.Vision,
+			Options:           mergeOptions(file.Model.Options, passthroughOptions(providerOptions)),
 		},
 		Client: llmConfig.ClientConfig{
-			Timeout:        timeout,
-			VerboseLogging: true,
+			Timeout:           pick("timeout", timeout, set.Client.Timeout),
+			FirstTokenTimeout: pick("first-token-timeout", firstTokenTimeout, set.Client.FirstTokenTimeout),
+			IdleTimeout:       pick("idle-timeout", idleTimeout, set.Client.IdleTimeout),
+			AutoContinue:      pick("auto-continue", autoContinue, set.Client.AutoContinue),
+			MaxToolIterations: pick("max-tool-iterations", maxToolIterations, set.Client.MaxToolIterations),
 		},
 	}
+	if !flagChanged("stop") && len(file.Model.Stop) > 0 {
+		cfg.Model.Stop = file.Model.Stop
+	}
+	return cfg
+}
+
+// echoAnswers reports whether runners print answers as they stream; the
+// --llm-config file's verbose_logging turns it off. The client's own
+// VerboseLogging stays off, since runners echo to their own writer.
+func echoAnswers() bool {
+	if v := fileSet.Client.VerboseLogging; v != nil {
+		return *v
+	}
+	return llmConfig.DefaultVerboseLogging
+}
+
+// flagTarget is the provider and model selected by the flags and the --llm-config file.
+func flagTarget() llm.Target {
+	cfg := llmConfigFromFlags()
+	return llm.Target{Provider: cfg.Provider, Model: cfg.Model.Name}
+}
+
+// pick returns the value of flag unless it was left unset and the file sets one.
+func pick[T any](flag string, value T, fromFile *T) T {
+	if flagChanged(flag) || fromFile == nil {
+		return value
+	}
+	return *fromFile
+}
+
+// mergeOptions returns the file's passthrough options overridden by the --option flags.
+func mergeOptions(fromFile, fromFlags map[string]any) map[string]any {
+	if len(fromFile) == 0 {
+		return fromFlags
+	}
+	opts := make(map[string]any, len(fromFile)+len(fromFlags))
+	for k, v := range fromFile {
+		opts[k] = v
+	}
+	for k, v := range fromFlags {
+		opts[k] = v
+	}
+	return opts
+}
+
+// newLLMClientFromConfig builds a client that prices and records its calls in the run's usage tracker.
+func newLLMClientFromConfig(cfg llmConfig.Config, opts ...llm.Option) (*llm.Client, error) {
+	opts = append([]llm.Option{
+		llm.WithPricing(llm.DefaultPricing.WithOverrides(fileConfig.Pricing)),
+		llm.WithUsageTracker(usageTracker),
+		llm.WithHTTPClient(providerHTTPClient),
+		llm.WithTimingReport(logTiming),
+	}, opts...)
+	client, err := llm.NewDefaultClient(cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	return client, nil
+}
+
+// runLLMInteraction initializes the LLM client and returns the response for the given prompt.
+func runLLMInteraction(prompt string) (string, error) {
+	return streamLLMInteraction(nil)(prompt)
+}
+
+// streamLLMInteraction returns an LLM call that streams the answer to stream as it
+// is generated and logs the stream's throughput. A nil stream disables streaming.
+func streamLLMInteraction(stream *ResponseStream) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		var opts []llm.Option
+		var meter *llm.RateMeter
+		if stream != nil {
+			meter = llm.NewRateMeter()
+			handlers := []llm.StreamHandler{llm.WriterStreamHandler(stream), meter.Handle}
+			if stream.file != nil {
+				handlers = append(handlers, stream.file.Handle)
+			}
+			opts = append(opts, llm.WithStreamHandler(llm.TeeStreamHandler(handlers...)))
+		}
+		client, err := newLLMClient(opts...)
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := client.Generate(runContext, prompt)
+		if meter != nil {
+			if stats := meter.Stats(); stats.Chunks > 0 {
+				stream.endLine()
+				log.Printf("[stream] %s", stats)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		if resp.Continuations > 0 {
+			log.Printf("[continue] answer extended by %d continuation request(s)", resp.Continuations)
+		}
+		if stream != nil {
+			stream.last = resp
+		}
+		return resp.Text, nil
+	}
+}
+
+// ResponseStream is where runners stream answers. It prints the "LLM Response:"
+// header before the first chunk so the answer is shown exactly once.
+type ResponseStream struct {
+	out     io.Writer
+	echo    bool // Print chunks to out; otherwise the answer is printed when complete
+	started bool
+	midLine bool // The last chunk did not end with a newline
+	text    strings.Builder
+	last    *llm.Response
+	file    *llm.FileStream
+}
+
+// NewResponseStream streams to out, unless the config file turns verbose_logging off.
+func NewResponseStream(out io.Writer) *ResponseStream {
+	return &ResponseStream{out: out, echo: echoAnswers()}
+}
+
+// SaveTo also writes the answer to path as it streams, so a long answer is on
+// disk even if the call never completes. Close closes the file.
+func (s *ResponseStream) SaveTo(path string) error {
+	file, err := llm.CreateFileStream(path)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	return nil
+}
+
+// Close closes the file the answer is saved to, if any.
+func (s *ResponseStream) Close() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+func (s *ResponseStream) Write(p []byte) (int, error) {
+	if !s.echo {
+		return s.text.Write(p)
+	}
+	if !s.started {
+		fmt.Fprint(s.out, "\nLLM Response:\n")
+		s.started = true
+	}
+	if len(p) > 0 {
+		s.midLine = p[len(p)-1] != '\n'
+	}
+	s.text.Write(p)
+	return s.out.Write(p)
+}
+
+// Text returns everything streamed so far, across calls.
+func (s *ResponseStream) Text() string {
+	if s == nil {
+		return ""
+	}
+	return s.text.String()
+}
+
+// Last returns the most recent completed response, or nil.
+func (s *ResponseStream) Last() *llm.Response {
+	if s == nil {
+		return nil
+	}
+	return s.last
+}
+
+// endLine terminates a streamed answer that did not end with a newline.
+func (s *ResponseStream) endLine() {
+	if s != nil && s.midLine {
+		fmt.Fprintln(s.out)
+		s.midLine = false
+	}
+}
+
+// Started reports whether any chunk has been printed.
+func (s *ResponseStream) Started() bool {
+	return s != nil && s.started
+}
+
+// saveWhileStreaming saves a streamed plain-text answer to path as it arrives,
+// when it is saved at all. Sampled answers are not streamed.
+func saveWhileStreaming(stream *ResponseStream, path string) {
+	if stream == nil || path == "" || sampleCount > 1 {
+		return
+	}
+	if err := stream.SaveTo(path); err != nil {
+		log.Fatalf("Save error: %v", err)
+	}
+}
+
+// printResponse shows the answer under its header unless it was already streamed.
+func printResponse(out io.Writer, stream *ResponseStream, resp string) {
+	if stream.Started() {
+		stream.endLine()
+		return
+	}
+	fmt.Fprintf(out, "\nLLM Response:\n%s\n", resp)
+}
+
+// logUsage prints the token usage and cost of a single call as it is recorded.
+func logUsage(target string, u llm.Usage) {
+	log.Printf("[usage] %s: %s", target, u)
+}
+
+// logTiming prints how quickly a call started answering and how long it took.
+func logTiming(target string, t llm.Timing) {
+	log.Printf("[latency] %s: %s", target, t)
+}
 
-	client, err := llm.NewDefaultClient(cfg)
+// runStructuredLLMInteraction requests a JSON response that validates against schema.
+func runStructuredLLMInteraction(prompt string, schema *llm.Schema) (string, error) {
+	client, err := newLLMClient()
 	if err != nil {
-		return "", fmt.Errorf("failed to create LLM client: %w", err)
+		return "", err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	return client.ChatJSON(runContext, prompt, schema, jsonRepairs)
+}
+
+// jsonPathFor returns the path of the JSON file written next to an answer file.
+func jsonPathFor(answerPath string) string {
+	return strings.TrimSuffix(answerPath, filepath.Ext(answerPath)) + ".json"
+}
+
+// addStructuredOutputFlags registers the JSON Schema flags on an LLM-calling command.
+func addStructuredOutputFlags(c *cobra.Command) {
+	c.Flags().StringVar(&jsonSchemaPath, "json-schema", "", "JSON Schema file; request and validate a JSON answer")
+	c.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when the JSON answer fails validation")
+}
+
+// addAttachFlags registers the files sent along with the prompt.
+func addAttachFlags(c *cobra.Command) {
+	c.Flags().StringArrayVar(&attachPaths, "attach", nil, "Attach an image or text file to the prompt (repeatable)")
+	c.Flags().BoolVar(&visionModel, "vision", false, "Treat the model as accepting images even if it is not a known vision model")
+}
+
+// addTimeoutFlags registers the streaming timeouts enforced alongside --timeout.
+func addTimeoutFlags(c *cobra.Command) {
+	c.Flags().DurationVar(&firstTokenTimeout, "first-token-timeout", 0, "Fail if the first token takes longer than this (0 = no limit)")
+	c.Flags().DurationVar(&idleTimeout, "idle-timeout", 0, "Fail if the stream stalls longer than this between chunks (0 = no limit)")
+}
 
-	return client.Chat(ctx, prompt)
+// addGenerationFlags registers sampling and length flags shared by LLM-calling commands.
+func addGenerationFlags(c *cobra.Command) {
+	c.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum tokens to generate (0 = provider default)")
+	c.Flags().Float64Var(&topP, "top-p", 0, "Nucleus sampling probability mass")
+	c.Flags().IntVar(&topK, "top-k", 0, "Top-k sampling candidates")
+	c.Flags().StringSliceVar(&stopSequences, "stop", nil, "Stop sequence (repeatable)")
+	c.Flags().IntVar(&seed, "seed", 0, "Sampling seed for reproducible runs")
+	c.Flags().Float64Var(&frequencyPenalty, "frequency-penalty", 0, "Frequency penalty")
+	c.Flags().Float64Var(&presencePenalty, "presence-penalty", 0, "Presence penalty")
+	c.Flags().Float64Var(&repetitionPenalty, "repetition-penalty", 0, "Repetition penalty")
+	c.Flags().StringToStringVar(&providerOptions, "option", nil, "Provider-specific option, e.g. num_ctx=8192 (repeatable)")
+	c.Flags().IntVar(&autoContinue, "auto-continue", 0, "Continuation requests allowed when the answer stops at the length limit")
+}
+
+// passthroughOptions converts --option flags into the provider options map.
+func passthroughOptions(flags map[string]string) map[string]any {
+	if len(flags) == 0 {
+		return nil
+	}
+	opts := make(map[string]any, len(flags))
+	for k, v := range flags {
+		opts[k] = v
+	}
+	return opts
 }
 
 // resolvePaths fills in default or derived paths based on the topic and other flags.
@@ -3,9 +3,13 @@ package cmd
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+
+	llmConfig "raja.aiml/ai.explorer/config/llm"
 )
 
 func Test_getPrompt_success(t *testing.T) {
@@ -46,3 +50,138 @@ func writeTempFile(t *testing.T, content string) string {
 	require.NoError(t, err)
 	return tmpFile
 }
+
+func Test_loadLLMConfig_registersProviders(t *testing.T) {
+	t.Cleanup(func() { fileConfig, fileSet = llmConfig.Config{}, fileSettings{} })
+	path := t.TempDir() + "/config.yaml"
+	writeFile(t, path, `
+provider: "local-vllm"
+providers:
+  - name: "local-vllm"
+    type: "openai-compatible"
+    base_url: "http://localhost:8000/v1"
+    headers:
+      X-Team: "explorers"
+`)
+
+	require.NoError(t, loadLLMConfig(path))
+	_, ok := wrapper.LookupProvider("local-vllm")
+	assert.True(t, ok)
+	assert.Equal(t, "local-vllm", fileConfig.Provider)
+}
+
+func Test_llmConfigFromFlags_mergesFileConfig(t *testing.T) {
+	var got wrapper.ProviderOptions
+	wrapper.RegisterProvider("test-options", func(_ string, opts wrapper.ProviderOptions) (wrapper.Model, error) {
+		got = opts
+		return wrapper.NewEchoModel(), nil
+	})
+	path := t.TempDir() + "/config.yaml"
+	writeFile(t, path, `
+provider: "test-options"
+model:
+  name: "tiny"
+  max_tokens: 512
+  seed: 7
+  options:
+    num_ctx: 8192
+    num_gpu: 1
+client:
+  auto_continue: 2
+`)
+	changed := map[string]bool{}
+	flagChanged = func(name string) bool { return changed[name] }
+	t.Cleanup(func() {
+		fileConfig, fileSet = llmConfig.Config{}, fileSettings{}
+		flagChanged = func(string) bool { return false }
+		providerName, modelName, seed, providerOptions = "", "", 0, nil
+	})
+	require.NoError(t, loadLLMConfig(path))
+	providerName, modelName, seed = DefaultProvider, DefaultModel, 3
+
+	cfg := llmConfigFromFlags()
+	assert.Equal(t, "test-options", cfg.Provider)
+	assert.Equal(t, "tiny", cfg.Model.Name)
+	assert.Equal(t, 512, cfg.Model.MaxTokens)
+	assert.Equal(t, 7, cfg.Model.Seed, "the file replaces flag defaults")
+	assert.Equal(t, 2, cfg.Client.AutoContinue)
+
+	_, err := newLLMClient()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"num_ctx": 8192, "num_gpu": 1}, got.Options)
+
+	changed["seed"], changed["option"] = true, true
+	providerOptions = map[string]string{"num_ctx": "4096"}
+	cfg = llmConfigFromFlags()
+	assert.Equal(t, 3, cfg.Model.Seed, "flags set on the command line win")
+	assert.Equal(t, map[string]any{"num_ctx": "4096", "num_gpu": 1}, cfg.Model.Options)
+}
+
+func Test_llmConfigFromFlags_zeroFileValues(t *testing.T) {
+	path := t.TempDir() + "/config.yaml"
+	writeFile(t, path, `
+provider: "fake"
+model:
+  name: "tiny"
+  temperature: 0
+client:
+  timeout: 0s
+`)
+	prevTemperature, prevTimeout := temperature, timeout
+	t.Cleanup(func() {
+		fileConfig, fileSet = llmConfig.Config{}, fileSettings{}
+		providerName, modelName, temperature, timeout = "", "", prevTemperature, prevTimeout
+	})
+	require.NoError(t, loadLLMConfig(path))
+	providerName, modelName, temperature, timeout = DefaultProvider, DefaultModel, DefaultTemperature, DefaultTimeout
+
+	cfg := llmConfigFromFlags()
+	assert.Equal(t, 0.0, cfg.Model.Temperature, "a zero in the file replaces the flag default")
+	assert.Equal(t, time.Duration(0), cfg.Client.Timeout)
+	assert.Equal(t, 0, cfg.Model.TopK, "settings the file leaves out keep the flag value")
+
+	meta := responseMetadata(nil)
+	assert.Equal(t, "fake", meta.Provider)
+	assert.Equal(t, "tiny", meta.Model)
+}
+
+func Test_loadLLMConfig_missingFileIsIgnored(t *testing.T) {
+	assert.NoError(t, loadLLMConfig(t.TempDir()+"/missing.yaml"))
+}
+
+func Test_setupCassette(t *testing.T) {
+	t.Cleanup(func() { providerHTTPClient = nil })
+
+	require.NoError(t, setupCassette("", ""))
+	assert.Nil(t, providerHTTPClient)
+
+	dir := t.TempDir() + "/cassettes"
+	require.NoError(t, setupCassette(dir, ""))
+	require.NotNil(t, providerHTTPClient)
+	assert.DirExists(t, dir)
+
+	require.NoError(t, setupCassette("", dir))
+	cassette, ok := providerHTTPClient.Transport.(*wrapper.Cassette)
+	require.True(t, ok)
+	assert.True(t, cassette.Replaying())
+
+	assert.Error(t, setupCassette("", t.TempDir()+"/missing"))
+}
+
+func Test_newLLMClient_loadsAttachments(t *testing.T) {
+	t.Cleanup(func() { attachPaths, providerName, modelName = nil, "", "" })
+	providerName, modelName = "echo", "any"
+
+	attachPaths = []string{t.TempDir() + "/missing.png"}
+	_, err := newLLMClient()
+	assert.ErrorContains(t, err, "missing.png")
+
+	notes := t.TempDir() + "/notes.md"
+	writeFile(t, notes, "# Notes")
+	attachPaths = []string{notes}
+	client, err := newLLMClient()
+	require.NoError(t, err)
+	resp, err := client.Chat(runContext, "Summarize")
+	require.NoError(t, err)
+	assert.Contains(t, resp, "Attached file: notes.md")
+}
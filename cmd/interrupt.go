@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"raja.aiml/ai.explorer/llm"
+)
+
+// ExitInterrupted is the exit status after Ctrl-C, matching the shell convention for SIGINT.
+const ExitInterrupted = 130
+
+// exit terminates the process (overridable in tests).
+var exit = os.Exit
+
+// runContext is the command's context; it is cancelled on the first interrupt.
+var runContext = context.Background()
+
+// notifyInterrupt returns a context cancelled by the first SIGINT or SIGTERM.
+// A second signal exits immediately with ExitInterrupted.
+func notifyInterrupt(parent context.Context, errOut io.Writer) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(errOut, "\nInterrupted; stopping (press Ctrl-C again to force exit)...")
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case <-signals:
+			fmt.Fprintln(errOut, "Forced exit.")
+			exit(ExitInterrupted)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// interrupted reports whether err was caused by the user interrupting the run.
+func interrupted(err error) bool {
+	return errors.Is(err, context.Canceled) && runContext.Err() != nil
+}
+
+// savePartialResponse saves whatever was streamed before an interrupt, marking
+// it partial in the metadata, then exits with ExitInterrupted.
+func savePartialResponse(out io.Writer, stream *ResponseStream, path string, save func(response, path string) error) {
+	stream.endLine()
+	savePartialText(out, stream.Text(), path, save)
+}
+
+// savePartialText saves text cut short by an interrupt, marking it partial in
+// the metadata, then exits with ExitInterrupted.
+func savePartialText(out io.Writer, text, path string, save func(response, path string) error) {
+	switch {
+	case path == "":
+		fmt.Fprintln(out, "Interrupted; no save path given, partial response discarded.")
+	case text == "":
+		fmt.Fprintln(out, "Interrupted before any output; nothing saved.")
+	default:
+		meta := responseMetadata(nil)
+		meta.Partial = true
+		if err := save(text, path); err != nil {
+			fmt.Fprintf(out, "Interrupted; failed to save partial response: %v\n", err)
+		} else if err := llm.SaveMetadata(path, meta); err != nil {
+			fmt.Fprintf(out, "Interrupted; partial response saved to %s but its metadata was not: %v\n", path, err)
+		} else {
+			fmt.Fprintf(out, "Interrupted; partial response saved to: %s\n", path)
+		}
+	}
+	exit(ExitInterrupted)
+}
+
+// saveResponseMetadata records how the saved response was produced.
+func saveResponseMetadata(out io.Writer, stream *ResponseStream, path string) {
+	meta := responseMetadata(stream.Last())
+	if err := llm.SaveMetadata(path, meta); err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+	}
+}
+
+// responseMetadata describes a response produced with the CLI flags.
+func responseMetadata(resp *llm.Response) llm.ResponseMetadata {
+	meta := llm.NewResponseMetadata(flagTarget(), resp)
+	meta.Attachments = attachPaths
+	return meta
+}
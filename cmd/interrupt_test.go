@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// fakeExit records exit codes instead of terminating the test binary.
+func fakeExit(t *testing.T) chan int {
+	t.Helper()
+	codes := make(chan int, 2)
+	exit = func(code int) { codes <- code }
+	t.Cleanup(func() { exit = os.Exit })
+	return codes
+}
+
+func Test_notifyInterrupt(t *testing.T) {
+	codes := fakeExit(t)
+	var errOut bytes.Buffer
+	ctx, stop := notifyInterrupt(context.Background(), &errOut)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("context not cancelled by the first interrupt")
+	}
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	select {
+	case code := <-codes:
+		assert.Equal(t, ExitInterrupted, code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("second interrupt did not force exit")
+	}
+}
+
+func Test_interrupted(t *testing.T) {
+	t.Cleanup(func() { runContext = context.Background() })
+	assert.False(t, interrupted(context.Canceled))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runContext = ctx
+	assert.False(t, interrupted(context.Canceled))
+	cancel()
+	assert.True(t, interrupted(context.Canceled))
+	assert.False(t, interrupted(context.DeadlineExceeded))
+}
+
+func Test_savePartialResponse(t *testing.T) {
+	codes := fakeExit(t)
+	path := filepath.Join(t.TempDir(), "answer.md")
+	var out bytes.Buffer
+	stream := NewResponseStream(&out)
+	stream.Write([]byte("Git is a distributed"))
+
+	savePartialResponse(&out, stream, path, saveResponse)
+
+	assert.Equal(t, ExitInterrupted, <-codes)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Git is a distributed", string(data))
+	meta, err := llm.LoadMetadata(path)
+	require.NoError(t, err)
+	assert.True(t, meta.Partial)
+	assert.Contains(t, out.String(), "partial response saved to: "+path)
+}
+
+func Test_savePartialResponse_nothingStreamed(t *testing.T) {
+	codes := fakeExit(t)
+	path := filepath.Join(t.TempDir(), "answer.md")
+	var out bytes.Buffer
+
+	savePartialResponse(&out, NewResponseStream(&out), path, saveResponse)
+
+	assert.Equal(t, ExitInterrupted, <-codes)
+	assert.NoFileExists(t, path)
+	assert.Contains(t, out.String(), "nothing saved")
+}
@@ -7,12 +7,15 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
 )
 
 type LLMRunner struct {
 	Out          io.Writer
+	Stream       *ResponseStream // Where RunLLM streams the answer; nil if it does not stream
 	GetPrompt    func(promptPath string) (string, error)
 	RunLLM       func(prompt string) (string, error)
+	RunJSON      func(prompt string, schema *llm.Schema) (string, error)
 	SaveResponse func(response, path string) error
 }
 
@@ -23,19 +26,47 @@ func (r *LLMRunner) Run() {
 		log.Fatalf("Prompt error: %v", err)
 	}
 
+	var schema *llm.Schema
+	if jsonSchemaPath != "" {
+		if schema, err = llm.LoadSchema(jsonSchemaPath); err != nil {
+			log.Fatalf("Schema error: %v", err)
+		}
+		if sampleCount > 1 {
+			log.Fatal("Schema error: --samples cannot be combined with --json-schema")
+		}
+	}
+
 	fmt.Fprintln(r.Out, "Calling LLM...")
-	resp, err := r.RunLLM(text)
+	var resp string
+	if schema != nil {
+		resp, err = r.RunJSON(text, schema)
+	} else {
+		saveWhileStreaming(r.Stream, responseFilePath)
+		defer r.Stream.Close()
+		resp, err = r.RunLLM(text)
+	}
+	if interrupted(err) {
+		savePartialResponse(r.Out, r.Stream, responseFilePath, r.SaveResponse)
+		return
+	}
 	if err != nil {
 		log.Fatalf("LLM error: %v", err)
 	}
 
-	fmt.Fprintf(r.Out, "\nLLM Response:\n%s\n", resp)
+	printResponse(r.Out, r.Stream, resp)
 
 	if responseFilePath != "" {
 		fmt.Fprintf(r.Out, "Saving to: %s\n", responseFilePath)
 		if err := r.SaveResponse(resp, responseFilePath); err != nil {
 			log.Fatalf("Save error: %v", err)
 		}
+		saveResponseMetadata(r.Out, r.Stream, responseFilePath)
+		if jsonPath := jsonPathFor(responseFilePath); schema != nil && jsonPath != responseFilePath {
+			fmt.Fprintf(r.Out, "Saving JSON to: %s\n", jsonPath)
+			if err := r.SaveResponse(resp, jsonPath); err != nil {
+				log.Fatalf("Save error: %v", err)
+			}
+		}
 	}
 }
 
@@ -43,10 +74,13 @@ var llmCmd = &cobra.Command{
 	Use:   "llm",
 	Short: "Send a raw prompt to LLM",
 	Run: func(cmd *cobra.Command, args []string) {
+		stream := NewResponseStream(os.Stdout)
 		runner := &LLMRunner{
 			Out:          os.Stdout,
+			Stream:       stream,
 			GetPrompt:    getPrompt,
-			RunLLM:       runLLMInteraction,
+			RunLLM:       answerInteraction(os.Stdout, stream),
+			RunJSON:      runStructuredLLMInteraction,
 			SaveResponse: saveResponse,
 		}
 		runner.Run()
@@ -60,5 +94,12 @@ func init() {
 	llmCmd.Flags().StringVarP(&promptPath, "prompt", "p", DefaultPromptPath, "Prompt file")
 	llmCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout duration")
 	llmCmd.Flags().StringVarP(&responseFilePath, "save", "s", "", "Save response to file")
+	addGenerationFlags(llmCmd)
+	addTimeoutFlags(llmCmd)
+	addAttachFlags(llmCmd)
+	addStructuredOutputFlags(llmCmd)
+	addCacheFlags(llmCmd)
+	addSampleFlags(llmCmd)
+	addToolFlags(llmCmd)
 	rootCmd.AddCommand(llmCmd)
 }
@@ -2,11 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	llmConfig "raja.aiml/ai.explorer/config/llm"
 )
 
 func TestLLMRunnerRun(t *testing.T) {
@@ -58,3 +63,156 @@ func TestLLMRunnerRun(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expectedResponse, string(data))
 }
+
+func TestLLMRunnerRun_JSONSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	promptPath = tmpDir + "/prompt.txt"
+	responseFilePath = tmpDir + "/answer.md"
+	jsonSchemaPath = tmpDir + "/schema.json"
+	t.Cleanup(func() { jsonSchemaPath = "" })
+	writeFile(t, jsonSchemaPath, `{"type":"object","required":["concepts"]}`)
+
+	expectedJSON := "{\n  \"concepts\": [\"commit\"]\n}"
+	var out bytes.Buffer
+	runner := &LLMRunner{
+		Out:       &out,
+		GetPrompt: func(string) (string, error) { return "List git concepts", nil },
+		RunLLM: func(string) (string, error) {
+			t.Fatal("RunLLM should not be called when a schema is set")
+			return "", nil
+		},
+		RunJSON: func(prompt string, schema *llm.Schema) (string, error) {
+			assert.Equal(t, "List git concepts", prompt)
+			assert.NotNil(t, schema)
+			return expectedJSON, nil
+		},
+		SaveResponse: saveResponse,
+	}
+
+	runner.Run()
+
+	assert.Contains(t, out.String(), "Saving JSON to: "+tmpDir+"/answer.json")
+	data, err := os.ReadFile(tmpDir + "/answer.json")
+	require.NoError(t, err)
+	assert.Equal(t, expectedJSON, string(data))
+}
+
+func TestLLMRunnerRun_JSONSchemaInterrupted(t *testing.T) {
+	codes := fakeExit(t)
+	tmpDir := t.TempDir()
+	promptPath = tmpDir + "/prompt.txt"
+	responseFilePath = tmpDir + "/answer.md"
+	jsonSchemaPath = tmpDir + "/schema.json"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runContext = ctx
+	t.Cleanup(func() { jsonSchemaPath, runContext = "", context.Background() })
+	writeFile(t, jsonSchemaPath, `{"type":"object"}`)
+
+	var out bytes.Buffer
+	runner := &LLMRunner{
+		Out:          &out,
+		GetPrompt:    func(string) (string, error) { return "List git concepts", nil },
+		RunJSON:      func(string, *llm.Schema) (string, error) { return "", context.Canceled },
+		SaveResponse: saveResponse,
+	}
+
+	runner.Run()
+
+	assert.Equal(t, ExitInterrupted, <-codes)
+	assert.Contains(t, out.String(), "Interrupted before any output; nothing saved.")
+	assert.NoFileExists(t, responseFilePath)
+}
+
+func TestLLMRunnerRun_StreamedResponsePrintedOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	promptPath = tmpDir + "/prompt.txt"
+	responseFilePath = ""
+
+	var out bytes.Buffer
+	stream := NewResponseStream(&out)
+	runner := &LLMRunner{
+		Out:       &out,
+		Stream:    stream,
+		GetPrompt: func(string) (string, error) { return "What is Go?", nil },
+		RunLLM: func(string) (string, error) {
+			stream.Write([]byte("Go is "))
+			stream.Write([]byte("a language."))
+			return "Go is a language.", nil
+		},
+	}
+
+	runner.Run()
+
+	output := out.String()
+	assert.Equal(t, 1, strings.Count(output, "Go is a language."))
+	assert.Equal(t, 1, strings.Count(output, "LLM Response:"))
+	assert.True(t, strings.HasSuffix(output, "LLM Response:\nGo is a language.\n"))
+}
+
+func TestLLMRunnerRun_UnstreamedResponsePrinted(t *testing.T) {
+	promptPath, responseFilePath = t.TempDir()+"/prompt.txt", ""
+
+	var out bytes.Buffer
+	runner := &LLMRunner{
+		Out:       &out,
+		Stream:    NewResponseStream(&out),
+		GetPrompt: func(string) (string, error) { return "What is Go?", nil },
+		RunLLM:    func(string) (string, error) { return "cached answer", nil },
+	}
+
+	runner.Run()
+	assert.True(t, strings.HasSuffix(out.String(), "LLM Response:\ncached answer\n"))
+}
+
+func TestLLMRunnerRun_SavesWhileStreaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	promptPath, responseFilePath = tmpDir+"/prompt.txt", tmpDir+"/answer.md"
+	providerName, modelName = "echo", "any"
+	t.Cleanup(func() { responseFilePath, providerName, modelName = "", "", "" })
+
+	var out bytes.Buffer
+	var onDisk []byte
+	stream := NewResponseStream(&out)
+	runner := &LLMRunner{
+		Out:       &out,
+		Stream:    stream,
+		GetPrompt: func(string) (string, error) { return "What is Go?", nil },
+		RunLLM: func(prompt string) (string, error) {
+			resp, err := streamLLMInteraction(stream)(prompt)
+			onDisk, _ = os.ReadFile(responseFilePath)
+			return resp, err
+		},
+		SaveResponse: func(string, string) error { return nil },
+	}
+
+	runner.Run()
+	assert.Equal(t, "What is Go?", string(onDisk), "the answer is on disk before the final save")
+	assert.Equal(t, 1, strings.Count(out.String(), "What is Go?"))
+}
+
+func TestLLMRunnerRun_VerboseLoggingOff(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := tmpDir + "/config.yaml"
+	writeFile(t, config, "client:\n  verbose_logging: false\n")
+	t.Cleanup(func() { fileConfig, fileSet = llmConfig.Config{}, fileSettings{} })
+	require.NoError(t, loadLLMConfig(config))
+	promptPath, responseFilePath = tmpDir+"/prompt.txt", ""
+
+	var out bytes.Buffer
+	stream := NewResponseStream(&out)
+	runner := &LLMRunner{
+		Out:       &out,
+		Stream:    stream,
+		GetPrompt: func(string) (string, error) { return "What is Go?", nil },
+		RunLLM: func(string) (string, error) {
+			stream.Write([]byte("Go is "))
+			assert.NotContains(t, out.String(), "Go is ", "chunks are not echoed")
+			stream.Write([]byte("a language."))
+			return "Go is a language.", nil
+		},
+	}
+
+	runner.Run()
+	assert.True(t, strings.HasSuffix(out.String(), "LLM Response:\nGo is a language.\n"))
+}
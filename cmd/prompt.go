@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 	"raja.aiml/ai.explorer/prompt"
 )
 
@@ -54,29 +53,11 @@ func buildPrompt(tmpl, cfg, out string) string {
 
 // detectPromptType peeks into the YAML keys to infer prompt style
 func detectPromptType(configPath string) string {
-	data, err := os.ReadFile(configPath)
+	kind, err := prompt.DetectConfigType(configPath)
 	if err != nil {
-		exitWithError(fmt.Errorf("failed to read config: %w", err))
+		exitWithError(err)
 	}
-
-	var raw map[string]any
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		exitWithError(fmt.Errorf("failed to parse config YAML: %w", err))
-	}
-
-	switch {
-	case hasKey(raw, "planning_phase") && hasKey(raw, "execution_phase"):
-		return "chart"
-	case hasKey(raw, "audience"):
-		return "topic"
-	default:
-		return "unknown"
-	}
-}
-
-func hasKey(m map[string]any, key string) bool {
-	_, ok := m[key]
-	return ok
+	return kind
 }
 
 func exitWithError(err error) {
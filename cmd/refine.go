@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// Refinement flags
+var (
+	refineRounds int
+	criticTarget string
+)
+
+// refineDir is where the drafts and critiques of an answer are kept, e.g.
+// answer.md → answer.refine/.
+func refineDir(answerPath string) string {
+	return strings.TrimSuffix(answerPath, filepath.Ext(answerPath)) + ".refine"
+}
+
+// refineAnswer has the critic check answer against rules and the model revise
+// it, saving each draft and critique in dir. It returns the latest draft, also
+// when it fails part way.
+func refineAnswer(out io.Writer, critic llm.JSONGenerator, revise func(prompt string) (string, error), rules []string, prompt, answer, dir string) (string, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return answer, fmt.Errorf("error clearing '%s': %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return answer, fmt.Errorf("error creating '%s': %w", dir, err)
+	}
+	var saveErr error
+	save := func(name, content string) {
+		if err := saveResponse(content, filepath.Join(dir, name)); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}
+
+	refiner := &llm.Refiner{
+		Critic:     critic,
+		Revise:     revise,
+		Rules:      rules,
+		Rounds:     refineRounds,
+		MaxRepairs: jsonRepairs,
+		OnCritique: func(round llm.RefineRound) {
+			save(fmt.Sprintf("draft-%d.md", round.Round), round.Draft)
+			data, _ := json.MarshalIndent(round.Critique, "", "  ")
+			save(fmt.Sprintf("critique-%d.json", round.Round), string(data)+"\n")
+			if round.Critique.Approved {
+				fmt.Fprintf(out, "Round %d: the critic approved the draft.\n", round.Round)
+				return
+			}
+			fmt.Fprintf(out, "Round %d: the critic found %d issue(s); revising...\n", round.Round, len(round.Critique.Issues))
+			for _, issue := range round.Critique.Issues {
+				fmt.Fprintf(out, "  - %s: %s\n", issue.Rule, issue.Problem)
+			}
+		},
+	}
+	final, rounds, err := refiner.Refine(runContext, prompt, answer)
+	if n := len(rounds); n > 0 && final != rounds[n-1].Draft {
+		save(fmt.Sprintf("draft-%d.md", n+1), final)
+	}
+	if err != nil {
+		return final, err
+	}
+	if saveErr != nil {
+		return final, fmt.Errorf("error saving drafts: %w", saveErr)
+	}
+	if n := len(rounds); n > 0 && !rounds[n-1].Critique.Approved {
+		fmt.Fprintf(out, "Stopped after %d round(s) without approval.\n", n)
+	}
+	fmt.Fprintf(out, "Drafts and critiques saved in: %s\n", dir)
+	return final, nil
+}
+
+// newCritic builds the critic client from --critic, defaulting to the answering model.
+func newCritic() (llm.JSONGenerator, error) {
+	if criticTarget == "" {
+		return newJudge(flagTarget())
+	}
+	target, err := llm.ParseTarget(criticTarget)
+	if err != nil {
+		return nil, err
+	}
+	return newJudge(target)
+}
+
+// addRefineFlags registers the self-refinement flags on chat.
+func addRefineFlags(c *cobra.Command) {
+	c.Flags().IntVar(&refineRounds, "refine", 0, "Critique and revise the answer up to N rounds against the topic's constraints and formatting")
+	c.Flags().StringVar(&criticTarget, "critic", "", "Critic model as provider:model (default the answering model)")
+}
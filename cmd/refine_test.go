@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_refineDir(t *testing.T) {
+	assert.Equal(t, "out/git/answer.refine", refineDir("out/git/answer.md"))
+}
+
+func Test_refineAnswer_savesDraftsAndCritiques(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "answer.refine")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	writeFile(t, filepath.Join(dir, "draft-9.md"), "stale")
+	refineRounds = 2
+	t.Cleanup(func() { refineRounds = 0 })
+
+	critic := fixedJudge{`{"approved": false, "issues": [{"rule": "Keep paragraphs short", "problem": "Long intro."}]}`}
+	revisions := 0
+	revise := func(string) (string, error) {
+		revisions++
+		return fmt.Sprintf("revision %d", revisions), nil
+	}
+
+	var out bytes.Buffer
+	final, err := refineAnswer(&out, critic, revise, []string{"Keep paragraphs short"}, "Explain Git", "first draft", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "revision 2", final)
+	assert.Contains(t, out.String(), "Round 1: the critic found 1 issue(s); revising...")
+	assert.Contains(t, out.String(), "  - Keep paragraphs short: Long intro.")
+	assert.Contains(t, out.String(), "Stopped after 2 round(s) without approval.")
+
+	for name, want := range map[string]string{"draft-1.md": "first draft", "draft-2.md": "revision 1", "draft-3.md": "revision 2"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+	assert.FileExists(t, filepath.Join(dir, "critique-2.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "draft-9.md"))
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
 )
 
 var rootCmd = &cobra.Command{
@@ -13,11 +15,36 @@ var rootCmd = &cobra.Command{
 	Short:         "Prompt generation + LLM interaction CLI",
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		runContext = cmd.Context()
+		flagChanged = cmd.Flags().Changed
+		usageTracker = llm.NewUsageTracker(maxCost)
+		usageTracker.Report = logUsage
+		if err := setupCassette(recordDir, replayDir); err != nil {
+			return err
+		}
+		return loadLLMConfig(llmConfigPath)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if _, calls := usageTracker.Total(); calls > 1 {
+			fmt.Fprint(cmd.ErrOrStderr(), usageTracker.Summary())
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&llmConfigPath, "llm-config", DefaultConfigPath, "LLM config file (declares extra providers and pricing)")
+	rootCmd.PersistentFlags().Float64Var(&maxCost, "max-cost", 0, "Abort once the run has spent this many USD (0 = no limit)")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record provider HTTP exchanges to this cassette directory")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay provider HTTP exchanges from this cassette directory instead of calling the network")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
 }
 
 func Execute() {
 	godotenv.Load()
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := notifyInterrupt(context.Background(), os.Stderr)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(rootCmd.ErrOrStderr(), "Error: %v\n", err)
 		os.Exit(1)
 	}
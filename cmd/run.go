@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/pipeline"
+)
+
+// Pipeline flags
+var (
+	pipelineOutputDir   string
+	pipelineConcurrency int
+	pipelineFresh       bool
+)
+
+// PipelineRunner executes a pipeline file.
+type PipelineRunner struct {
+	Out       io.Writer
+	NewClient func(s pipeline.Settings) (pipeline.Client, error)
+}
+
+// Run executes the pipeline and reports whether every step succeeded.
+func (r *PipelineRunner) Run(pipelinePath string) bool {
+	p, err := pipeline.Load(pipelinePath)
+	if err != nil {
+		log.Fatalf("Pipeline error: %v", err)
+	}
+	// Steps and the pipeline's defaults take precedence over the CLI flags.
+	cfg := llmConfigFromFlags()
+	if p.Defaults.Provider == "" {
+		p.Defaults.Provider = cfg.Provider
+	}
+	if p.Defaults.Model == "" {
+		p.Defaults.Model = cfg.Model.Name
+	}
+	if p.Defaults.Temperature == nil {
+		t := cfg.Model.Temperature
+		p.Defaults.Temperature = &t
+	}
+	customDir := pipelineOutputDir
+	if customDir == "" {
+		customDir = p.OutputDir
+	}
+	outDir := paths.GetPipelineOutputDir(p.Name, customDir)
+
+	fmt.Fprintf(r.Out, "Running pipeline %s (%d steps) into %s...\n", p.Name, len(p.Steps), outDir)
+	runner := &pipeline.Runner{
+		NewClient:   r.NewClient,
+		Concurrency: pipelineConcurrency,
+		JSONRepairs: jsonRepairs,
+		Fresh:       pipelineFresh,
+		OnResult: func(res pipeline.StepResult) {
+			switch res.Status {
+			case pipeline.StatusDone:
+				fmt.Fprintf(r.Out, "  ✓ %s (%s) %s → %s\n", res.ID, res.Target, res.Duration.Round(time.Millisecond), res.Output)
+			case pipeline.StatusReused:
+				fmt.Fprintf(r.Out, "  = %s reused from the previous run → %s\n", res.ID, res.Output)
+			default:
+				fmt.Fprintf(r.Out, "  ✗ %s %s: %s\n", res.ID, res.Status, res.Error)
+			}
+		},
+	}
+
+	res, err := runner.Run(runContext, p, outDir)
+	if interrupted(err) {
+		fmt.Fprintln(r.Out, "Interrupted; finished steps are saved. Re-run to resume.")
+		exit(ExitInterrupted)
+		return false
+	}
+	if err != nil {
+		log.Fatalf("Pipeline error: %v", err)
+	}
+	if failed := res.Failed(); len(failed) > 0 {
+		fmt.Fprintf(r.Out, "Pipeline incomplete: %s did not finish. Re-run to resume from the failed steps.\n", strings.Join(failed, ", "))
+		return false
+	}
+	fmt.Fprintf(r.Out, "Pipeline complete. Outputs: %s\n", outDir)
+	return true
+}
+
+// newPipelineClient builds a client for one step, applying its provider, model
+// and temperature to the CLI flags.
+func newPipelineClient(s pipeline.Settings) (pipeline.Client, error) {
+	cfg := llmConfigFromFlags()
+	cfg.Provider = s.Provider
+	cfg.Model.Name = s.Model
+	if s.Temperature != nil {
+		cfg.Model.Temperature = *s.Temperature
+	}
+	return newLLMClientFromConfig(cfg)
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run <pipeline.yaml>",
+	Short: "Run a multi-step prompt pipeline",
+	Long: `A pipeline is a DAG of LLM calls. Each step renders a template (with a topic
+or chart config) or an inline prompt; the outputs of the steps it needs are
+available in its template as {{ <id>.text }} and, for JSON steps, {{ <id>.json }}.
+Independent steps run concurrently. Each answer is saved in the output dir, and
+re-running after a failure reuses the steps that already finished.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &PipelineRunner{Out: os.Stdout, NewClient: newPipelineClient}
+		if !runner.Run(args[0]) {
+			return fmt.Errorf("pipeline did not complete")
+		}
+		return nil
+	},
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "Default LLM provider")
+	runCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "Default LLM model")
+	runCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Default temperature")
+	runCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout per step")
+	runCmd.Flags().StringVarP(&pipelineOutputDir, "output", "o", "", "Output dir (default the pipeline's output_dir, or resources/output/pipelines/<name>)")
+	runCmd.Flags().IntVarP(&pipelineConcurrency, "concurrency", "c", 4, "Steps run at once")
+	runCmd.Flags().BoolVar(&pipelineFresh, "fresh", false, "Re-run every step instead of reusing answers from a previous run")
+	runCmd.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when a JSON step fails validation")
+	addGenerationFlags(runCmd)
+	addTimeoutFlags(runCmd)
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipeline = `name: notes
+steps:
+  - id: explain
+    prompt: "Explain Git."
+  - id: summary
+    needs: [explain]
+    prompt: "Summarize: {{ explain.text }}"
+`
+
+// usePipelineFixtures points the fake provider at fixtures answering the
+// test pipeline, failing the summary step when failSummary is set.
+func usePipelineFixtures(t *testing.T, path string, failSummary bool) {
+	t.Helper()
+	summary := `response: "Git tracks snapshots."`
+	if failSummary {
+		summary = `error: "model overloaded"`
+	}
+	writeFile(t, path, "fixtures:\n  - match: \"^Explain Git\"\n    response: \"Git is a version control system.\"\n"+
+		"  - match: \"^Summarize: Git is a version control system\"\n    "+summary+"\n")
+	providerName, modelName, providerOptions = "fake", "notes", map[string]string{"fixtures": path}
+}
+
+func TestPipelineRunner_FakeProviderResumes(t *testing.T) {
+	dir := t.TempDir()
+	pipelinePath := filepath.Join(dir, "notes.yaml")
+	writeFile(t, pipelinePath, testPipeline)
+	pipelineOutputDir, pipelineConcurrency, pipelineFresh = filepath.Join(dir, "out"), 2, false
+	t.Cleanup(func() {
+		pipelineOutputDir, providerName, modelName, providerOptions = "", "", "", nil
+	})
+
+	var out bytes.Buffer
+	runner := &PipelineRunner{Out: &out, NewClient: newPipelineClient}
+	usePipelineFixtures(t, filepath.Join(dir, "failing.yaml"), true)
+	assert.False(t, runner.Run(pipelinePath))
+	assert.Contains(t, out.String(), "  ✓ explain (fake:notes)")
+	assert.Contains(t, out.String(), "  ✗ summary failed: ")
+	assert.Contains(t, out.String(), "Pipeline incomplete: summary did not finish.")
+
+	out.Reset()
+	usePipelineFixtures(t, filepath.Join(dir, "fixed.yaml"), false)
+	assert.True(t, runner.Run(pipelinePath))
+	assert.Contains(t, out.String(), "  = explain reused from the previous run → "+filepath.Join(pipelineOutputDir, "explain.md"))
+	assert.Contains(t, out.String(), "  ✓ summary (fake:notes)")
+	assert.Contains(t, out.String(), "Pipeline complete. Outputs: "+pipelineOutputDir)
+
+	summary, err := os.ReadFile(filepath.Join(pipelineOutputDir, "summary.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Git tracks snapshots.", string(summary))
+
+	out.Reset()
+	pipelineFresh = true
+	t.Cleanup(func() { pipelineFresh = false })
+	assert.True(t, runner.Run(pipelinePath))
+	assert.NotContains(t, out.String(), "reused", "--fresh re-runs every step")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// Sampling flags
+var (
+	sampleCount int
+	sampleJudge string
+)
+
+// SamplesFile lists the similarity scores of the samples in the alternatives dir.
+const SamplesFile = "samples.json"
+
+// alternativesDir is where the samples of an answer are kept.
+func alternativesDir(answerPath string) string {
+	return filepath.Join(filepath.Dir(answerPath), "alternatives")
+}
+
+// answerInteraction returns the call that answers a plain prompt: sampled when
+// --samples is above 1, else streamed through the semantic cache.
+func answerInteraction(out io.Writer, stream *ResponseStream) func(prompt string) (string, error) {
+	if sampleCount > 1 {
+		return sampleLLMInteraction(out, stream)
+	}
+	return withSemanticCache(out, streamLLMInteraction(stream))
+}
+
+// sampleLLMInteraction returns an LLM call that generates --samples answers
+// concurrently and returns the chosen one. Samples are not streamed; the
+// chosen response is recorded on stream for its metadata.
+func sampleLLMInteraction(out io.Writer, stream *ResponseStream) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		client, err := newLLMClient()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(out, "Generating %d samples...\n", sampleCount)
+		sampling, err := llm.GenerateSamples(runContext, client, prompt, sampleCount)
+		if err != nil {
+			return "", err
+		}
+		for _, s := range sampling.Samples {
+			if s.Error != "" {
+				log.Printf("[samples] sample %d failed: %s", s.Index, s.Error)
+			}
+		}
+
+		chooseSample(sampling, prompt, newJudge)
+		best := sampling.Best()
+		fmt.Fprintf(out, "Chose sample %d of %d (%s)\n", best.Index, len(sampling.Samples), sampling.Method)
+		if sampling.Reason != "" {
+			fmt.Fprintf(out, "Judge: %s\n", sampling.Reason)
+		}
+		if responseFilePath != "" {
+			dir := alternativesDir(responseFilePath)
+			if err := saveSamples(sampling, dir); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(out, "Samples saved in: %s\n", dir)
+		}
+		if stream != nil {
+			stream.last = best.Response
+		}
+		return best.Text, nil
+	}
+}
+
+// chooseSample scores the samples against their centroid and, with --sample-judge,
+// lets the judge pick. Failures are logged and keep the previous choice.
+func chooseSample(sampling *llm.Sampling, prompt string, newJudge func(llm.Target) (llm.JSONGenerator, error)) {
+	if len(sampling.Succeeded()) < 2 {
+		return
+	}
+	if embedder, err := newEmbedder(); err != nil {
+		log.Printf("[samples] similarity skipped: %v", err)
+	} else if err := sampling.AddSimilarity(runContext, llm.NewSimilarityService(embedder)); err != nil {
+		log.Printf("[samples] similarity skipped: %v", err)
+	}
+	if sampleJudge == "" {
+		return
+	}
+	target, err := llm.ParseTarget(sampleJudge)
+	if err != nil {
+		log.Printf("[samples] judge skipped: %v", err)
+		return
+	}
+	judge, err := newJudge(target)
+	if err != nil {
+		log.Printf("[samples] judge skipped: %v", err)
+		return
+	}
+	if err := sampling.PickWithJudge(runContext, judge, prompt, jsonRepairs); err != nil {
+		log.Printf("[samples] judge skipped: %v", err)
+	}
+}
+
+// saveSamples writes every answer as sample-N.md and their scores to SamplesFile.
+func saveSamples(sampling *llm.Sampling, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating '%s': %w", dir, err)
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, "sample-*.md"))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+	for _, s := range sampling.Succeeded() {
+		if err := saveResponse(s.Text, filepath.Join(dir, fmt.Sprintf("sample-%d.md", s.Index))); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(sampling, "", "  ")
+	if err != nil {
+		return err
+	}
+	return saveResponse(string(data)+"\n", filepath.Join(dir, SamplesFile))
+}
+
+// addSampleFlags registers the self-consistency sampling flags on llm and chat.
+func addSampleFlags(c *cobra.Command) {
+	c.Flags().IntVar(&sampleCount, "samples", 1, "Generate N answers concurrently and keep the most central one")
+	c.Flags().StringVar(&sampleJudge, "sample-judge", "", "Judge model as provider:model that picks the best sample instead")
+}
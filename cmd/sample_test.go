@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// lengthEmbedder embeds a text by its length, so texts of similar length are similar.
+type lengthEmbedder struct{}
+
+func (lengthEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	vecs := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		vecs[i] = []float32{1, float32(len(in)) / 10}
+	}
+	return vecs, nil
+}
+
+func testSampling() *llm.Sampling {
+	return &llm.Sampling{Chosen: 1, Method: llm.PickFirst, Samples: []llm.Sample{
+		{Index: 1, Text: "a very much longer answer than the rest"},
+		{Index: 2, Text: "medium answer"},
+		{Index: 3, Text: "short"},
+		{Index: 4, Error: "503 service unavailable"},
+	}}
+}
+
+func Test_chooseSample(t *testing.T) {
+	origEmbedder := newEmbedder
+	newEmbedder = func() (wrapper.Embedder, error) { return lengthEmbedder{}, nil }
+	t.Cleanup(func() { newEmbedder, sampleJudge = origEmbedder, "" })
+	noJudge := func(llm.Target) (llm.JSONGenerator, error) { panic("judge not configured") }
+
+	sampling := testSampling()
+	chooseSample(sampling, "Explain Git", noJudge)
+	assert.Equal(t, llm.PickCentroid, sampling.Method)
+	assert.Equal(t, 2, sampling.Chosen)
+
+	sampleJudge = "openai:gpt-4o"
+	var judged llm.Target
+	sampling = testSampling()
+	chooseSample(sampling, "Explain Git", func(target llm.Target) (llm.JSONGenerator, error) {
+		judged = target
+		return fixedJudge{`{"best": 3, "reason": "Most concise."}`}, nil
+	})
+	assert.Equal(t, llm.Target{Provider: "openai", Model: "gpt-4o"}, judged)
+	assert.Equal(t, llm.PickJudge, sampling.Method)
+	assert.Equal(t, 3, sampling.Chosen)
+	assert.NotZero(t, sampling.Samples[2].Similarity)
+}
+
+func Test_saveSamples(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "alternatives")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	writeFile(t, filepath.Join(dir, "sample-9.md"), "stale")
+
+	require.NoError(t, saveSamples(testSampling(), dir))
+	assert.NoFileExists(t, filepath.Join(dir, "sample-9.md"))
+	assert.NoFileExists(t, filepath.Join(dir, "sample-4.md"))
+	data, err := os.ReadFile(filepath.Join(dir, "sample-3.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "short", string(data))
+
+	var saved llm.Sampling
+	data, err = os.ReadFile(filepath.Join(dir, SamplesFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Len(t, saved.Samples, 4)
+	assert.Equal(t, "503 service unavailable", saved.Samples[3].Error)
+}
+
+func Test_alternativesDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "git", "alternatives"), alternativesDir("out/git/answer.md"))
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Tool flags
+var (
+	toolNames         []string
+	toolRepo          string
+	glossaryPath      string
+	maxToolIterations int
+)
+
+// builtinTools are the local tools --tool can enable.
+var builtinTools = map[string]func() llm.Tool{
+	"git_log":  gitLogTool,
+	"glossary": glossaryTool,
+}
+
+// toolRegistry builds the registry of the tools named by --tool, or nil when none are.
+func toolRegistry() (*llm.ToolRegistry, error) {
+	if len(toolNames) == 0 {
+		return nil, nil
+	}
+	var tools []llm.Tool
+	for _, name := range toolNames {
+		build, ok := builtinTools[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(builtinToolNames(), ", "))
+		}
+		tools = append(tools, build())
+	}
+	return llm.NewToolRegistry(tools...)
+}
+
+func builtinToolNames() []string {
+	names := make([]string, 0, len(builtinTools))
+	for name := range builtinTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// logToolCall prints each tool call as the model makes it.
+func logToolCall(target string, call llm.ToolCall) {
+	log.Printf("[tool] %s: %s", target, call)
+}
+
+// gitLogTool lists recent commits of the --tool-repo repository.
+func gitLogTool() llm.Tool {
+	return llm.Tool{
+		Name:        "git_log",
+		Description: "Show recent commits (git log --oneline) of the sample repository, optionally only those touching a path.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit": map[string]any{"type": "integer", "minimum": 1, "maximum": 50, "description": "Number of commits to show (default 10)"},
+				"path":  map[string]any{"type": "string", "description": "Only show commits touching this file or directory"},
+			},
+			"additionalProperties": false,
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Limit int    `json:"limit"`
+				Path  string `json:"path"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", err
+			}
+			if args.Limit == 0 {
+				args.Limit = 10
+			}
+			cmdArgs := []string{"-C", toolRepo, "log", "--oneline", "--no-color", fmt.Sprintf("-n%d", args.Limit)}
+			if args.Path != "" {
+				cmdArgs = append(cmdArgs, "--", args.Path)
+			}
+			out, err := exec.CommandContext(ctx, "git", cmdArgs...).CombinedOutput()
+			if err != nil {
+				return "", fmt.Errorf("git log failed: %v: %s", err, strings.TrimSpace(string(out)))
+			}
+			if len(out) == 0 {
+				return "(no commits)", nil
+			}
+			return string(out), nil
+		},
+	}
+}
+
+// glossaryTool looks terms up in the --glossary file, or in the concepts of the
+// topic config when no glossary is given.
+func glossaryTool() llm.Tool {
+	return llm.Tool{
+		Name:        "glossary",
+		Description: "Look up the definition of a term in the topic's glossary.",
+		Parameters: map[string]any{
+			"type":                 "object",
+			"required":             []any{"term"},
+			"properties":           map[string]any{"term": map[string]any{"type": "string", "minLength": 1}},
+			"additionalProperties": false,
+		},
+		Handler: func(_ context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Term string `json:"term"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", err
+			}
+			glossary, err := loadGlossary()
+			if err != nil {
+				return "", err
+			}
+			for term, definition := range glossary {
+				if strings.EqualFold(term, strings.TrimSpace(args.Term)) {
+					return term + ": " + definition, nil
+				}
+			}
+			terms := make([]string, 0, len(glossary))
+			for term := range glossary {
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+			return fmt.Sprintf("%q is not in the glossary. Known terms: %s", args.Term, strings.Join(terms, ", ")), nil
+		},
+	}
+}
+
+// loadGlossary reads the term → definition map the glossary tool searches.
+func loadGlossary() (map[string]string, error) {
+	if glossaryPath != "" {
+		glossary, err := promptConfig.ReadYAML[map[string]string](glossaryPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading glossary '%s': %w", glossaryPath, err)
+		}
+		return glossary, nil
+	}
+	if configPath == "" {
+		return nil, fmt.Errorf("no glossary available; pass --glossary")
+	}
+	cfg, err := promptConfig.ReadTopicConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config '%s': %w", configPath, err)
+	}
+	glossary := map[string]string{}
+	for _, concept := range cfg.Concepts {
+		// Concepts read "**Term**: definition".
+		term, definition, _ := strings.Cut(concept, ":")
+		glossary[strings.Trim(strings.TrimSpace(term), "*_`")] = strings.TrimSpace(definition)
+	}
+	return glossary, nil
+}
+
+// addToolFlags registers the tool calling flags on llm and chat.
+func addToolFlags(c *cobra.Command) {
+	c.Flags().StringSliceVar(&toolNames, "tool", nil, "Let the model call a local tool: "+strings.Join(builtinToolNames(), ", ")+" (repeatable)")
+	c.Flags().StringVar(&toolRepo, "tool-repo", ".", "Repository the git_log tool reads")
+	c.Flags().StringVar(&glossaryPath, "glossary", "", "YAML map of term: definition for the glossary tool (default the topic's concepts)")
+	c.Flags().IntVar(&maxToolIterations, "max-tool-iterations", llm.DefaultMaxToolIterations, "Model turns that may request tools per call")
+}
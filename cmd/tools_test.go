@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_toolRegistry(t *testing.T) {
+	t.Cleanup(func() { toolNames = nil })
+
+	toolNames = nil
+	registry, err := toolRegistry()
+	require.NoError(t, err)
+	assert.Nil(t, registry)
+
+	toolNames = []string{"git_log", "glossary"}
+	registry, err = toolRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git_log", "glossary"}, registry.Names())
+
+	toolNames = []string{"shell"}
+	_, err = toolRegistry()
+	assert.EqualError(t, err, `unknown tool "shell" (available: git_log, glossary)`)
+}
+
+func Test_glossaryTool(t *testing.T) {
+	dir := t.TempDir()
+	configPath = filepath.Join(dir, "git.yaml")
+	writeFile(t, configPath, "concepts:\n  - \"**Commits**: How they capture project states\"\n")
+	t.Cleanup(func() { configPath, glossaryPath = "", "" })
+	lookup := func(term string) string {
+		args, _ := json.Marshal(map[string]string{"term": term})
+		out, err := glossaryTool().Handler(context.Background(), args)
+		require.NoError(t, err)
+		return out
+	}
+
+	assert.Equal(t, "Commits: How they capture project states", lookup("commits"))
+	assert.Equal(t, `"rebase" is not in the glossary. Known terms: Commits`, lookup("rebase"))
+
+	glossaryPath = filepath.Join(dir, "glossary.yaml")
+	writeFile(t, glossaryPath, "branch: A line of commits\n")
+	assert.Equal(t, "branch: A line of commits", lookup("Branch"))
+}
+
+func Test_gitLogTool(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	toolRepo = t.TempDir()
+	t.Cleanup(func() { toolRepo = "." })
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", toolRepo, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	writeFile(t, filepath.Join(toolRepo, "plan.md"), "day 1")
+	git("add", "plan.md")
+	git("commit", "-qm", "Add trip plan")
+	git("commit", "-q", "--allow-empty", "-m", "Pick a route")
+
+	out, err := gitLogTool().Handler(context.Background(), json.RawMessage(`{"limit": 1}`))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pick a route")
+	assert.NotContains(t, out, "Add trip plan")
+
+	out, err = gitLogTool().Handler(context.Background(), json.RawMessage(`{"path": "plan.md"}`))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Add trip plan")
+	assert.NotContains(t, out, "Pick a route")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/scaffold"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Topic scaffolding flags
+var (
+	topicAudience    string
+	topicStage       string
+	topicTitle       string
+	topicConfigOut   string
+	topicYes         bool
+	topicInteractive bool
+)
+
+// TopicNewRunner drafts a topic config, by asking the model or the user, and
+// writes it once confirmed.
+type TopicNewRunner struct {
+	Out       io.Writer
+	In        io.Reader
+	NewClient func() (llm.JSONGenerator, error)
+}
+
+// Run drafts the config of topic name and reports whether it was written.
+func (r *TopicNewRunner) Run(name string) bool {
+	path := paths.GetConfigPath(name, topicConfigOut)
+	req := scaffold.TopicRequest{Topic: topicTitle, Audience: topicAudience, Stage: topicStage}
+	if req.Topic == "" {
+		req.Topic = displayName(name)
+	}
+	prompter := scaffold.NewPrompter(r.In, r.Out)
+
+	var cfg promptConfig.TopicConfig
+	var err error
+	if topicInteractive {
+		cfg, err = scaffold.AskTopic(prompter, req.Base())
+	} else {
+		cfg, err = r.propose(req)
+	}
+	if interrupted(err) {
+		exit(ExitInterrupted)
+		return false
+	}
+	if err != nil {
+		log.Fatalf("Topic config error: %v", err)
+	}
+	if problems := scaffold.ValidateTopic(cfg); len(problems) > 0 {
+		log.Fatalf("Invalid topic config:\n- %s", strings.Join(problems, "\n- "))
+	}
+
+	data := scaffold.MarshalTopic(cfg)
+	old, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(r.Out, "\nNew config %s:\n%s\n", path, data)
+	case err != nil:
+		log.Fatalf("Error reading '%s': %v", path, err)
+	case string(old) == string(data):
+		fmt.Fprintf(r.Out, "%s is already up to date.\n", path)
+		return false
+	default:
+		fmt.Fprintf(r.Out, "\nChanges to %s:\n%s\n", path, scaffold.Diff(string(old), string(data)))
+	}
+
+	if !topicYes && !prompter.Confirm("Write "+path+"?") {
+		fmt.Fprintln(r.Out, "Not written.")
+		return false
+	}
+	paths.EnsureDirectoryExists(path)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Fatalf("Save error: %v", err)
+	}
+	fmt.Fprintf(r.Out, "Topic config saved to: %s\n", path)
+	return true
+}
+
+// propose asks the model for the fields the user did not decide.
+func (r *TopicNewRunner) propose(req scaffold.TopicRequest) (promptConfig.TopicConfig, error) {
+	if req.Audience == "" || req.Stage == "" {
+		return promptConfig.TopicConfig{}, fmt.Errorf("--audience and --stage are required unless --interactive is set")
+	}
+	client, err := r.NewClient()
+	if err != nil {
+		return promptConfig.TopicConfig{}, err
+	}
+	fmt.Fprintf(r.Out, "Drafting the %s config for %s (%s)...\n", req.Topic, req.Audience, req.Stage)
+	return scaffold.ProposeTopic(runContext, client, req, jsonRepairs)
+}
+
+// displayName turns a config name into a topic name, e.g. "docker-compose" → "Docker compose".
+func displayName(name string) string {
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	first, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// newTopicDrafter builds the client that drafts topic configs.
+func newTopicDrafter() (llm.JSONGenerator, error) {
+	return newLLMClient()
+}
+
+var topicCmd = &cobra.Command{
+	Use:   "topic",
+	Short: "Manage topic configs",
+}
+
+var topicNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Draft a topic config with the model or a questionnaire",
+	Long: `Asks the model to propose the concepts, analogies, explanation requirements,
+formatting, constraints, output format, purpose and tone of a topic for the
+given audience and learning stage, as JSON matching the topic config. With
+--interactive, asks for each field instead, without calling a model.
+
+The draft is validated and shown, as a diff when the config already exists,
+and written to resources/configs/<name>.yaml once confirmed (or with --yes).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		(&TopicNewRunner{Out: os.Stdout, In: os.Stdin, NewClient: newTopicDrafter}).Run(args[0])
+	},
+}
+
+func init() {
+	topicNewCmd.Flags().StringVar(&topicAudience, "audience", "", "Who the topic is taught to, e.g. \"backend devs\"")
+	topicNewCmd.Flags().StringVar(&topicStage, "stage", "", "Learning stage of the audience, e.g. intermediate")
+	topicNewCmd.Flags().StringVar(&topicTitle, "title", "", "Topic name used in prompts (default the capitalized config name)")
+	topicNewCmd.Flags().StringVarP(&topicConfigOut, "output", "o", "", "Config file to write (default resources/configs/<name>.yaml)")
+	topicNewCmd.Flags().BoolVarP(&topicYes, "yes", "y", false, "Write without asking for confirmation")
+	topicNewCmd.Flags().BoolVarP(&topicInteractive, "interactive", "i", false, "Answer a questionnaire instead of asking the model")
+	topicNewCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "LLM provider")
+	topicNewCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model")
+	topicNewCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature")
+	topicNewCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout duration")
+	topicNewCmd.Flags().IntVar(&jsonRepairs, "json-retries", llm.DefaultJSONRepairs, "Re-prompts allowed when the draft fails schema validation")
+	addGenerationFlags(topicNewCmd)
+	addTimeoutFlags(topicNewCmd)
+
+	topicCmd.AddCommand(topicNewCmd)
+	rootCmd.AddCommand(topicCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+const topicDraft = `{"context": "backend work", "analogies": "shipping containers",
+  "concepts": ["**Images**: What they package", "**Containers**: How they run", "**Volumes**: Where data lives"],
+  "explanation_requirements": ["Show a Dockerfile", "Compare images and containers"],
+  "formatting": ["Use headers", "Use code blocks"], "constraints": ["Assume Linux", "Skip Kubernetes"],
+  "output_format": ["Overview", "Cheat sheet"], "purpose": "packaging services", "tone": "practical"}`
+
+func topicNewRunner(out *bytes.Buffer, input string) *TopicNewRunner {
+	return &TopicNewRunner{Out: out, In: strings.NewReader(input), NewClient: func() (llm.JSONGenerator, error) {
+		return fixedJudge{topicDraft}, nil
+	}}
+}
+
+func TestTopicNewRunner(t *testing.T) {
+	topicConfigOut = filepath.Join(t.TempDir(), "docker.yaml")
+	topicAudience, topicStage, topicTitle = "backend devs", "intermediate", ""
+	t.Cleanup(func() {
+		topicConfigOut, topicAudience, topicStage, topicYes, topicInteractive = "", "", "", false, false
+	})
+
+	var out bytes.Buffer
+	assert.False(t, topicNewRunner(&out, "n\n").Run("docker"))
+	assert.Contains(t, out.String(), "New config "+topicConfigOut+":\naudience: \"backend devs\"\n")
+	assert.Contains(t, out.String(), "Not written.")
+	assert.NoFileExists(t, topicConfigOut)
+
+	out.Reset()
+	assert.True(t, topicNewRunner(&out, "y\n").Run("docker"))
+	cfg, err := promptConfig.ReadTopicConfig(topicConfigOut)
+	require.NoError(t, err)
+	assert.Equal(t, "Docker", cfg.Topic)
+	assert.Equal(t, "intermediate", cfg.LearningStage)
+	assert.Len(t, cfg.Concepts, 3)
+
+	out.Reset()
+	assert.False(t, topicNewRunner(&out, "").Run("docker"))
+	assert.Contains(t, out.String(), "is already up to date")
+
+	out.Reset()
+	topicStage, topicYes = "beginner", true
+	assert.True(t, topicNewRunner(&out, "").Run("docker"))
+	assert.Contains(t, out.String(), "Changes to "+topicConfigOut+":\n  audience: \"backend devs\"\n- learning_stage: \"intermediate\"\n+ learning_stage: \"beginner\"\n")
+	assert.NotContains(t, out.String(), "[y/N]")
+}
+
+func TestTopicNewRunner_Interactive(t *testing.T) {
+	topicConfigOut = filepath.Join(t.TempDir(), "configs", "docker-compose.yaml")
+	topicAudience, topicStage, topicTitle, topicInteractive = "", "", "", true
+	t.Cleanup(func() { topicConfigOut, topicInteractive = "", false })
+
+	answers := "\nops engineers\nbeginner\n\n\n**Services**: What a service is\n\n\n\n\n\nrunning stacks\nhands-on\ny\n"
+	var out bytes.Buffer
+	runner := &TopicNewRunner{Out: &out, In: strings.NewReader(answers), NewClient: func() (llm.JSONGenerator, error) {
+		t.Fatal("the questionnaire does not call a model")
+		return nil, nil
+	}}
+	require.True(t, runner.Run("docker-compose"))
+
+	data, err := os.ReadFile(topicConfigOut)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "topic: \"Docker compose\"\n")
+	assert.Contains(t, string(data), "concepts:\n  - \"**Services**: What a service is\"\n")
+	assert.Contains(t, string(data), "\nformatting: []\n")
+	assert.Contains(t, string(data), "tone: \"hands-on\"\n")
+}
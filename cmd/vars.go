@@ -9,7 +9,8 @@ const (
 	DefaultModel       = "phi4"
 	DefaultTemperature = 0.8
 	DefaultTimeout     = 2 * time.Minute
-	DefaultPromptPath  = "resources/default/prompt.txt" // Ensure a valid default prompt path
+	DefaultPromptPath  = "resources/default/prompt.txt"  // Ensure a valid default prompt path
+	DefaultCachePath   = "resources/cache/semantic.json" // Semantic cache index
 )
 
 // Define flags as global variables to avoid scope issues
@@ -23,9 +24,52 @@ var (
 
 // CLI flags
 var (
-	providerName string
-	modelName    string
-	temperature  float64
-	promptPath   string
-	timeout      time.Duration
+	llmConfigPath string
+	maxCost       float64
+	recordDir     string
+	replayDir     string
+	providerName  string
+	modelName     string
+	temperature   float64
+	promptPath    string
+	timeout       time.Duration
+)
+
+// Attachment flags
+var (
+	attachPaths []string
+	visionModel bool
+)
+
+// Streaming timeout flags
+var (
+	firstTokenTimeout time.Duration
+	idleTimeout       time.Duration
+)
+
+// Generation parameter flags
+var (
+	maxTokens         int
+	topP              float64
+	topK              int
+	stopSequences     []string
+	seed              int
+	frequencyPenalty  float64
+	presencePenalty   float64
+	repetitionPenalty float64
+	providerOptions   map[string]string
+	autoContinue      int
+)
+
+// Structured output flags
+var (
+	jsonSchemaPath string
+	jsonRepairs    int
+)
+
+// Semantic cache flags
+var (
+	useSemanticCache bool
+	cacheThreshold   float64
+	cachePath        string
 )
@@ -18,22 +18,55 @@ const (
 )
 
 // ModelConfig holds configuration specific to the language model.
+// Zero values leave the provider's default in place.
 type ModelConfig struct {
-	Name        string  // Name of the model
-	Temperature float64 // Temperature setting
+	Name              string         // Name of the model
+	Temperature       float64        // Temperature setting
+	MaxTokens         int            `yaml:"max_tokens"`         // Maximum tokens to generate
+	TopP              float64        `yaml:"top_p"`              // Nucleus sampling probability mass
+	TopK              int            `yaml:"top_k"`              // Number of candidate tokens for top-k sampling
+	Stop              []string       `yaml:"stop"`               // Stop sequences
+	Seed              int            `yaml:"seed"`               // Seed for reproducible sampling
+	FrequencyPenalty  float64        `yaml:"frequency_penalty"`  // Penalize frequent tokens
+	PresencePenalty   float64        `yaml:"presence_penalty"`   // Penalize tokens already present
+	RepetitionPenalty float64        `yaml:"repetition_penalty"` // Penalize repeated tokens (Ollama, HF)
+	Vision            bool           `yaml:"vision"`             // Accepts images, for models not recognized as vision-capable
+	Options           map[string]any `yaml:"options"`            // Provider-specific passthrough, e.g. Ollama's num_ctx
 }
 
 // ClientConfig holds runtime behavior configuration.
 type ClientConfig struct {
-	Timeout        time.Duration // Maximum request time
-	VerboseLogging bool          // Enable verbose logs
+	Timeout           time.Duration // Maximum request time
+	FirstTokenTimeout time.Duration `yaml:"first_token_timeout"` // Maximum wait for the first streamed chunk (0 = no limit)
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`        // Maximum gap between streamed chunks (0 = no limit)
+	VerboseLogging    bool          `yaml:"verbose_logging"`     // Echo answers as they stream
+	AutoContinue      int           `yaml:"auto_continue"`       // Continuation requests allowed after length stops
+	MaxToolIterations int           `yaml:"max_tool_iterations"` // Model turns that may request tools per call (0 = default)
+}
+
+// ProviderConfig declares an additional provider, usable anywhere a provider name is accepted.
+type ProviderConfig struct {
+	Name         string            `yaml:"name"`         // Name passed to --provider
+	Type         string            `yaml:"type"`         // Provider type, e.g. "openai-compatible"
+	BaseURL      string            `yaml:"base_url"`     // Server base URL, e.g. "http://vllm:8000/v1"
+	APIKeyEnv    string            `yaml:"api_key_env"`  // Env var holding the API key (optional)
+	Organization string            `yaml:"organization"` // OpenAI organization header (optional)
+	Headers      map[string]string `yaml:"headers"`      // Default headers sent with every request
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input  float64 `yaml:"input"`  // Prompt tokens
+	Output float64 `yaml:"output"` // Completion tokens
 }
 
 // Config aggregates model and client configurations.
 type Config struct {
-	Provider string
-	Model    ModelConfig
-	Client   ClientConfig
+	Provider  string
+	Model     ModelConfig
+	Client    ClientConfig
+	Providers []ProviderConfig `yaml:"providers"`
+	Pricing   map[string]Price `yaml:"pricing"` // Overrides keyed by "provider:model" or model name
 }
 
 // Dependency injection: package-level variable for file reading.
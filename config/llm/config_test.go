@@ -90,3 +90,45 @@ func TestConfigLoaderParseError(t *testing.T) {
 		t.Errorf("Expected error to contain 'failed to parse config YAML', got %v", err)
 	}
 }
+
+// TestConfigLoaderGenerationParams checks the extended model settings and passthrough options.
+func TestConfigLoaderGenerationParams(t *testing.T) {
+	origReadFile := readFile
+	defer func() { readFile = origReadFile }()
+
+	readFile = func(filename string) ([]byte, error) {
+		return []byte(`
+provider: "ollama"
+model:
+  name: "phi4"
+  max_tokens: 512
+  top_p: 0.9
+  top_k: 40
+  stop: ["###", "END"]
+  seed: 7
+  frequency_penalty: 0.1
+  presence_penalty: 0.2
+  repetition_penalty: 1.1
+  options:
+    num_ctx: 8192
+`), nil
+	}
+
+	cfg, err := ConfigLoader("dummy.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	m := cfg.Model
+	if m.MaxTokens != 512 || m.TopP != 0.9 || m.TopK != 40 || m.Seed != 7 {
+		t.Errorf("Unexpected sampling settings: %+v", m)
+	}
+	if len(m.Stop) != 2 || m.Stop[1] != "END" {
+		t.Errorf("Expected stop sequences [### END], got %v", m.Stop)
+	}
+	if m.FrequencyPenalty != 0.1 || m.PresencePenalty != 0.2 || m.RepetitionPenalty != 1.1 {
+		t.Errorf("Unexpected penalties: %+v", m)
+	}
+	if m.Options["num_ctx"] != 8192 {
+		t.Errorf("Expected options.num_ctx 8192, got %v", m.Options["num_ctx"])
+	}
+}
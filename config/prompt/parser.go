@@ -27,7 +27,8 @@ func ReadYAML[T any](filePath string) (T, error) {
 // -------------------- Template --------------------
 
 type Template struct {
-	Template string `yaml:"template"`
+	Template     string         `yaml:"template"`
+	OutputSchema map[string]any `yaml:"output_schema"` // Optional JSON Schema for structured answers
 }
 
 func ReadTemplate(filePath string) (Template, error) {
@@ -36,19 +37,20 @@ func ReadTemplate(filePath string) (Template, error) {
 
 // -------------------- Topic Prompt --------------------
 
+// TopicConfig also decodes from JSON, the form `topic new` asks the model for.
 type TopicConfig struct {
-	Audience                string   `yaml:"audience"`
-	LearningStage           string   `yaml:"learning_stage"`
-	Topic                   string   `yaml:"topic"`
-	Context                 string   `yaml:"context"`
-	Analogies               string   `yaml:"analogies"`
-	Concepts                []string `yaml:"concepts"`
-	ExplanationRequirements []string `yaml:"explanation_requirements"`
-	Formatting              []string `yaml:"formatting"`
-	Constraints             []string `yaml:"constraints"`
-	OutputFormat            []string `yaml:"output_format"`
-	Purpose                 string   `yaml:"purpose"`
-	Tone                    string   `yaml:"tone"`
+	Audience                string   `yaml:"audience" json:"audience"`
+	LearningStage           string   `yaml:"learning_stage" json:"learning_stage"`
+	Topic                   string   `yaml:"topic" json:"topic"`
+	Context                 string   `yaml:"context" json:"context"`
+	Analogies               string   `yaml:"analogies" json:"analogies"`
+	Concepts                []string `yaml:"concepts" json:"concepts"`
+	ExplanationRequirements []string `yaml:"explanation_requirements" json:"explanation_requirements"`
+	Formatting              []string `yaml:"formatting" json:"formatting"`
+	Constraints             []string `yaml:"constraints" json:"constraints"`
+	OutputFormat            []string `yaml:"output_format" json:"output_format"`
+	Purpose                 string   `yaml:"purpose" json:"purpose"`
+	Tone                    string   `yaml:"tone" json:"tone"`
 }
 
 func ReadTopicConfig(filePath string) (TopicConfig, error) {
@@ -57,33 +59,34 @@ func ReadTopicConfig(filePath string) (TopicConfig, error) {
 
 // -------------------- Flowchart Prompt --------------------
 
+// ChartConfig also decodes from JSON, the form `chart generate` asks the model for.
 type ChartConfig struct {
-	FlowDirection  string            `yaml:"flow_direction"`
-	Style          map[string]string `yaml:"style"`
-	PlanningPhase  Phase             `yaml:"planning_phase"`
-	PlanningLinks  []Link            `yaml:"planning_links"`
-	ExecutionPhase Phase             `yaml:"execution_phase"`
-	ExecutionLinks []Link            `yaml:"execution_links"`
-	TransitionLink Link              `yaml:"transition_link"`
+	FlowDirection  string            `yaml:"flow_direction" json:"flow_direction"`
+	Style          map[string]string `yaml:"style" json:"style"`
+	PlanningPhase  Phase             `yaml:"planning_phase" json:"planning_phase"`
+	PlanningLinks  []Link            `yaml:"planning_links" json:"planning_links"`
+	ExecutionPhase Phase             `yaml:"execution_phase" json:"execution_phase"`
+	ExecutionLinks []Link            `yaml:"execution_links" json:"execution_links"`
+	TransitionLink Link              `yaml:"transition_link" json:"transition_link"`
 }
 
 type Phase struct {
-	Title     string `yaml:"title"`
-	Emoji     string `yaml:"emoji"`
-	Direction string `yaml:"direction"`
-	Steps     []Step `yaml:"steps"`
+	Title     string `yaml:"title" json:"title"`
+	Emoji     string `yaml:"emoji" json:"emoji"`
+	Direction string `yaml:"direction" json:"direction"`
+	Steps     []Step `yaml:"steps" json:"steps"`
 }
 
 type Step struct {
-	ID          string `yaml:"id"`
-	Emoji       string `yaml:"emoji"`
-	Title       string `yaml:"title"`
-	Description string `yaml:"description"`
+	ID          string `yaml:"id" json:"id"`
+	Emoji       string `yaml:"emoji" json:"emoji"`
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description" json:"description"`
 }
 
 type Link struct {
-	From string `yaml:"from"`
-	To   string `yaml:"to"`
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
 }
 
 func ReadChartConfig(filePath string) (ChartConfig, error) {
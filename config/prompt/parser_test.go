@@ -76,6 +76,21 @@ func TestReadTemplate(t *testing.T) {
 	assertEqual(t, result.Template, "Test Template", "template")
 }
 
+func TestReadTemplate_OutputSchema(t *testing.T) {
+	overrideReadFile(t, func(string) ([]byte, error) {
+		return []byte(`
+template: 'List concepts'
+output_schema:
+  type: object
+  required: ["concepts"]
+`), nil
+	})
+
+	result, err := ReadTemplate("dummy.yaml")
+	assertNoError(t, err)
+	assertEqual(t, result.OutputSchema["type"], any("object"), "output_schema.type")
+}
+
 func TestReadTopicConfig(t *testing.T) {
 	overrideReadFile(t, func(string) ([]byte, error) {
 		return []byte(testTopicYAML), nil
@@ -29,8 +29,10 @@ func cleanupPath(path string, mustExist bool) {
 }
 
 var _ = BeforeSuite(func() {
-	By("Loading environment variables from .env")
-	Expect(godotenv.Load("../.env")).To(Succeed(), "Failed to load .env file")
+	By("Loading environment variables from .env, if present")
+	if err := godotenv.Load("../.env"); err != nil {
+		GinkgoWriter.Printf("No .env loaded (%v); live specs need provider credentials in the environment\n", err)
+	}
 
 	By("Creating output directory")
 	createDir(filepath.Join("..", ".build", "output"))
@@ -0,0 +1,272 @@
+package explore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/scaffold"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Exploration --------------------
+
+// Files written in each topic's directory.
+const (
+	AnswerFile = "answer.md"
+	PromptFile = "prompt.txt"
+	ConfigFile = "config.yaml"
+)
+
+// A topic is done once its explanation is saved, and failed when generating or
+// saving it fails. Topics the explorer never got to, because the run was
+// cancelled or the budget ran out, are skipped and have no subtopics.
+const (
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Client is the model the explorer calls; *llm.Client implements it.
+type Client interface {
+	Generate(ctx context.Context, prompt string) (*llm.Response, error)
+	ChatJSON(ctx context.Context, prompt string, schema *llm.Schema, maxRepairs int) (string, error)
+}
+
+// Node is one topic of the knowledge tree.
+type Node struct {
+	Title    string
+	Dir      string // Relative to the tree's root dir; "" for the root topic
+	Depth    int
+	Config   promptConfig.TopicConfig
+	Status   string
+	Error    string // Why the topic failed or was skipped, or why its subtopics are missing
+	Parent   *Node
+	Children []*Node
+
+	answer string
+}
+
+// Walk calls f for n and its descendants, depth first.
+func (n *Node) Walk(f func(*Node)) {
+	f(n)
+	for _, c := range n.Children {
+		c.Walk(f)
+	}
+}
+
+// Explorer explains a topic, asks the model for its most important subtopics
+// and explores each of them in turn, down to Depth levels.
+type Explorer struct {
+	Client      Client
+	Target      llm.Target // Recorded in each answer's metadata
+	Template    string     // Topic prompt template
+	Depth       int        // Levels of subtopics below the root topic
+	Breadth     int        // Subtopics per topic
+	Concurrency int        // Topics generated at once (default 2)
+	MaxRepairs  int        // Re-prompts allowed when the subtopic list fails validation
+	OnNode      func(*Node)
+
+	mu      sync.Mutex
+	titles  map[string]string // Titles of every topic in the tree by slug, so none is explored twice
+	stopped error             // Set once the cost budget is spent
+}
+
+// Explore builds the knowledge tree of root in outDir: each topic's answer,
+// prompt and config are saved in its own directory, nested under its parent's.
+// Topics that fail are recorded in the tree; the others carry on. When the cost
+// budget is spent the remaining topics are skipped and the error wraps
+// llm.ErrBudgetExceeded. Write the index and links with WriteTree.
+func (e *Explorer) Explore(ctx context.Context, root promptConfig.TopicConfig, outDir string) (*Node, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output dir '%s': %w", outDir, err)
+	}
+	workers := e.Concurrency
+	if workers < 1 {
+		workers = 2
+	}
+	e.titles = map[string]string{paths.Slug(root.Topic): root.Topic}
+	e.stopped = nil
+
+	tree := &Node{Title: root.Topic, Config: root}
+	e.visit(ctx, tree, outDir, make(chan struct{}, workers))
+
+	if e.stopped != nil {
+		return tree, e.stopped
+	}
+	return tree, ctx.Err()
+}
+
+// visit explains n, plans its subtopics and explores them concurrently. sem
+// bounds the model calls in flight across the tree; it is not held while the
+// subtopics are explored.
+func (e *Explorer) visit(ctx context.Context, n *Node, outDir string, sem chan struct{}) {
+	subtopics := e.run(ctx, n, filepath.Join(outDir, n.Dir), sem)
+	e.report(n)
+	for _, s := range subtopics {
+		n.Children = append(n.Children, &Node{
+			Title:  s.Title,
+			Dir:    filepath.Join(n.Dir, paths.Slug(s.Title)),
+			Depth:  n.Depth + 1,
+			Config: ChildConfig(n.Config, s),
+			Parent: n,
+		})
+	}
+	var wg sync.WaitGroup
+	for _, c := range n.Children {
+		wg.Add(1)
+		go func(c *Node) {
+			defer wg.Done()
+			e.visit(ctx, c, outDir, sem)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// run explains n in dir once a slot in sem is free, setting its status, and
+// returns its subtopics.
+func (e *Explorer) run(ctx context.Context, n *Node, dir string, sem chan struct{}) []Subtopic {
+	if err := e.stopErr(ctx); err != nil {
+		n.Status, n.Error = StatusSkipped, err.Error()
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		n.Status, n.Error = StatusSkipped, ctx.Err().Error()
+		return nil
+	}
+	defer func() { <-sem }()
+	if err := e.stopErr(ctx); err != nil { // The budget may have run out while waiting
+		n.Status, n.Error = StatusSkipped, err.Error()
+		return nil
+	}
+
+	subtopics, err := e.explain(ctx, n, dir)
+	switch {
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		e.stop(err)
+		n.Status, n.Error = StatusSkipped, err.Error()
+	case err != nil:
+		n.Status, n.Error = StatusFailed, err.Error()
+	default:
+		n.Status = StatusDone
+	}
+	return subtopics
+}
+
+// explain generates and saves the answer of n in dir, then asks for its
+// subtopics unless n is at the deepest level. A failure to plan subtopics is
+// recorded on n rather than failing it.
+func (e *Explorer) explain(ctx context.Context, n *Node, dir string) ([]Subtopic, error) {
+	text, err := prompt.Render(e.Template, prompt.TopicContext(n.Config))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), scaffold.MarshalTopic(n.Config), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, PromptFile), []byte(text), 0644); err != nil {
+		return nil, err
+	}
+	resp, err := e.Client.Generate(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	n.answer = resp.Text
+	answerPath := filepath.Join(dir, AnswerFile)
+	if err := os.WriteFile(answerPath, []byte(resp.Text), 0644); err != nil {
+		return nil, err
+	}
+	if err := llm.SaveMetadata(answerPath, llm.NewResponseMetadata(e.Target, resp)); err != nil {
+		return nil, err
+	}
+
+	if n.Depth >= e.Depth || e.Breadth < 1 {
+		return nil, nil
+	}
+	subtopics, err := e.plan(ctx, n)
+	if err != nil {
+		e.stop(err)
+		n.Error = "subtopics: " + err.Error()
+	}
+	return subtopics, nil
+}
+
+// plan asks for the most important subtopics of n, leaving out topics already in the tree.
+func (e *Explorer) plan(ctx context.Context, n *Node) ([]Subtopic, error) {
+	e.mu.Lock()
+	known := make([]string, 0, len(e.titles))
+	for _, title := range e.titles {
+		known = append(known, title)
+	}
+	e.mu.Unlock()
+	sort.Strings(known)
+
+	schema, err := llm.SchemaFromMap(SubtopicSchema(e.Breadth))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := e.Client.ChatJSON(ctx, SubtopicPrompt(n.Config, n.answer, e.Breadth, known), schema, e.MaxRepairs)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Subtopics []Subtopic `json:"subtopics"`
+	}
+	if err := json.Unmarshal([]byte(doc), &list); err != nil {
+		return nil, fmt.Errorf("invalid subtopics: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var fresh []Subtopic
+	for _, s := range list.Subtopics {
+		key := paths.Slug(s.Title)
+		if _, ok := e.titles[key]; ok || key == "" || len(fresh) == e.Breadth {
+			continue
+		}
+		e.titles[key] = s.Title
+		fresh = append(fresh, s)
+	}
+	return fresh, nil
+}
+
+func (e *Explorer) report(n *Node) {
+	if e.OnNode == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.OnNode(n)
+}
+
+func (e *Explorer) stop(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped == nil && errors.Is(err, llm.ErrBudgetExceeded) {
+		e.stopped = err
+	}
+}
+
+// stopErr returns why no more topics should be generated, if any.
+func (e *Explorer) stopErr(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped != nil {
+		return e.stopped
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,217 @@
+package explore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+const testTemplate = "Explain {{ topic }} to {{ audience }} in a {{ tone }} tone."
+
+// treeClient explains each topic with a line naming it and proposes the
+// subtopics listed for it. It records the subtopic prompts it is sent.
+type treeClient struct {
+	subtopics map[string][]string
+	fail      string // Topic whose explanation fails
+	budget    int    // Explanations allowed before the budget is spent; 0 = no limit
+
+	mu       sync.Mutex
+	calls    int
+	inFlight int
+	peak     int
+	plans    map[string]string
+}
+
+func (c *treeClient) Generate(_ context.Context, prompt string) (*llm.Response, error) {
+	topic := strings.TrimPrefix(prompt[:strings.Index(prompt, " to ")], "Explain ")
+	c.mu.Lock()
+	c.calls++
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	over := c.budget > 0 && c.calls > c.budget
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	switch {
+	case over:
+		return nil, fmt.Errorf("%w: spent $1.0000 of $1.0000", llm.ErrBudgetExceeded)
+	case topic == c.fail:
+		return nil, errors.New("model unavailable")
+	}
+	return &llm.Response{Text: "All about " + topic + "."}, nil
+}
+
+func (c *treeClient) ChatJSON(_ context.Context, prompt string, _ *llm.Schema, _ int) (string, error) {
+	start := strings.Index(prompt, "explanation of ") + len("explanation of ")
+	topic := prompt[start : start+strings.Index(prompt[start:], " for ")]
+	c.mu.Lock()
+	if c.plans == nil {
+		c.plans = map[string]string{}
+	}
+	c.plans[topic] = prompt
+	c.mu.Unlock()
+
+	var list []Subtopic
+	for _, title := range c.subtopics[topic] {
+		list = append(list, Subtopic{
+			Title:                   title,
+			Purpose:                 "using " + title,
+			Concepts:                []string{"**" + title + "**: what it is"},
+			ExplanationRequirements: []string{"Show an example"},
+		})
+	}
+	doc, err := json.Marshal(map[string]any{"subtopics": list})
+	return string(doc), err
+}
+
+func gitConfig() promptConfig.TopicConfig {
+	return promptConfig.TopicConfig{
+		Topic:         "Git",
+		Audience:      "students",
+		LearningStage: "beginner",
+		Tone:          "friendly",
+		Formatting:    []string{"Use headings"},
+		Concepts:      []string{"**Commit**: a snapshot"},
+		Purpose:       "version control",
+	}
+}
+
+func gitSubtopics() map[string][]string {
+	return map[string][]string{
+		"Git":       {"Branching", "Staging Area", "Remotes"},
+		"Branching": {"Merging", "Rebasing", "Git"},
+		"Remotes":   {"Merging", "Pull Requests"},
+	}
+}
+
+func TestExplorer_Explore(t *testing.T) {
+	client := &treeClient{subtopics: gitSubtopics()}
+	dir := t.TempDir()
+	var reported []string
+	e := &Explorer{Client: client, Template: testTemplate, Depth: 2, Breadth: 2, Concurrency: 1, OnNode: func(n *Node) {
+		reported = append(reported, n.Title+" "+n.Status)
+	}}
+
+	tree, err := e.Explore(context.Background(), gitConfig(), dir)
+	require.NoError(t, err)
+
+	var titles []string
+	tree.Walk(func(n *Node) { titles = append(titles, fmt.Sprintf("%d %s %s", n.Depth, n.Title, n.Dir)) })
+	assert.Equal(t, []string{
+		"0 Git ",
+		"1 Branching branching",
+		"2 Merging branching/merging",
+		"2 Rebasing branching/rebasing",
+		"1 Staging Area staging-area",
+	}, titles, "breadth caps each level and known topics are not explored twice")
+	assert.Len(t, reported, 5)
+	assert.Equal(t, "Git done", reported[0], "a topic is reported before its subtopics")
+
+	assert.Equal(t, "All about Git.", readFile(t, filepath.Join(dir, AnswerFile)))
+	assert.Equal(t, "Explain Merging to students in a friendly tone.", readFile(t, filepath.Join(dir, "branching", "merging", PromptFile)))
+	assert.FileExists(t, filepath.Join(dir, "branching", "merging", "answer.meta.json"))
+
+	child, err := promptConfig.ReadTopicConfig(filepath.Join(dir, "branching", ConfigFile))
+	require.NoError(t, err)
+	assert.Equal(t, "Branching", child.Topic)
+	assert.Equal(t, "students", child.Audience)
+	assert.Equal(t, "beginner", child.LearningStage)
+	assert.Equal(t, []string{"Use headings"}, child.Formatting)
+	assert.Equal(t, []string{"**Branching**: what it is"}, child.Concepts)
+
+	assert.Len(t, client.plans, 3, "only topics above the deepest level are planned")
+	assert.Contains(t, client.plans["Git"], "Explanation:\n<<<\nAll about Git.\n>>>")
+	assert.Contains(t, client.plans["Branching"], "already covered; do not propose them again:\n- Branching\n- Git\n- Staging Area\n")
+}
+
+func TestExplorer_ExploreFailure(t *testing.T) {
+	client := &treeClient{subtopics: gitSubtopics(), fail: "Branching"}
+	e := &Explorer{Client: client, Template: testTemplate, Depth: 2, Breadth: 3}
+
+	tree, err := e.Explore(context.Background(), gitConfig(), t.TempDir())
+	require.NoError(t, err)
+
+	require.Len(t, tree.Children, 3)
+	branching := tree.Children[0]
+	assert.Equal(t, StatusFailed, branching.Status)
+	assert.Equal(t, "model unavailable", branching.Error)
+	assert.Empty(t, branching.Children)
+	assert.Equal(t, StatusDone, tree.Children[2].Status)
+	require.Len(t, tree.Children[2].Children, 2, "the other topics carry on")
+}
+
+func TestExplorer_ExploreBudget(t *testing.T) {
+	client := &treeClient{subtopics: gitSubtopics(), budget: 2}
+	e := &Explorer{Client: client, Template: testTemplate, Depth: 2, Breadth: 3, Concurrency: 1}
+
+	tree, err := e.Explore(context.Background(), gitConfig(), t.TempDir())
+	require.ErrorIs(t, err, llm.ErrBudgetExceeded)
+
+	statuses := map[string]int{}
+	tree.Walk(func(n *Node) { statuses[n.Status]++ })
+	assert.Equal(t, 2, statuses[StatusDone])
+	assert.Zero(t, statuses[StatusFailed])
+	assert.Equal(t, 3, client.calls, "no topic is generated once the budget is spent")
+}
+
+func TestExplorer_ExploreConcurrency(t *testing.T) {
+	client := &treeClient{subtopics: gitSubtopics()}
+	e := &Explorer{Client: client, Template: testTemplate, Depth: 2, Breadth: 3, Concurrency: 2}
+
+	tree, err := e.Explore(context.Background(), gitConfig(), t.TempDir())
+	require.NoError(t, err)
+
+	count := 0
+	tree.Walk(func(*Node) { count++ })
+	assert.Equal(t, 7, count)
+	assert.LessOrEqual(t, client.peak, 2)
+}
+
+func TestExplorer_ExploreCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e := &Explorer{Client: &treeClient{}, Template: testTemplate, Depth: 1, Breadth: 3}
+
+	tree, err := e.Explore(ctx, gitConfig(), t.TempDir())
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StatusSkipped, tree.Status)
+}
+
+func TestExplorer_NonASCIISubtopics(t *testing.T) {
+	client := &treeClient{subtopics: map[string][]string{"Git": {"分支", "合并 / 变基", "分支", "Über Git", "ber git"}}}
+	dir := t.TempDir()
+	e := &Explorer{Client: client, Template: testTemplate, Depth: 1, Breadth: 5, Concurrency: 1}
+
+	tree, err := e.Explore(context.Background(), gitConfig(), dir)
+	require.NoError(t, err)
+
+	var dirs []string
+	for _, c := range tree.Children {
+		dirs = append(dirs, c.Dir)
+	}
+	assert.Equal(t, []string{"分支", "合并-变基", "über-git", "ber-git"}, dirs, "non-ASCII letters are kept, and each title explored once")
+	assert.FileExists(t, filepath.Join(dir, "合并-变基", AnswerFile))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,78 @@
+package explore
+
+import (
+	"fmt"
+	"strings"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Subtopics --------------------
+
+// Subtopic is one of the subtopics the model proposes for a topic.
+type Subtopic struct {
+	Title                   string   `json:"title"`
+	Purpose                 string   `json:"purpose"`
+	Concepts                []string `json:"concepts"`
+	ExplanationRequirements []string `json:"explanation_requirements"`
+}
+
+// SubtopicSchema is the JSON Schema of the list of at most breadth subtopics.
+func SubtopicSchema(breadth int) map[string]any {
+	text := map[string]any{"type": "string", "minLength": 1}
+	subtopic := map[string]any{
+		"type":     "object",
+		"required": []any{"title", "purpose", "concepts", "explanation_requirements"},
+		"properties": map[string]any{
+			"title":   map[string]any{"type": "string", "minLength": 1, "maxLength": 60},
+			"purpose": text,
+			"concepts": map[string]any{
+				"type": "array", "minItems": 2,
+				"items": map[string]any{"type": "string", "pattern": `^\*\*[^*]+\*\*: \S`},
+			},
+			"explanation_requirements": map[string]any{"type": "array", "minItems": 1, "items": text},
+		},
+	}
+	return map[string]any{
+		"type":     "object",
+		"required": []any{"subtopics"},
+		"properties": map[string]any{
+			"subtopics": map[string]any{"type": "array", "minItems": 1, "maxItems": breadth, "items": subtopic},
+		},
+	}
+}
+
+// SubtopicPrompt asks for the most important subtopics of the topic of cfg,
+// given its explanation, leaving out the known topics.
+func SubtopicPrompt(cfg promptConfig.TopicConfig, answer string, breadth int, known []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You plan tutorials. Below is an explanation of %s for %s (%s).\n"+
+		"Pick the %d most important subtopics a learner should study next to go deeper, "+
+		"most important first.\n\n", cfg.Topic, cfg.Audience, cfg.LearningStage, breadth)
+	fmt.Fprintf(&b, "Explanation:\n<<<\n%s\n>>>\n\n", strings.TrimSpace(answer))
+	if len(known) > 0 {
+		b.WriteString("These topics are already covered; do not propose them again:\n")
+		for _, title := range known {
+			fmt.Fprintf(&b, "- %s\n", title)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("For each subtopic give:\n" +
+		"- title: the subtopic's name, in a few words\n" +
+		"- purpose: what it is used for, in a few words\n" +
+		"- concepts: 2 to 5 key concepts, each written as \"**Term**: what to explain about it\"\n" +
+		"- explanation_requirements: 2 to 4 things its explanation must do\n")
+	return b.String()
+}
+
+// ChildConfig derives the config of subtopic s from its parent's: the
+// audience, stage, tone and presentation carry over, while the topic, purpose,
+// concepts and requirements are the subtopic's own.
+func ChildConfig(parent promptConfig.TopicConfig, s Subtopic) promptConfig.TopicConfig {
+	cfg := parent
+	cfg.Topic = s.Title
+	cfg.Purpose = s.Purpose
+	cfg.Concepts = s.Concepts
+	cfg.ExplanationRequirements = s.ExplanationRequirements
+	return cfg
+}
@@ -0,0 +1,56 @@
+package explore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+)
+
+func TestSubtopicSchema(t *testing.T) {
+	schema, err := llm.SchemaFromMap(SubtopicSchema(2))
+	require.NoError(t, err)
+
+	item := `{"title":"Branching","purpose":"parallel work",` +
+		`"concepts":["**Branch**: a movable pointer","**HEAD**: the current branch"],` +
+		`"explanation_requirements":["Show a diagram"]}`
+	assert.Empty(t, schema.Validate([]byte(`{"subtopics":[`+item+`]}`)))
+
+	badConcept := strings.Replace(item, "**Branch**: a movable pointer", "Branch", 1)
+	assert.NotEmpty(t, schema.Validate([]byte(`{"subtopics":[`+badConcept+`]}`)))
+
+	tooMany := `{"subtopics":[` + strings.Join([]string{item, item, item}, ",") + `]}`
+	assert.NotEmpty(t, schema.Validate([]byte(tooMany)))
+}
+
+func TestSubtopicPrompt(t *testing.T) {
+	text := SubtopicPrompt(gitConfig(), "All about Git.\n", 3, []string{"Git", "Branching"})
+
+	assert.Contains(t, text, "explanation of Git for students (beginner)")
+	assert.Contains(t, text, "Pick the 3 most important subtopics")
+	assert.Contains(t, text, "Explanation:\n<<<\nAll about Git.\n>>>")
+	assert.Contains(t, text, "do not propose them again:\n- Git\n- Branching\n")
+	assert.NotContains(t, SubtopicPrompt(gitConfig(), "", 3, nil), "already covered")
+}
+
+func TestChildConfig(t *testing.T) {
+	parent := gitConfig()
+	child := ChildConfig(parent, Subtopic{
+		Title:                   "Branching",
+		Purpose:                 "parallel work",
+		Concepts:                []string{"**Branch**: a movable pointer"},
+		ExplanationRequirements: []string{"Show a diagram"},
+	})
+
+	assert.Equal(t, "Branching", child.Topic)
+	assert.Equal(t, "parallel work", child.Purpose)
+	assert.Equal(t, []string{"**Branch**: a movable pointer"}, child.Concepts)
+	assert.Equal(t, []string{"Show a diagram"}, child.ExplanationRequirements)
+	assert.Equal(t, parent.Audience, child.Audience)
+	assert.Equal(t, parent.LearningStage, child.LearningStage)
+	assert.Equal(t, parent.Tone, child.Tone)
+	assert.Equal(t, parent.Formatting, child.Formatting)
+	assert.Equal(t, "Git", parent.Topic)
+}
@@ -0,0 +1,114 @@
+package explore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// -------------------- Index and Links --------------------
+
+// IndexFile is the table of contents written at the root of the tree.
+const IndexFile = "index.md"
+
+var statusIcons = map[string]string{
+	StatusDone:    "✅",
+	StatusFailed:  "❌",
+	StatusSkipped: "⏭️",
+}
+
+// WriteTree writes the index of tree in outDir and links each saved answer to
+// its parent, subtopics and sibling topics.
+func WriteTree(tree *Node, outDir string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", tree.Title)
+	tree.Walk(func(n *Node) {
+		indent := strings.Repeat("  ", n.Depth)
+		if n.Status == StatusDone {
+			fmt.Fprintf(&b, "%s- [%s](%s)", indent, n.Title, link("", filepath.Join(n.Dir, AnswerFile)))
+		} else {
+			fmt.Fprintf(&b, "%s- %s %s", indent, statusIcons[n.Status], n.Title)
+		}
+		if n.Error != "" {
+			fmt.Fprintf(&b, " (%s)", oneLine(n.Error))
+		}
+		b.WriteString("\n")
+	})
+	if err := os.WriteFile(filepath.Join(outDir, IndexFile), []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing index: %w", err)
+	}
+
+	var err error
+	tree.Walk(func(n *Node) {
+		if err != nil || n.Status != StatusDone {
+			return
+		}
+		nav := Nav(n)
+		if nav == "" {
+			return
+		}
+		path := filepath.Join(outDir, n.Dir, AnswerFile)
+		f, openErr := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if openErr != nil {
+			err = openErr
+			return
+		}
+		_, err = f.WriteString(nav)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// Nav returns the footer linking n to its parent, its saved subtopics and its
+// saved siblings, or "" when there is nothing to link to.
+func Nav(n *Node) string {
+	var lines []string
+	if n.Parent != nil {
+		lines = append(lines, fmt.Sprintf("**Up:** [%s](%s)", n.Parent.Title, link(n.Dir, filepath.Join(n.Parent.Dir, AnswerFile))))
+	}
+	if links := links(n, n.Children); links != "" {
+		lines = append(lines, "**Subtopics:** "+links)
+	}
+	if n.Parent != nil {
+		var siblings []*Node
+		for _, s := range n.Parent.Children {
+			if s != n {
+				siblings = append(siblings, s)
+			}
+		}
+		if links := links(n, siblings); links != "" {
+			lines = append(lines, "**Related:** "+links)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\n---\n\n" + strings.Join(lines, "  \n") + "\n"
+}
+
+// links lists the saved topics of nodes as links relative to the directory of n.
+func links(n *Node, nodes []*Node) string {
+	var items []string
+	for _, c := range nodes {
+		if c.Status == StatusDone {
+			items = append(items, fmt.Sprintf("[%s](%s)", c.Title, link(n.Dir, filepath.Join(c.Dir, AnswerFile))))
+		}
+	}
+	return strings.Join(items, " · ")
+}
+
+// link returns the path of target relative to dir, both relative to the tree's root.
+func link(dir, target string) string {
+	rel, err := filepath.Rel(filepath.Join(".", dir), target)
+	if err != nil {
+		rel = target
+	}
+	return filepath.ToSlash(rel)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,58 @@
+package explore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleTree is Git with two subtopics, the first with one of its own, and a
+// failed third subtopic.
+func sampleTree() *Node {
+	root := &Node{Title: "Git", Status: StatusDone}
+	branching := &Node{Title: "Branching", Dir: "branching", Depth: 1, Status: StatusDone, Parent: root}
+	merging := &Node{Title: "Merging", Dir: filepath.Join("branching", "merging"), Depth: 2, Status: StatusDone, Parent: branching}
+	staging := &Node{Title: "Staging Area", Dir: "staging-area", Depth: 1, Status: StatusDone, Parent: root}
+	remotes := &Node{Title: "Remotes", Dir: "remotes", Depth: 1, Status: StatusFailed, Error: "model\nunavailable", Parent: root}
+	branching.Children = []*Node{merging}
+	root.Children = []*Node{branching, staging, remotes}
+	return root
+}
+
+func TestWriteTree(t *testing.T) {
+	dir := t.TempDir()
+	tree := sampleTree()
+	tree.Walk(func(n *Node) {
+		if n.Status == StatusDone {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, n.Dir), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, n.Dir, AnswerFile), []byte("All about "+n.Title+"."), 0644))
+		}
+	})
+
+	require.NoError(t, WriteTree(tree, dir))
+
+	assert.Equal(t, "# Git\n\n"+
+		"- [Git](answer.md)\n"+
+		"  - [Branching](branching/answer.md)\n"+
+		"    - [Merging](branching/merging/answer.md)\n"+
+		"  - [Staging Area](staging-area/answer.md)\n"+
+		"  - ❌ Remotes (model unavailable)\n",
+		readFile(t, filepath.Join(dir, IndexFile)))
+
+	assert.Equal(t, "All about Branching.\n\n---\n\n"+
+		"**Up:** [Git](../answer.md)  \n"+
+		"**Subtopics:** [Merging](merging/answer.md)  \n"+
+		"**Related:** [Staging Area](../staging-area/answer.md)\n",
+		readFile(t, filepath.Join(dir, "branching", AnswerFile)))
+	assert.Equal(t, "All about Merging.\n\n---\n\n**Up:** [Branching](../answer.md)\n",
+		readFile(t, filepath.Join(dir, "branching", "merging", AnswerFile)))
+}
+
+func TestNav(t *testing.T) {
+	tree := sampleTree()
+	assert.Equal(t, "\n\n---\n\n**Subtopics:** [Branching](branching/answer.md) · [Staging Area](staging-area/answer.md)\n", Nav(tree))
+	assert.Empty(t, Nav(&Node{Title: "Git", Status: StatusDone}))
+}
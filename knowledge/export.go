@@ -0,0 +1,148 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -------------------- Export --------------------
+
+// JSON returns the graph as {"nodes": [...], "edges": [...]}.
+func (g *Graph) JSON() ([]byte, error) {
+	doc := struct {
+		Nodes []*Node `json:"nodes"`
+		Edges []Edge  `json:"edges"`
+	}{Nodes: g.Nodes, Edges: g.Edges}
+	if doc.Nodes == nil {
+		doc.Nodes = []*Node{}
+	}
+	if doc.Edges == nil {
+		doc.Edges = []Edge{}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type graphML struct {
+	XMLName xml.Name   `xml:"graphml"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	Keys    []graphKey `xml:"key"`
+	Graph   struct {
+		ID          string      `xml:"id,attr"`
+		EdgeDefault string      `xml:"edgedefault,attr"`
+		Nodes       []graphNode `xml:"node"`
+		Edges       []graphEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphNode struct {
+	ID   string      `xml:"id,attr"`
+	Data []graphData `xml:"data"`
+}
+
+type graphEdge struct {
+	ID       string      `xml:"id,attr"`
+	Source   string      `xml:"source,attr"`
+	Target   string      `xml:"target,attr"`
+	Directed bool        `xml:"directed,attr"`
+	Data     []graphData `xml:"data"`
+}
+
+// GraphML returns the graph as GraphML, with the kind, label and files of
+// each node and the kind and weight of each edge as attributes, as Gephi and
+// yEd read them.
+func (g *Graph) GraphML() ([]byte, error) {
+	doc := graphML{XMLNS: "http://graphml.graphdrawing.org/xmlns", Keys: []graphKey{
+		{ID: "kind", For: "node", Name: "kind", Type: "string"},
+		{ID: "label", For: "node", Name: "label", Type: "string"},
+		{ID: "path", For: "node", Name: "path", Type: "string"},
+		{ID: "answer", For: "node", Name: "answer", Type: "string"},
+		{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+		{ID: "weight", For: "edge", Name: "weight", Type: "double"},
+	}}
+	doc.Graph.ID = "G"
+	doc.Graph.EdgeDefault = "directed"
+	ids := g.exportIDs()
+	for _, n := range g.Nodes {
+		data := []graphData{{"kind", n.Kind}, {"label", n.Label}}
+		if n.Path != "" {
+			data = append(data, graphData{"path", n.Path})
+		}
+		if n.Answer != "" {
+			data = append(data, graphData{"answer", n.Answer})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphNode{ID: ids[n.ID], Data: data})
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphEdge{
+			ID: fmt.Sprintf("e%d", i), Source: ids[e.Source], Target: ids[e.Target], Directed: e.Directed(),
+			Data: []graphData{{"edge_kind", e.Kind}, {"weight", strconv.FormatFloat(e.Weight, 'f', -1, 64)}},
+		})
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
+
+// Mermaid returns the graph as a Mermaid flowchart: topics are boxes and
+// concepts rounded, subtopics hang off their topic with arrows, concepts with
+// dotted lines, and overlap and similar edges are labelled with their weight.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := g.exportIDs()
+	for _, n := range g.Nodes {
+		label := strings.ReplaceAll(n.Label, `"`, "'")
+		if n.Kind == KindConcept {
+			fmt.Fprintf(&b, "  %s([\"%s\"]):::concept\n", ids[n.ID], label)
+		} else {
+			fmt.Fprintf(&b, "  %s[\"%s\"]:::topic\n", ids[n.ID], label)
+		}
+	}
+	for _, e := range g.Edges {
+		source, target := ids[e.Source], ids[e.Target]
+		switch e.Kind {
+		case EdgeSubtopic:
+			fmt.Fprintf(&b, "  %s --> %s\n", source, target)
+		case EdgeConcept:
+			fmt.Fprintf(&b, "  %s -.- %s\n", source, target)
+		case EdgeOverlap:
+			fmt.Fprintf(&b, "  %s ===|%g shared| %s\n", source, e.Weight, target)
+		case EdgeSimilar:
+			fmt.Fprintf(&b, "  %s -.-|%.2f| %s\n", source, e.Weight, target)
+		}
+	}
+	b.WriteString("  classDef topic fill:#E3F2FD,stroke:#1565C0,stroke-width:2px,color:#333\n")
+	b.WriteString("  classDef concept fill:#FFFBE6,stroke:#607D8B,color:#333\n")
+	return b.String()
+}
+
+// exportIDs maps node ids to the short ids n1, n2, ... that GraphML and
+// Mermaid use, since node ids hold any character of the label.
+func (g *Graph) exportIDs() map[string]string {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i+1)
+	}
+	return ids
+}
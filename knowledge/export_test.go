@@ -0,0 +1,86 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// sampleGraph is Git with its subtopic Branching, sharing the Branches concept.
+func sampleGraph() *Graph {
+	g := New()
+	git := g.AddTopic(gitTopic(), "configs/git.yaml")
+	branching := g.AddTopic(branchingTopic(), "")
+	g.SetAnswer(git, "output/git/answer.md", "# Git")
+	g.AddSubtopic(git, branching)
+	g.LinkOverlaps(1)
+	g.link(git.ID, branching.ID, EdgeSimilar, 0.8734)
+	return g
+}
+
+func TestGraph_JSON(t *testing.T) {
+	data, err := sampleGraph().JSON()
+	require.NoError(t, err)
+
+	var doc struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Nodes, 5)
+	assert.Equal(t, Node{ID: "topic:git", Kind: KindTopic, Label: "Git", Path: "configs/git.yaml", Answer: "output/git/answer.md"}, doc.Nodes[0])
+	assert.Equal(t, Node{ID: "concept:head", Kind: KindConcept, Label: "HEAD"}, doc.Nodes[4])
+	assert.Contains(t, doc.Edges, Edge{Source: "topic:git", Target: "topic:branching", Kind: EdgeOverlap, Weight: 1})
+	assert.NotContains(t, string(data), "Snapshots", "embedded text is not exported")
+
+	empty, err := New().JSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"nodes": [], "edges": []}`, string(empty))
+}
+
+func TestGraph_GraphML(t *testing.T) {
+	data, err := sampleGraph().GraphML()
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, out, `<key id="weight" for="edge" attr.name="weight" attr.type="double"></key>`)
+	assert.Contains(t, out, "<node id=\"n1\">\n      <data key=\"kind\">topic</data>\n      <data key=\"label\">Git</data>\n"+
+		"      <data key=\"path\">configs/git.yaml</data>\n      <data key=\"answer\">output/git/answer.md</data>\n    </node>")
+	assert.Contains(t, out, `<edge id="e4" source="n1" target="n4" directed="true">`)
+	assert.Contains(t, out, `<edge id="e6" source="n1" target="n4" directed="false">`)
+	assert.Contains(t, out, `<data key="weight">0.8734</data>`)
+
+	var doc graphML
+	require.NoError(t, xml.Unmarshal(data, &doc), "the export is well-formed")
+	assert.Len(t, doc.Graph.Nodes, 5)
+	assert.Len(t, doc.Graph.Edges, 7)
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	g := sampleGraph()
+	g.AddTopic(promptConfig.TopicConfig{Topic: `The "git add" command`}, "")
+
+	assert.Equal(t, `graph LR
+  n1["Git"]:::topic
+  n2(["Commits"]):::concept
+  n3(["Branches"]):::concept
+  n4["Branching"]:::topic
+  n5(["HEAD"]):::concept
+  n6["The 'git add' command"]:::topic
+  n1 -.- n2
+  n1 -.- n3
+  n4 -.- n3
+  n4 -.- n5
+  n1 --> n4
+  n1 ===|1 shared| n4
+  n1 -.-|0.87| n4
+  classDef topic fill:#E3F2FD,stroke:#1565C0,stroke-width:2px,color:#333
+  classDef concept fill:#FFFBE6,stroke:#607D8B,color:#333
+`, g.Mermaid())
+}
@@ -0,0 +1,215 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Graph --------------------
+
+// Node kinds.
+const (
+	KindTopic   = "topic"
+	KindConcept = "concept"
+)
+
+// Edge kinds.
+const (
+	EdgeSubtopic = "subtopic" // Topic → subtopic, from an explored tree
+	EdgeConcept  = "concept"  // Topic → one of its concepts
+	EdgeOverlap  = "overlap"  // Topics sharing concepts; the weight counts them
+	EdgeSimilar  = "similar"  // Nodes of one kind whose embeddings are close; the weight is the cosine similarity
+)
+
+// Node is a topic or a concept.
+type Node struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Label  string `json:"label"`
+	Path   string `json:"path,omitempty"`   // Config the topic was read from
+	Answer string `json:"answer,omitempty"` // Answer saved for the topic
+
+	text string // What is embedded: the answer or config of a topic, the definition of a concept
+}
+
+// Edge links two nodes. Subtopic and concept edges point from the topic;
+// overlap and similar edges are undirected.
+type Edge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Kind   string  `json:"kind"`
+	Weight float64 `json:"weight"`
+}
+
+// Directed reports whether the edge has a direction.
+func (e Edge) Directed() bool {
+	return e.Kind == EdgeSubtopic || e.Kind == EdgeConcept
+}
+
+// Graph holds topics, their concepts and how they relate. Topics and concepts
+// are identified by their name, ignoring case and spacing, so the same concept
+// in several configs is one node.
+type Graph struct {
+	Nodes []*Node
+	Edges []Edge
+
+	index map[string]*Node
+	edges map[[3]string]bool
+}
+
+// New returns an empty graph.
+func New() *Graph {
+	return &Graph{index: map[string]*Node{}, edges: map[[3]string]bool{}}
+}
+
+// Node returns the node with id, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.index[id]
+}
+
+// Count returns the number of nodes of kind.
+func (g *Graph) Count(kind string) int {
+	return len(g.nodes(kind))
+}
+
+// AddTopic adds the topic of cfg, read from path, with its concepts. A topic
+// already in the graph keeps its path and gains the concepts it lacked.
+func (g *Graph) AddTopic(cfg promptConfig.TopicConfig, path string) *Node {
+	topic := g.add(KindTopic, cfg.Topic)
+	if topic.Path == "" {
+		topic.Path = path
+		topic.text = topicText(cfg)
+	}
+	for _, c := range cfg.Concepts {
+		term, definition := SplitConcept(c)
+		concept := g.add(KindConcept, term)
+		if concept.text == "" {
+			concept.text = strings.TrimSuffix(term+": "+definition, ": ")
+		}
+		g.link(topic.ID, concept.ID, EdgeConcept, 1)
+	}
+	return topic
+}
+
+// SetAnswer records the answer saved for topic, which is then embedded instead of its config.
+func (g *Graph) SetAnswer(topic *Node, path, text string) {
+	if topic.Answer != "" {
+		return
+	}
+	topic.Answer = path
+	if strings.TrimSpace(text) != "" {
+		topic.text = text
+	}
+}
+
+// AddSubtopic links parent to its subtopic child.
+func (g *Graph) AddSubtopic(parent, child *Node) {
+	g.link(parent.ID, child.ID, EdgeSubtopic, 1)
+}
+
+// LinkOverlaps links the topics that share at least min concepts.
+func (g *Graph) LinkOverlaps(min int) {
+	concepts := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.Kind == EdgeConcept {
+			concepts[e.Source] = append(concepts[e.Source], e.Target)
+		}
+	}
+	topics := g.nodes(KindTopic)
+	for i, a := range topics {
+		for _, b := range topics[i+1:] {
+			shared := 0
+			for _, c := range concepts[a.ID] {
+				if g.edges[[3]string{b.ID, c, EdgeConcept}] {
+					shared++
+				}
+			}
+			if shared > 0 && shared >= min {
+				g.link(a.ID, b.ID, EdgeOverlap, float64(shared))
+			}
+		}
+	}
+}
+
+// LinkSimilar embeds every node and links the nodes of the same kind whose
+// cosine similarity is at least threshold.
+func (g *Graph) LinkSimilar(ctx context.Context, service *llm.SimilarityService, threshold float64) error {
+	if len(g.Nodes) < 2 {
+		return nil
+	}
+	texts := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		texts[i] = n.text
+		if texts[i] == "" {
+			texts[i] = n.Label
+		}
+	}
+	matrix, err := service.Pairwise(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("error embedding nodes: %w", err)
+	}
+	for i, a := range g.Nodes {
+		for j := i + 1; j < len(g.Nodes); j++ {
+			if b := g.Nodes[j]; a.Kind == b.Kind && matrix[i][j] >= threshold {
+				g.link(a.ID, b.ID, EdgeSimilar, matrix[i][j])
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Graph) add(kind, label string) *Node {
+	id := kind + ":" + normalizeLabel(label)
+	if n, ok := g.index[id]; ok {
+		return n
+	}
+	n := &Node{ID: id, Kind: kind, Label: label}
+	g.Nodes = append(g.Nodes, n)
+	g.index[id] = n
+	return n
+}
+
+// normalizeLabel lowercases label and collapses its whitespace. Punctuation is
+// kept, so "C", "C++" and "C#" stay apart.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// link adds an edge unless it is a loop or already in the graph.
+func (g *Graph) link(source, target, kind string, weight float64) {
+	key := [3]string{source, target, kind}
+	if source == target || g.edges[key] {
+		return
+	}
+	g.edges[key] = true
+	g.Edges = append(g.Edges, Edge{Source: source, Target: target, Kind: kind, Weight: weight})
+}
+
+func (g *Graph) nodes(kind string) []*Node {
+	var nodes []*Node
+	for _, n := range g.Nodes {
+		if n.Kind == kind {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// SplitConcept splits a concept written "**Term**: definition" into its term
+// and definition. A concept without a definition is all term.
+func SplitConcept(concept string) (term, definition string) {
+	term, definition, _ = strings.Cut(concept, ":")
+	return strings.Trim(strings.TrimSpace(term), "*_`"), strings.TrimSpace(definition)
+}
+
+// topicText describes a topic that has no saved answer.
+func topicText(cfg promptConfig.TopicConfig) string {
+	parts := []string{cfg.Topic, cfg.Purpose}
+	parts = append(parts, cfg.Concepts...)
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,153 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// wordEmbedder maps text mentioning "branch" and text mentioning "commit" to
+// orthogonal vectors, and anything else halfway between them.
+type wordEmbedder struct{ err error }
+
+func (e wordEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		switch in = strings.ToLower(in); {
+		case strings.Contains(in, "branch"):
+			out[i] = []float32{1, 0}
+		case strings.Contains(in, "commit"):
+			out[i] = []float32{0, 1}
+		default:
+			out[i] = []float32{1, 1}
+		}
+	}
+	return out, nil
+}
+
+func gitTopic() promptConfig.TopicConfig {
+	return promptConfig.TopicConfig{Topic: "Git", Purpose: "version control", Concepts: []string{
+		"**Commits**: Snapshots of the project",
+		"**Branches**: Parallel lines of work",
+	}}
+}
+
+func branchingTopic() promptConfig.TopicConfig {
+	return promptConfig.TopicConfig{Topic: "Branching", Purpose: "parallel work", Concepts: []string{
+		"**Branches**: Pointers to commits",
+		"**HEAD**: The branch you are on",
+	}}
+}
+
+func edgeKeys(g *Graph, kind string) []string {
+	var keys []string
+	for _, e := range g.Edges {
+		if e.Kind == kind {
+			keys = append(keys, e.Source+" "+e.Target)
+		}
+	}
+	return keys
+}
+
+func TestGraph_AddTopic(t *testing.T) {
+	g := New()
+	git := g.AddTopic(gitTopic(), "configs/git.yaml")
+	g.AddTopic(branchingTopic(), "explore/git/branching/config.yaml")
+	again := g.AddTopic(promptConfig.TopicConfig{Topic: "git", Concepts: []string{"**Staging Area**: The next commit"}}, "explore/git/config.yaml")
+
+	assert.Same(t, git, again, "topics are identified by name")
+	assert.Equal(t, "configs/git.yaml", git.Path)
+	assert.Equal(t, 2, g.Count(KindTopic))
+	assert.Equal(t, 4, g.Count(KindConcept), "Branches is one concept")
+	assert.Equal(t, []string{
+		"topic:git concept:commits",
+		"topic:git concept:branches",
+		"topic:branching concept:branches",
+		"topic:branching concept:head",
+		"topic:git concept:staging area",
+	}, edgeKeys(g, EdgeConcept))
+	assert.Equal(t, "Commits: Snapshots of the project", g.Node("concept:commits").text)
+	assert.Nil(t, g.Node("concept:missing"))
+}
+
+func TestGraph_AddTopicNormalizesNames(t *testing.T) {
+	g := New()
+	for _, name := range []string{"C", "C++", "C#", "Über", "ber"} {
+		g.AddTopic(promptConfig.TopicConfig{Topic: name}, "")
+	}
+	staging := g.AddTopic(promptConfig.TopicConfig{Topic: "Staging area"}, "")
+
+	assert.Same(t, staging, g.AddTopic(promptConfig.TopicConfig{Topic: " staging  Area "}, ""), "case and spacing are ignored")
+	assert.Equal(t, 6, g.Count(KindTopic), "names differing in punctuation or letters stay apart")
+	assert.NotNil(t, g.Node("topic:c++"))
+	assert.NotNil(t, g.Node("topic:über"))
+}
+
+func TestGraph_AddSubtopic(t *testing.T) {
+	g := New()
+	git := g.AddTopic(gitTopic(), "")
+	branching := g.AddTopic(branchingTopic(), "")
+	g.AddSubtopic(git, branching)
+	g.AddSubtopic(git, branching)
+	g.AddSubtopic(git, git)
+
+	assert.Equal(t, []string{"topic:git topic:branching"}, edgeKeys(g, EdgeSubtopic))
+}
+
+func TestGraph_LinkOverlaps(t *testing.T) {
+	g := New()
+	g.AddTopic(gitTopic(), "")
+	g.AddTopic(branchingTopic(), "")
+	g.AddTopic(promptConfig.TopicConfig{Topic: "Docker", Concepts: []string{"**Images**: Packaged apps"}}, "")
+
+	g.LinkOverlaps(2)
+	assert.Empty(t, edgeKeys(g, EdgeOverlap))
+
+	g.LinkOverlaps(1)
+	assert.Equal(t, []string{"topic:git topic:branching"}, edgeKeys(g, EdgeOverlap))
+	assert.Equal(t, 1.0, g.Edges[len(g.Edges)-1].Weight)
+}
+
+func TestGraph_LinkSimilar(t *testing.T) {
+	g := New()
+	g.AddTopic(gitTopic(), "")
+	branching := g.AddTopic(branchingTopic(), "")
+	g.AddTopic(promptConfig.TopicConfig{Topic: "Branch naming", Purpose: "tidy repositories"}, "")
+	g.SetAnswer(branching, "branching/answer.md", "All about commits.")
+
+	require.NoError(t, g.LinkSimilar(context.Background(), llm.NewSimilarityService(wordEmbedder{}), 0.99))
+
+	assert.Equal(t, []string{
+		"topic:git topic:branch naming",
+		"concept:branches concept:head",
+	}, edgeKeys(g, EdgeSimilar), "only nodes of the same kind are linked, and answers are embedded over configs")
+}
+
+func TestGraph_LinkSimilarError(t *testing.T) {
+	g := New()
+	g.AddTopic(gitTopic(), "")
+
+	err := g.LinkSimilar(context.Background(), llm.NewSimilarityService(wordEmbedder{err: errors.New("no API key")}), 0.8)
+	assert.ErrorContains(t, err, "no API key")
+	assert.Empty(t, edgeKeys(g, EdgeSimilar))
+}
+
+func TestSplitConcept(t *testing.T) {
+	term, definition := SplitConcept("**Staging Area**: The selection process before committing")
+	assert.Equal(t, "Staging Area", term)
+	assert.Equal(t, "The selection process before committing", definition)
+
+	term, definition = SplitConcept("Rebasing")
+	assert.Equal(t, "Rebasing", term)
+	assert.Empty(t, definition)
+}
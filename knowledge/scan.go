@@ -0,0 +1,127 @@
+package knowledge
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"raja.aiml/ai.explorer/prompt"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Scanning --------------------
+
+// Files the scanner looks for in output directories: those of `chat`
+// (resources/output/<config name>/answer.md) and of `explore`, which also
+// saves each topic's config.yaml, nested under its parent topic's directory.
+const (
+	AnswerFile = "answer.md"
+	ConfigFile = "config.yaml"
+)
+
+// Scan builds the graph of the topic configs in configDir and the topics
+// saved under outputDirs. Configs that are not topic configs are skipped.
+func Scan(configDir string, outputDirs ...string) (*Graph, error) {
+	g := New()
+	byName, err := scanConfigs(g, configDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range outputDirs {
+		if err := scanOutput(g, dir, byName); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// scanConfigs adds the topic configs of dir and returns their topics by config name.
+func scanConfigs(g *Graph, dir string) (map[string]*Node, error) {
+	byName := map[string]*Node{}
+	if dir == "" {
+		return byName, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("error reading config dir: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		cfg, ok, err := readTopic(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			byName[strings.TrimSuffix(filepath.Base(path), ".yaml")] = g.AddTopic(cfg, path)
+		}
+	}
+	return byName, nil
+}
+
+// scanOutput adds the topics saved under root. A directory with a config.yaml
+// is a topic, the subtopic of the nearest such directory above it; a directory
+// with only an answer.md holds the answer of the config it is named after.
+func scanOutput(g *Graph, root string, byName map[string]*Node) error {
+	nearest := map[string]*Node{} // Topic at or above each directory
+	return filepath.WalkDir(filepath.Clean(root), func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error scanning '%s': %w", dir, err)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		parent := nearest[filepath.Dir(dir)]
+		nearest[dir] = parent
+		var topic *Node
+		cfg, ok, err := readTopic(filepath.Join(dir, ConfigFile))
+		switch {
+		case err != nil && !errors.Is(err, fs.ErrNotExist):
+			return err
+		case ok:
+			topic = g.AddTopic(cfg, filepath.Join(dir, ConfigFile))
+			if parent != nil {
+				g.AddSubtopic(parent, topic)
+			}
+			nearest[dir] = topic
+		default:
+			topic = byName[d.Name()]
+		}
+		if topic == nil {
+			return nil
+		}
+
+		answer := filepath.Join(dir, AnswerFile)
+		data, err := os.ReadFile(answer)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading answer: %w", err)
+		}
+		g.SetAnswer(topic, answer, string(data))
+		return nil
+	})
+}
+
+// readTopic reads the topic config at path; ok is false when it is another kind of config.
+func readTopic(path string) (cfg promptConfig.TopicConfig, ok bool, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return cfg, false, err
+	}
+	kind, err := prompt.DetectConfigType(path)
+	if err != nil || kind != "topic" {
+		return cfg, false, err
+	}
+	cfg, err = promptConfig.ReadTopicConfig(path)
+	if err != nil {
+		return cfg, false, fmt.Errorf("error reading config '%s': %w", path, err)
+	}
+	return cfg, cfg.Topic != "", nil
+}
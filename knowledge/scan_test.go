@@ -0,0 +1,66 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	configs := filepath.Join(dir, "configs")
+	writeFile(t, filepath.Join(configs, "git.yaml"),
+		"audience: students\ntopic: Git\nconcepts:\n  - \"**Commits**: Snapshots\"\n  - \"**Branches**: Lines of work\"\n")
+	writeFile(t, filepath.Join(configs, "flowchart.yaml"), "planning_phase: {}\nexecution_phase: {}\n")
+	writeFile(t, filepath.Join(configs, "notes.txt"), "not a config")
+
+	output := filepath.Join(dir, "output")
+	writeFile(t, filepath.Join(output, "git", "answer.md"), "# Git from chat")
+	writeFile(t, filepath.Join(output, "unknown", "answer.md"), "# No config")
+	tree := filepath.Join(output, "explore", "git")
+	writeFile(t, filepath.Join(tree, "config.yaml"), "audience: students\ntopic: Git\n")
+	writeFile(t, filepath.Join(tree, "answer.md"), "# Git from explore")
+	writeFile(t, filepath.Join(tree, "branching", "config.yaml"),
+		"audience: students\ntopic: Branching\nconcepts:\n  - \"**Branches**: Pointers\"\n  - \"**HEAD**: Where you are\"\n")
+	writeFile(t, filepath.Join(tree, "branching", "answer.md"), "# Branching")
+	writeFile(t, filepath.Join(tree, "branching", "merging", "config.yaml"), "audience: students\ntopic: Merging\n")
+
+	g, err := Scan(configs, output+string(filepath.Separator))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, g.Count(KindTopic), "the flowchart and the answer with no config are left out")
+	assert.Equal(t, 3, g.Count(KindConcept))
+	git := g.Node("topic:git")
+	require.NotNil(t, git)
+	assert.Equal(t, filepath.Join(configs, "git.yaml"), git.Path)
+	assert.Equal(t, filepath.Join(output, "explore", "git", "answer.md"), git.Answer, "explore sorts before git")
+	branching := g.Node("topic:branching")
+	assert.Equal(t, filepath.Join(tree, "branching", "config.yaml"), branching.Path)
+	assert.Equal(t, "# Branching", branching.text)
+	assert.Empty(t, g.Node("topic:merging").Answer)
+
+	assert.Equal(t, []string{"topic:git topic:branching", "topic:branching topic:merging"}, edgeKeys(g, EdgeSubtopic))
+	assert.Contains(t, edgeKeys(g, EdgeConcept), "topic:branching concept:branches")
+}
+
+func TestScan_Missing(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Scan(filepath.Join(dir, "configs"))
+	assert.ErrorContains(t, err, "error reading config dir")
+
+	_, err = Scan("", filepath.Join(dir, "output"))
+	assert.ErrorContains(t, err, "error scanning")
+
+	g, err := Scan("", dir)
+	require.NoError(t, err)
+	assert.Empty(t, g.Nodes)
+}
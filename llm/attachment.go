@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------- Attachments ----------
+
+// Attachment size limits.
+const (
+	MaxImageBytes = 20 << 20  // 20 MiB, the OpenAI per-image limit
+	MaxTextBytes  = 256 << 10 // Inlined into the prompt, so kept well below context sizes
+)
+
+// ErrImagesUnsupported is returned when images are attached for a model without vision.
+var ErrImagesUnsupported = errors.New("model does not accept images")
+
+// imageTypes are the image formats vision models accept.
+var imageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// visionModels are substrings of model names known to accept images.
+var visionModels = []string{
+	"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-4-vision", "gpt-5",
+	"llava", "vision", "moondream", "minicpm-v", "gemma3", "qwen2.5vl", "qwen2-vl", "pixtral", "llama4",
+}
+
+// visionReasoningModels are the OpenAI reasoning models that accept images. They
+// match by full name or dated snapshot (o1-2024-12-17), since o1-mini,
+// o1-preview and o3-mini share their prefixes but take text only.
+var visionReasoningModels = []string{"o1", "o1-pro", "o3", "o3-pro", "o4-mini"}
+
+// Attachment is a file sent along with a prompt.
+type Attachment struct {
+	Name     string // Base name, used as the label of inlined text
+	MIMEType string
+	Data     []byte
+}
+
+// LoadAttachment reads a file and detects whether it is an image or text.
+func LoadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("error reading attachment '%s': %w", path, err)
+	}
+	if info.Size() > MaxImageBytes {
+		return Attachment{}, fmt.Errorf("attachment '%s' is %d bytes; the limit is %d", path, info.Size(), MaxImageBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("error reading attachment '%s': %w", path, err)
+	}
+
+	a := Attachment{Name: filepath.Base(path), Data: data}
+	sniffed := http.DetectContentType(data)
+	switch {
+	case slices.Contains(imageTypes, sniffed):
+		a.MIMEType = sniffed
+	case utf8.Valid(data) && !bytes.ContainsRune(data, 0):
+		if len(data) > MaxTextBytes {
+			return Attachment{}, fmt.Errorf("text attachment '%s' is %d bytes; the limit is %d", path, len(data), MaxTextBytes)
+		}
+		a.MIMEType = "text/plain; charset=utf-8"
+		if ext := mime.TypeByExtension(filepath.Ext(path)); strings.HasPrefix(ext, "text/") {
+			a.MIMEType = ext
+		}
+	default:
+		return Attachment{}, fmt.Errorf("unsupported attachment '%s' (%s): attach PNG, JPEG, GIF or WebP images, or UTF-8 text", path, sniffed)
+	}
+	return a, nil
+}
+
+// LoadAttachments loads every path in order.
+func LoadAttachments(paths []string) ([]Attachment, error) {
+	attachments := make([]Attachment, 0, len(paths))
+	for _, path := range paths {
+		a, err := LoadAttachment(path)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// IsImage reports whether the attachment is sent as an image part.
+func (a Attachment) IsImage() bool {
+	return slices.Contains(imageTypes, a.MIMEType)
+}
+
+// SupportsImages reports whether a model is known to accept images. The
+// offline providers accept and ignore them.
+func SupportsImages(provider, model string) bool {
+	switch ProviderType(provider) {
+	case "fake", "echo":
+		return true
+	}
+	name := strings.ToLower(model)
+	for _, v := range visionReasoningModels {
+		if name == v || strings.HasPrefix(name, v+"-20") {
+			return true
+		}
+	}
+	for _, v := range visionModels {
+		if strings.Contains(name, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// InlineTextAttachments appends the text attachments to prompt, each under a
+// labeled fence.
+func InlineTextAttachments(prompt string, attachments []Attachment) string {
+	if !slices.ContainsFunc(attachments, func(a Attachment) bool { return !a.IsImage() }) {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(prompt, "\n"))
+	for _, a := range attachments {
+		if a.IsImage() {
+			continue
+		}
+		text := string(a.Data)
+		fence := fenceFor(text)
+		lang := strings.TrimPrefix(filepath.Ext(a.Name), ".")
+		fmt.Fprintf(&b, "\n\nAttached file: %s\n%s%s\n%s", a.Name, fence, lang, text)
+		if !strings.HasSuffix(text, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString(fence)
+	}
+	return b.String()
+}
+
+// fenceFor returns a backtick fence longer than any backtick run in text.
+func fenceFor(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
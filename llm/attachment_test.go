@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func writeAttachment(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestLoadAttachment(t *testing.T) {
+	img, err := LoadAttachment(writeAttachment(t, "diagram.png", pngHeader))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.MIMEType)
+	assert.True(t, img.IsImage())
+
+	// Detection goes by content, not extension.
+	img, err = LoadAttachment(writeAttachment(t, "diagram.bin", pngHeader))
+	require.NoError(t, err)
+	assert.True(t, img.IsImage())
+
+	text, err := LoadAttachment(writeAttachment(t, "notes.md", []byte("# Notes\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "notes.md", text.Name)
+	assert.False(t, text.IsImage())
+
+	_, err = LoadAttachment(writeAttachment(t, "blob.bin", []byte{0x00, 0x01, 0xff, 0xfe}))
+	assert.ErrorContains(t, err, "unsupported attachment")
+
+	_, err = LoadAttachment(writeAttachment(t, "huge.txt", []byte(strings.Repeat("a", MaxTextBytes+1))))
+	assert.ErrorContains(t, err, "the limit is")
+
+	_, err = LoadAttachments([]string{filepath.Join(t.TempDir(), "missing.png")})
+	assert.Error(t, err)
+}
+
+func TestSupportsImages(t *testing.T) {
+	assert.True(t, SupportsImages("openai", "gpt-4o-mini"))
+	assert.True(t, SupportsImages("ollama", "llava:13b"))
+	assert.True(t, SupportsImages("ollama", "llama3.2-vision"))
+	assert.True(t, SupportsImages("echo", "anything"))
+	assert.False(t, SupportsImages("ollama", "phi4"))
+	assert.False(t, SupportsImages("openai", "gpt-3.5-turbo"))
+
+	assert.True(t, SupportsImages("openai", "o1"))
+	assert.True(t, SupportsImages("openai", "o1-2024-12-17"))
+	assert.True(t, SupportsImages("openai", "o3-pro"))
+	assert.True(t, SupportsImages("openai", "o4-mini"))
+	assert.False(t, SupportsImages("openai", "o1-mini"))
+	assert.False(t, SupportsImages("openai", "o1-preview"))
+	assert.False(t, SupportsImages("openai", "o3-mini-2025-01-31"))
+}
+
+func TestInlineTextAttachments(t *testing.T) {
+	got := InlineTextAttachments("Explain these.", []Attachment{
+		{Name: "main.go", MIMEType: "text/plain", Data: []byte("package main")},
+		{Name: "a.png", MIMEType: "image/png", Data: pngHeader},
+		{Name: "README.md", MIMEType: "text/markdown", Data: []byte("```sh\nls\n```\n")},
+	})
+	assert.Equal(t, "Explain these.\n\n"+
+		"Attached file: main.go\n```go\npackage main\n```\n\n"+
+		"Attached file: README.md\n````md\n```sh\nls\n```\n````", got)
+}
+
+func attachmentClient(t *testing.T, provider, model string, vision bool, sent *[]wrapper.MessageContent, attachments ...Attachment) *Client {
+	t.Helper()
+	return &Client{
+		config: llmConfig.Config{
+			Provider: provider,
+			Model:    llmConfig.ModelConfig{Name: model, Vision: vision},
+			Client:   llmConfig.ClientConfig{Timeout: time.Second},
+		},
+		attachments: attachments,
+		callContent: func(_ context.Context, _ wrapper.Model, msgs []wrapper.MessageContent, _ ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+			*sent = msgs
+			return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{Content: "ok"}}}, nil
+		},
+	}
+}
+
+func TestClient_Attachments(t *testing.T) {
+	image := Attachment{Name: "arch.png", MIMEType: "image/png", Data: pngHeader}
+	text := Attachment{Name: "notes.txt", MIMEType: "text/plain", Data: []byte("cache in front")}
+
+	var sent []wrapper.MessageContent
+	client := attachmentClient(t, "openai", "gpt-4o", false, &sent, image, text)
+	_, err := client.Generate(context.Background(), "Explain this architecture as an analogy")
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	require.Len(t, sent[0].Parts, 2)
+	assert.Contains(t, sent[0].Parts[0].(llms.TextContent).Text, "Attached file: notes.txt\n```txt\ncache in front\n```")
+	assert.True(t, strings.HasPrefix(sent[0].Parts[1].(llms.ImageURLContent).URL, "data:image/png;base64,"))
+
+	client = attachmentClient(t, "ollama", "phi4", false, &sent, image)
+	_, err = client.Generate(context.Background(), "Explain")
+	assert.ErrorIs(t, err, ErrImagesUnsupported)
+	assert.ErrorContains(t, err, "arch.png")
+
+	client = attachmentClient(t, "ollama", "my-custom-vlm", true, &sent, image)
+	client.model, err = ollama.New(ollama.WithModel("my-custom-vlm"))
+	require.NoError(t, err)
+	_, err = client.Generate(context.Background(), "Explain")
+	require.NoError(t, err)
+	assert.Equal(t, llms.BinaryPart("image/png", pngHeader), sent[0].Parts[1], "Ollama takes raw image bytes")
+}
@@ -0,0 +1,318 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BatchRequest is one line of a batch input file.
+type BatchRequest struct {
+	ID          string   `json:"id"`
+	Prompt      string   `json:"prompt,omitempty"`
+	PromptFile  string   `json:"prompt_file,omitempty"` // Relative paths resolve against the input file's directory
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// BatchResult is one line of a batch output file.
+type BatchResult struct {
+	ID               string  `json:"id"`
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Response         string  `json:"response,omitempty"`
+	LatencyMS        int64   `json:"latency_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	Error            string  `json:"error,omitempty"`
+	Partial          bool    `json:"partial,omitempty"` // Interrupted mid-call; Response holds what streamed so far
+}
+
+// Generator produces a response for a prompt; *Client implements it.
+type Generator interface {
+	Generate(ctx context.Context, prompt string) (*Response, error)
+}
+
+// ReadBatchRequests parses a JSONL batch file, resolving prompt files and
+// rejecting lines without an id or prompt.
+func ReadBatchRequests(path string) ([]BatchRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening batch file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	var reqs []BatchRequest
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Bytes()
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		var req BatchRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+		}
+		if req.ID == "" {
+			return nil, fmt.Errorf("line %d: missing id", line)
+		}
+		if seen[req.ID] {
+			return nil, fmt.Errorf("line %d: duplicate id %q", line, req.ID)
+		}
+		seen[req.ID] = true
+		if req.Prompt == "" && req.PromptFile == "" {
+			return nil, fmt.Errorf("line %d: request %q has neither prompt nor prompt_file", line, req.ID)
+		}
+		if req.PromptFile != "" && !filepath.IsAbs(req.PromptFile) {
+			req.PromptFile = filepath.Join(filepath.Dir(path), req.PromptFile)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading batch file '%s': %w", path, err)
+	}
+	return reqs, nil
+}
+
+// CompletedBatchIDs returns the ids that already have a successful result in
+// the output file. A missing file yields an empty set.
+func CompletedBatchIDs(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var res BatchResult
+		if json.Unmarshal(scanner.Bytes(), &res) == nil && res.ID != "" && res.Error == "" {
+			done[res.ID] = true
+		}
+	}
+	return done, scanner.Err()
+}
+
+// BatchExecutor runs batch requests through a worker pool.
+type BatchExecutor struct {
+	Concurrency     int                                                             // Number of workers (minimum 1)
+	RateLimits      map[string]int                                                  // Requests per minute, keyed by provider
+	DefaultProvider string                                                          // Used when a request omits provider
+	DefaultModel    string                                                          // Used when a request omits model
+	NewGenerator    func(req BatchRequest, stream StreamHandler) (Generator, error) // Builds the client for a request, streaming to stream
+	OnResult        func(res BatchResult)                                           // Optional progress callback
+}
+
+// BatchSummary counts the outcomes of a batch run.
+type BatchSummary struct {
+	Succeeded   int
+	Failed      int
+	Skipped     int
+	Interrupted int
+}
+
+// Run executes reqs, streaming one JSON result per line to out as each finishes.
+// Requests whose id is in skip are not run. When the cost budget is exceeded the
+// remaining requests are abandoned and ErrBudgetExceeded is returned. Requests
+// cut off by cancellation are recorded as partial results.
+func (b *BatchExecutor) Run(ctx context.Context, reqs []BatchRequest, skip map[string]bool, out io.Writer) (BatchSummary, error) {
+	var summary BatchSummary
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := b.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	limiters := map[string]*rateLimiter{}
+	for provider, perMinute := range b.RateLimits {
+		if perMinute > 0 {
+			limiters[provider] = newRateLimiter(perMinute)
+		}
+	}
+
+	jobs := make(chan BatchRequest)
+	var (
+		mu       sync.Mutex
+		abortErr error
+		wg       sync.WaitGroup
+	)
+
+	record := func(res BatchResult) error {
+		mu.Lock()
+		defer mu.Unlock()
+		line, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		switch {
+		case res.Partial:
+			summary.Interrupted++
+		case res.Error == "":
+			summary.Succeeded++
+		default:
+			summary.Failed++
+		}
+		if b.OnResult != nil {
+			b.OnResult(res)
+		}
+		return nil
+	}
+	abort := func(err error) {
+		mu.Lock()
+		if abortErr == nil {
+			abortErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				if req.Provider == "" {
+					req.Provider = b.DefaultProvider
+				}
+				if req.Model == "" {
+					req.Model = b.DefaultModel
+				}
+				if l := limiters[req.Provider]; l != nil {
+					if err := l.Wait(ctx); err != nil {
+						return
+					}
+				}
+				res, err := b.runOne(ctx, req)
+				if errors.Is(err, ErrBudgetExceeded) {
+					abort(err)
+					return
+				}
+				if ctx.Err() != nil && !res.Partial {
+					return
+				}
+				if err := record(res); err != nil {
+					abort(fmt.Errorf("failed to write result: %w", err))
+					return
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, req := range reqs {
+		if skip[req.ID] {
+			summary.Skipped++
+			continue
+		}
+		select {
+		case jobs <- req:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if abortErr != nil {
+		return summary, abortErr
+	}
+	return summary, ctx.Err()
+}
+
+func (b *BatchExecutor) runOne(ctx context.Context, req BatchRequest) (BatchResult, error) {
+	res := BatchResult{ID: req.ID, Provider: req.Provider, Model: req.Model}
+
+	prompt := req.Prompt
+	if req.PromptFile != "" {
+		data, err := os.ReadFile(req.PromptFile)
+		if err != nil {
+			res.Error = fmt.Sprintf("error reading prompt file '%s': %v", req.PromptFile, err)
+			return res, nil
+		}
+		prompt = string(data)
+	}
+
+	var streamed bytes.Buffer
+	gen, err := b.NewGenerator(req, WriterStreamHandler(&streamed))
+	if err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	start := time.Now()
+	resp, err := gen.Generate(ctx, prompt)
+	res.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		if ctx.Err() != nil && !errors.Is(err, ErrBudgetExceeded) {
+			res.Partial, res.Response = true, streamed.String()
+		}
+		return res, err
+	}
+	res.Response = resp.Text
+	res.PromptTokens = resp.Usage.PromptTokens
+	res.CompletionTokens = resp.Usage.CompletionTokens
+	res.CostUSD = resp.Usage.Cost
+	return res, nil
+}
+
+// rateLimiter spaces calls evenly to stay under a per-minute limit.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// Wait blocks until the caller may issue its next request.
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	slot := r.next
+	if slot.Before(now) {
+		slot = now
+	}
+	r.next = slot.Add(r.interval)
+	r.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// stubGenerator echoes the prompt, failing for prompts containing "fail".
+type stubGenerator struct {
+	cost float64
+}
+
+func (s stubGenerator) Generate(_ context.Context, prompt string) (*Response, error) {
+	if strings.Contains(prompt, "fail") {
+		return nil, errors.New("model exploded")
+	}
+	return &Response{Text: "re: " + prompt, Usage: Usage{PromptTokens: 3, CompletionTokens: 4, Cost: s.cost}}, nil
+}
+
+func writeBatchFile(t *testing.T, dir string, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, "requests.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	return path
+}
+
+func decodeResults(t *testing.T, data []byte) map[string]BatchResult {
+	t.Helper()
+	results := map[string]BatchResult{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var res BatchResult
+		require.NoError(t, json.Unmarshal([]byte(line), &res))
+		results[res.ID] = res
+	}
+	return results
+}
+
+func TestReadBatchRequests(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "p.txt"), []byte("from file"), 0644))
+	path := writeBatchFile(t, dir,
+		`{"id":"a","prompt":"hello","provider":"openai","model":"gpt-4o","temperature":0.2}`,
+		``,
+		`{"id":"b","prompt_file":"p.txt"}`,
+	)
+
+	reqs, err := ReadBatchRequests(path)
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	assert.Equal(t, 0.2, *reqs[0].Temperature)
+	assert.Equal(t, filepath.Join(dir, "p.txt"), reqs[1].PromptFile)
+
+	for _, bad := range []string{`{"prompt":"x"}`, `{"id":"x"}`, `not json`} {
+		_, err := ReadBatchRequests(writeBatchFile(t, t.TempDir(), bad))
+		assert.Error(t, err, bad)
+	}
+	_, err = ReadBatchRequests(writeBatchFile(t, t.TempDir(), `{"id":"x","prompt":"1"}`, `{"id":"x","prompt":"2"}`))
+	assert.ErrorContains(t, err, "duplicate id")
+}
+
+func TestBatchExecutor_RunWritesResultsAndSkipsDone(t *testing.T) {
+	reqs := []BatchRequest{
+		{ID: "1", Prompt: "one"},
+		{ID: "2", Prompt: "please fail"},
+		{ID: "3", Prompt: "three", Provider: "openai", Model: "gpt-4o"},
+		{ID: "4", Prompt: "four"},
+	}
+
+	var mu sync.Mutex
+	var built []string
+	exec := &BatchExecutor{
+		Concurrency:     3,
+		DefaultProvider: "ollama",
+		DefaultModel:    "phi4",
+		NewGenerator: func(req BatchRequest, _ StreamHandler) (Generator, error) {
+			mu.Lock()
+			built = append(built, req.Provider+":"+req.Model)
+			mu.Unlock()
+			return stubGenerator{}, nil
+		},
+	}
+
+	var out bytes.Buffer
+	summary, err := exec.Run(context.Background(), reqs, map[string]bool{"4": true}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, BatchSummary{Succeeded: 2, Failed: 1, Skipped: 1}, summary)
+
+	results := decodeResults(t, out.Bytes())
+	assert.Len(t, results, 3)
+	assert.Equal(t, "re: one", results["1"].Response)
+	assert.Equal(t, 4, results["1"].CompletionTokens)
+	assert.Equal(t, "model exploded", results["2"].Error)
+	assert.Equal(t, "gpt-4o", results["3"].Model)
+
+	sort.Strings(built)
+	assert.Equal(t, []string{"ollama:phi4", "ollama:phi4", "openai:gpt-4o"}, built)
+}
+
+func TestBatchExecutor_AbortsWhenBudgetExceeded(t *testing.T) {
+	tracker := NewUsageTracker(0.015)
+	client := &Client{
+		pricing: Pricing{"m": {Input: 10000, Output: 0}},
+		tracker: tracker,
+		callGen: func(_ context.Context, _ wrapper.Model, prompt string, _ ...wrapper.CallOption) (string, error) {
+			return "ok", nil
+		},
+	}
+	client.config.Model.Name = "m"
+	client.config.Client.Timeout = time.Second
+
+	reqs := []BatchRequest{{ID: "1", Prompt: "a b c"}, {ID: "2", Prompt: "a b c"}, {ID: "3", Prompt: "a b c"}}
+	exec := &BatchExecutor{
+		Concurrency:  1,
+		NewGenerator: func(BatchRequest, StreamHandler) (Generator, error) { return client, nil },
+	}
+
+	var out bytes.Buffer
+	summary, err := exec.Run(context.Background(), reqs, nil, &out)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Less(t, summary.Succeeded, 3)
+}
+
+// stallingGenerator streams a first chunk, then waits to be cancelled.
+type stallingGenerator struct {
+	stream  StreamHandler
+	started chan struct{}
+}
+
+func (g stallingGenerator) Generate(ctx context.Context, _ string) (*Response, error) {
+	if err := g.stream(ctx, []byte("half an")); err != nil {
+		return nil, err
+	}
+	close(g.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBatchExecutor_RecordsInterruptedRequestsAsPartial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	exec := &BatchExecutor{
+		Concurrency: 1,
+		NewGenerator: func(_ BatchRequest, stream StreamHandler) (Generator, error) {
+			return stallingGenerator{stream: stream, started: started}, nil
+		},
+	}
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	var out bytes.Buffer
+	summary, err := exec.Run(ctx, []BatchRequest{{ID: "1", Prompt: "a"}, {ID: "2", Prompt: "b"}}, nil, &out)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, BatchSummary{Interrupted: 1}, summary)
+
+	results := decodeResults(t, out.Bytes())
+	require.Len(t, results, 1, "requests that never started are not recorded")
+	assert.True(t, results["1"].Partial)
+	assert.Equal(t, "half an", results["1"].Response)
+	assert.Equal(t, "context canceled", results["1"].Error)
+
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	require.NoError(t, os.WriteFile(path, out.Bytes(), 0644))
+	done, err := CompletedBatchIDs(path)
+	require.NoError(t, err)
+	assert.Empty(t, done, "partial results are re-run on resume")
+}
+
+func TestCompletedBatchIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	done, err := CompletedBatchIDs(path)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","response":"x"}
+{"id":"b","error":"boom"}
+garbage
+`), 0644))
+	done, err = CompletedBatchIDs(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true}, done)
+}
+
+func TestRateLimiter_SpacesCalls(t *testing.T) {
+	limiter := newRateLimiter(1200) // one call every 50ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
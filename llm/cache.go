@@ -0,0 +1,220 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// DefaultCacheThreshold is the minimum cosine similarity for a semantic cache hit.
+const DefaultCacheThreshold = 0.95
+
+// CacheEntry is a single prompt/answer pair stored in the semantic cache index.
+type CacheEntry struct {
+	Hash      string    `json:"hash"`
+	Model     string    `json:"model"`
+	Prompt    string    `json:"prompt"`
+	Answer    string    `json:"answer"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CacheHit describes an answer served from the cache.
+type CacheHit struct {
+	Entry CacheEntry
+	Score float64 // Cosine similarity to the cached prompt (1 for exact matches)
+	Exact bool    // True when the normalized prompt hash matched
+}
+
+// SemanticCache reuses answers for prompts that are identical or nearly identical.
+// Entries are stored as JSON at path; embeddings are derived data and can be rebuilt.
+type SemanticCache struct {
+	path      string
+	embedder  wrapper.Embedder
+	threshold float64
+
+	mu      sync.Mutex
+	entries []CacheEntry
+}
+
+// NewSemanticCache loads the cache index at path, starting empty if it does not exist yet.
+func NewSemanticCache(path string, embedder wrapper.Embedder, threshold float64) (*SemanticCache, error) {
+	if embedder == nil {
+		return nil, errors.New("semantic cache requires an embedder")
+	}
+	c := &SemanticCache{path: path, embedder: embedder, threshold: threshold}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse cache index: %w", err)
+	}
+	return c, nil
+}
+
+// Len returns the number of cached entries.
+func (c *SemanticCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Lookup returns the best cached answer for the prompt, or nil when nothing scores
+// at or above the threshold. Only entries produced by the same model are considered.
+func (c *SemanticCache) Lookup(ctx context.Context, model, prompt string) (*CacheHit, error) {
+	hash := promptHash(prompt)
+
+	c.mu.Lock()
+	for _, e := range c.entries {
+		if e.Model == model && e.Hash == hash {
+			c.mu.Unlock()
+			return &CacheHit{Entry: e, Score: 1, Exact: true}, nil
+		}
+	}
+	c.mu.Unlock()
+
+	vec, err := c.embed(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var best *CacheHit
+	for _, e := range c.entries {
+		if e.Model != model || len(e.Embedding) == 0 {
+			continue
+		}
+		score := cosine(vec, e.Embedding)
+		if score >= c.threshold && (best == nil || score > best.Score) {
+			best = &CacheHit{Entry: e, Score: score}
+		}
+	}
+	return best, nil
+}
+
+// Store embeds the prompt and persists the prompt/answer pair, replacing any
+// previous entry with the same normalized prompt and model.
+func (c *SemanticCache) Store(ctx context.Context, model, prompt, answer string) error {
+	vec, err := c.embed(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	entry := CacheEntry{
+		Hash:      promptHash(prompt),
+		Model:     model,
+		Prompt:    prompt,
+		Answer:    answer,
+		Embedding: vec,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	replaced := false
+	for i, e := range c.entries {
+		if e.Model == model && e.Hash == entry.Hash {
+			c.entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		c.entries = append(c.entries, entry)
+	}
+	return c.save()
+}
+
+// Rebuild recomputes hashes and embeddings for every entry, dropping duplicates.
+// Use it after switching embedding models or when the index was edited by hand.
+func (c *SemanticCache) Rebuild(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) == 0 {
+		return 0, c.save()
+	}
+
+	prompts := make([]string, len(c.entries))
+	for i, e := range c.entries {
+		prompts[i] = normalizePrompt(e.Prompt)
+	}
+	vecs, err := c.embedder.Embed(ctx, prompts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed cached prompts: %w", err)
+	}
+	if len(vecs) != len(prompts) {
+		return 0, errors.New("embedder returned an unexpected number of vectors")
+	}
+
+	seen := make(map[string]bool, len(c.entries))
+	rebuilt := make([]CacheEntry, 0, len(c.entries))
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		e := c.entries[i]
+		e.Hash = promptHash(e.Prompt)
+		e.Embedding = vecs[i]
+		key := e.Model + "\x00" + e.Hash
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		rebuilt = append([]CacheEntry{e}, rebuilt...)
+	}
+	c.entries = rebuilt
+	return len(c.entries), c.save()
+}
+
+func (c *SemanticCache) embed(ctx context.Context, prompt string) ([]float32, error) {
+	vecs, err := c.embedder.Embed(ctx, []string{normalizePrompt(prompt)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed prompt: %w", err)
+	}
+	if len(vecs) == 0 {
+		return nil, errors.New("no embedding returned for prompt")
+	}
+	return vecs[0], nil
+}
+
+// save writes the index atomically; callers must hold c.mu.
+func (c *SemanticCache) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache index: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// normalizePrompt collapses whitespace so formatting-only differences hash identically.
+func normalizePrompt(prompt string) string {
+	return strings.Join(strings.Fields(prompt), " ")
+}
+
+func promptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(normalizePrompt(prompt)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyedEmbedder returns a fixed vector per input text.
+type keyedEmbedder struct {
+	vectors map[string][]float32
+	calls   int
+}
+
+func (k *keyedEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	k.calls++
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		vec, ok := k.vectors[in]
+		if !ok {
+			return nil, errors.New("unknown input: " + in)
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func newTestCache(t *testing.T, path string, threshold float64) (*SemanticCache, *keyedEmbedder) {
+	t.Helper()
+	emb := &keyedEmbedder{vectors: map[string][]float32{
+		"Explain git branches":              {1, 0, 0},
+		"Explain git branches briefly":      {0.99, 0.1, 0},
+		"Write a poem about the ocean":      {0, 0, 1},
+		"Explain git branches in a new way": {0.5, 0.5, 0.5},
+	}}
+	cache, err := NewSemanticCache(path, emb, threshold)
+	require.NoError(t, err)
+	return cache, emb
+}
+
+func TestSemanticCache_ExactHitIgnoresWhitespace(t *testing.T) {
+	ctx := context.Background()
+	cache, emb := newTestCache(t, filepath.Join(t.TempDir(), "cache.json"), 0.9)
+
+	require.NoError(t, cache.Store(ctx, "ollama:phi4", "Explain git branches", "answer"))
+	calls := emb.calls
+
+	hit, err := cache.Lookup(ctx, "ollama:phi4", "  Explain   git\nbranches ")
+	require.NoError(t, err)
+	require.NotNil(t, hit)
+	assert.True(t, hit.Exact)
+	assert.Equal(t, 1.0, hit.Score)
+	assert.Equal(t, "answer", hit.Entry.Answer)
+	assert.Equal(t, calls, emb.calls, "exact hits should not call the embedder")
+}
+
+func TestSemanticCache_SemanticHitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestCache(t, filepath.Join(t.TempDir(), "cache.json"), 0.9)
+	require.NoError(t, cache.Store(ctx, "ollama:phi4", "Explain git branches", "answer"))
+
+	hit, err := cache.Lookup(ctx, "ollama:phi4", "Explain git branches briefly")
+	require.NoError(t, err)
+	require.NotNil(t, hit)
+	assert.False(t, hit.Exact)
+	assert.Greater(t, hit.Score, 0.9)
+
+	hit, err = cache.Lookup(ctx, "ollama:phi4", "Write a poem about the ocean")
+	require.NoError(t, err)
+	assert.Nil(t, hit)
+
+	hit, err = cache.Lookup(ctx, "openai:gpt-4o", "Explain git branches briefly")
+	require.NoError(t, err)
+	assert.Nil(t, hit, "entries from other models must not match")
+}
+
+func TestSemanticCache_PersistsAndRebuilds(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "cache.json")
+
+	cache, _ := newTestCache(t, path, 0.9)
+	require.NoError(t, cache.Store(ctx, "ollama:phi4", "Explain git branches", "first"))
+	require.NoError(t, cache.Store(ctx, "ollama:phi4", "Explain  git branches", "second"))
+	require.NoError(t, cache.Store(ctx, "ollama:phi4", "Write a poem about the ocean", "poem"))
+	assert.Equal(t, 2, cache.Len())
+
+	reloaded, _ := newTestCache(t, path, 0.9)
+	assert.Equal(t, 2, reloaded.Len())
+
+	n, err := reloaded.Rebuild(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	hit, err := reloaded.Lookup(ctx, "ollama:phi4", "Explain git branches")
+	require.NoError(t, err)
+	require.NotNil(t, hit)
+	assert.Equal(t, "second", hit.Entry.Answer)
+}
+
+func TestNewSemanticCache_RequiresEmbedder(t *testing.T) {
+	_, err := NewSemanticCache(filepath.Join(t.TempDir(), "cache.json"), nil, 0.9)
+	assert.Error(t, err)
+}
@@ -2,7 +2,10 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	llmConfig "raja.aiml/ai.explorer/config/llm"
 	"raja.aiml/ai.explorer/llm/wrapper"
@@ -15,44 +18,324 @@ type LLM interface {
 
 // Client wraps an LLM model and config.
 type Client struct {
-	model   wrapper.Model
-	config  llmConfig.Config
-	callGen func(ctx context.Context, model wrapper.Model, prompt string, opts ...wrapper.CallOption) (string, error)
+	model       wrapper.Model
+	config      llmConfig.Config
+	callGen     func(ctx context.Context, model wrapper.Model, prompt string, opts ...wrapper.CallOption) (string, error)
+	callContent func(ctx context.Context, model wrapper.Model, messages []wrapper.MessageContent, opts ...wrapper.CallOption) (*wrapper.ContentResponse, error)
+	pricing     Pricing
+	tracker     *UsageTracker
+	httpClient  *http.Client
+	stream      StreamHandler
+	timing      func(target string, t Timing)
+	attachments []Attachment
+	tools       *ToolRegistry
+	toolReport  func(target string, call ToolCall)
+}
+
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithPricing sets the price table used to compute call costs.
+func WithPricing(p Pricing) Option {
+	return func(c *Client) { c.pricing = p }
+}
+
+// WithUsageTracker records every call's usage in t and enforces its budget.
+func WithUsageTracker(t *UsageTracker) Option {
+	return func(c *Client) { c.tracker = t }
+}
+
+// WithHTTPClient sends provider calls through h, e.g. a record/replay cassette.
+// It only applies to clients built with NewDefaultClient.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.httpClient = h }
+}
+
+// WithStreamHandler streams response chunks to h as they arrive. Without it,
+// chunks go to stdout only when verbose logging is enabled.
+func WithStreamHandler(h StreamHandler) Option {
+	return func(c *Client) { c.stream = h }
+}
+
+// WithTimingReport calls report with the first-token and total latency of every call.
+func WithTimingReport(report func(target string, t Timing)) Option {
+	return func(c *Client) { c.timing = report }
+}
+
+// WithAttachments sends the files with every prompt: images as image parts,
+// text inlined under labeled fences.
+func WithAttachments(attachments ...Attachment) Option {
+	return func(c *Client) { c.attachments = attachments }
+}
+
+// WithTools offers the registry's tools to models that support tool calling.
+func WithTools(r *ToolRegistry) Option {
+	return func(c *Client) { c.tools = r }
+}
+
+// WithToolReport calls report after every tool call the model requests.
+func WithToolReport(report func(target string, call ToolCall)) Option {
+	return func(c *Client) { c.toolReport = report }
+}
+
+// Response is the result of a single generation.
+type Response struct {
+	Text          string
+	StopReason    string
+	Usage         Usage
+	Latency       time.Duration
+	FirstToken    time.Duration // Until the first streamed chunk, or Latency when not streamed
+	Continuations int           // Follow-up requests made after length stops
+	ToolCalls     []ToolCall    // Tools run while generating, in order
+
+	toolCalls []wrapper.ToolCall // Tools requested by this turn
 }
 
 // NewClient supports injecting dependencies for testability.
-func NewClient(cfg llmConfig.Config, provider wrapper.Provider, generator func(context.Context, wrapper.Model, string, ...wrapper.CallOption) (string, error)) (*Client, error) {
+func NewClient(cfg llmConfig.Config, provider wrapper.Provider, generator func(context.Context, wrapper.Model, string, ...wrapper.CallOption) (string, error), opts ...Option) (*Client, error) {
 	model, err := provider.Init(cfg.Provider, cfg.Model.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM provider: %w", err)
 	}
-	return &Client{
+	c := &Client{
 		model:   model,
 		config:  cfg,
 		callGen: generator,
-	}, nil
+		pricing: DefaultPricing,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // NewDefaultClient returns a client with default dependencies.
-func NewDefaultClient(cfg llmConfig.Config) (*Client, error) {
-	return NewClient(cfg, &wrapper.LangchaingoProvider{}, wrapper.GenerateFromSinglePrompt)
+func NewDefaultClient(cfg llmConfig.Config, opts ...Option) (*Client, error) {
+	// The provider is built before NewClient applies options, so read the HTTP client up front.
+	settings := &Client{}
+	for _, opt := range opts {
+		opt(settings)
+	}
+	provider := &wrapper.LangchaingoProvider{Options: cfg.Model.Options, HTTPClient: settings.httpClient}
+	c, err := NewClient(cfg, provider, wrapper.GenerateFromSinglePrompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.callContent = wrapper.GenerateContent
+	return c, nil
+}
+
+// Target returns the "provider:model" identifier of the client.
+func (c *Client) Target() string {
+	return c.config.Provider + ":" + c.config.Model.Name
 }
 
 // Chat generates a response for the given prompt.
 func (c *Client) Chat(ctx context.Context, prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.config.Client.Timeout)
+	resp, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// Generate returns the response for the prompt along with its token usage and cost.
+// When the model stops at the length limit, up to Client.AutoContinue
+// continuation requests extend the answer.
+func (c *Client) Generate(ctx context.Context, prompt string) (*Response, error) {
+	resp, err := c.chat(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	for resp.Continuations < c.config.Client.AutoContinue && isLengthStop(resp.StopReason) {
+		if err := c.continueResponse(ctx, prompt, resp); err != nil {
+			return nil, fmt.Errorf("continuation %d: %w", resp.Continuations+1, err)
+		}
+	}
+	return resp, nil
+}
+
+// chat sends the prompt with the configured call options plus any extras,
+// running the client's tools when the model requests them.
+func (c *Client) chat(ctx context.Context, prompt string, extra ...wrapper.CallOption) (*Response, error) {
+	msg, err := c.promptMessage(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if c.toolsEnabled() {
+		return c.sendWithTools(ctx, []wrapper.MessageContent{msg}, extra...)
+	}
+	return c.send(ctx, []wrapper.MessageContent{msg}, c.streamHandler(), extra...)
+}
+
+// promptMessage builds the user message for prompt with the client's attachments.
+func (c *Client) promptMessage(prompt string) (wrapper.MessageContent, error) {
+	msg := wrapper.HumanMessage(InlineTextAttachments(prompt, c.attachments))
+	for _, a := range c.attachments {
+		if !a.IsImage() {
+			continue
+		}
+		if !c.config.Model.Vision && !SupportsImages(c.config.Provider, c.config.Model.Name) {
+			return msg, fmt.Errorf("%w: %s cannot take the attached image %s; use a vision model (e.g. gpt-4o, llava) or pass --vision if it is one",
+				ErrImagesUnsupported, c.Target(), a.Name)
+		}
+		msg.Parts = append(msg.Parts, wrapper.ImagePart(c.model, a.MIMEType, a.Data))
+	}
+	return msg, nil
+}
+
+// send makes one call with the conversation in messages, streaming to stream if set.
+// The call is streamed whenever a first-token or idle timeout must be enforced.
+func (c *Client) send(ctx context.Context, messages []wrapper.MessageContent, stream StreamHandler, extra ...wrapper.CallOption) (*Response, error) {
+	if c.tracker != nil {
+		if err := c.tracker.CheckBudget(); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := c.config.Client
+	ctx, cancel := context.WithTimeoutCause(ctx, cfg.Timeout, fmt.Errorf("%w (limit %s)", ErrTotalTimeout, cfg.Timeout))
 	defer cancel()
 
-	opts := []wrapper.CallOption{
-		wrapper.WithTemperature(c.config.Model.Temperature),
+	opts := append(callOptions(c.config.Model), extra...)
+	var watchdog *streamWatchdog
+	if stream != nil || cfg.FirstTokenTimeout > 0 || cfg.IdleTimeout > 0 {
+		ctx, watchdog = newStreamWatchdog(ctx, cfg.FirstTokenTimeout, cfg.IdleTimeout)
+		defer watchdog.stop()
+		handler := watchdog.Handle
+		if stream != nil {
+			handler = TeeStreamHandler(watchdog.Handle, stream)
+		}
+		opts = append(opts, wrapper.WithStreamingFunc(handler))
+	}
+
+	start := time.Now()
+	resp, err := c.generate(ctx, messages, opts...)
+	if err != nil {
+		if cause := timeoutCause(ctx); cause != nil {
+			err = cause
+		}
+		return nil, fmt.Errorf("chat failed: %w", err)
+	}
+	resp.Latency = time.Since(start)
+	resp.FirstToken = resp.Latency
+	timing := Timing{FirstToken: resp.Latency, Total: resp.Latency}
+	if watchdog != nil {
+		if first, ok := watchdog.firstChunk(); ok {
+			resp.FirstToken, timing.FirstToken, timing.Streamed = first, first, true
+		}
+	}
+	if c.timing != nil {
+		c.timing(c.Target(), timing)
+	}
+	resp.Usage = c.pricing.Apply(c.config.Provider, c.config.Model.Name, resp.Usage)
+
+	if c.tracker != nil {
+		c.tracker.Record(c.Target(), resp.Usage)
+	}
+	return resp, nil
+}
+
+func (c *Client) streamHandler() StreamHandler {
+	if c.stream != nil {
+		return c.stream
 	}
 	if c.config.Client.VerboseLogging {
-		opts = append(opts, wrapper.WithStreamingFunc(defaultStreamHandler))
+		return defaultStreamHandler
+	}
+	return nil
+}
+
+// generate calls the model, preferring the content API so provider-reported
+// usage is available, and estimates token counts when it is not.
+func (c *Client) generate(ctx context.Context, messages []wrapper.MessageContent, opts ...wrapper.CallOption) (*Response, error) {
+	prompt := wrapper.MessageText(messages)
+	if c.callContent == nil {
+		text, err := c.callGen(ctx, c.model, prompt, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Text: text, Usage: estimateUsage(prompt, text)}, nil
 	}
 
-	response, err := c.callGen(ctx, c.model, prompt, opts...)
+	content, err := c.callContent(ctx, c.model, messages, opts...)
 	if err != nil {
-		return "", fmt.Errorf("chat failed: %w", err)
+		return nil, err
+	}
+	if content == nil || len(content.Choices) == 0 {
+		return nil, errors.New("empty response from model")
+	}
+	choice := content.Choices[0]
+	usage, ok := reportedUsage(choice.GenerationInfo)
+	if !ok {
+		usage = estimateUsage(prompt, choice.Content)
+	}
+	return &Response{Text: choice.Content, StopReason: choice.StopReason, Usage: usage, toolCalls: choice.ToolCalls}, nil
+}
+
+// reportedUsage reads token counts from provider generation info.
+func reportedUsage(info map[string]any) (Usage, bool) {
+	prompt, okP := intValue(info, "PromptTokens", "InputTokens")
+	completion, okC := intValue(info, "CompletionTokens", "OutputTokens")
+	if (!okP && !okC) || prompt+completion == 0 {
+		return Usage{}, false
+	}
+	return Usage{PromptTokens: prompt, CompletionTokens: completion}, true
+}
+
+func estimateUsage(prompt, completion string) Usage {
+	return Usage{
+		PromptTokens:     EstimateTokens(prompt),
+		CompletionTokens: EstimateTokens(completion),
+		Estimated:        true,
+	}
+}
+
+func intValue(info map[string]any, keys ...string) (int, bool) {
+	for _, k := range keys {
+		switch v := info[k].(type) {
+		case int:
+			return v, true
+		case int32:
+			return int(v), true
+		case int64:
+			return int(v), true
+		case float64:
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// callOptions maps the model configuration onto langchaingo call options,
+// skipping zero values so provider defaults apply.
+func callOptions(m llmConfig.ModelConfig) []wrapper.CallOption {
+	opts := []wrapper.CallOption{
+		wrapper.WithTemperature(m.Temperature),
+	}
+	if m.MaxTokens > 0 {
+		opts = append(opts, wrapper.WithMaxTokens(m.MaxTokens))
+	}
+	if m.TopP > 0 {
+		opts = append(opts, wrapper.WithTopP(m.TopP))
+	}
+	if m.TopK > 0 {
+		opts = append(opts, wrapper.WithTopK(m.TopK))
+	}
+	if len(m.Stop) > 0 {
+		opts = append(opts, wrapper.WithStopWords(m.Stop))
+	}
+	if m.Seed != 0 {
+		opts = append(opts, wrapper.WithSeed(m.Seed))
+	}
+	if m.FrequencyPenalty != 0 {
+		opts = append(opts, wrapper.WithFrequencyPenalty(m.FrequencyPenalty))
+	}
+	if m.PresencePenalty != 0 {
+		opts = append(opts, wrapper.WithPresencePenalty(m.PresencePenalty))
+	}
+	if m.RepetitionPenalty != 0 {
+		opts = append(opts, wrapper.WithRepetitionPenalty(m.RepetitionPenalty))
 	}
-	return response, nil
+	return opts
 }
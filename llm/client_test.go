@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
 	llmConfig "raja.aiml/ai.explorer/config/llm"
 	"raja.aiml/ai.explorer/llm/wrapper"
 )
@@ -133,3 +136,113 @@ func TestClient_Chat_Error(t *testing.T) {
 	assert.Empty(t, resp)
 	assert.Contains(t, err.Error(), "chat failed")
 }
+
+func TestCallOptions_MapsModelConfig(t *testing.T) {
+	opts := callOptions(llmConfig.ModelConfig{
+		Temperature:       0.2,
+		MaxTokens:         256,
+		TopP:              0.9,
+		TopK:              40,
+		Stop:              []string{"###"},
+		Seed:              42,
+		FrequencyPenalty:  0.5,
+		PresencePenalty:   0.3,
+		RepetitionPenalty: 1.1,
+	})
+
+	var got llms.CallOptions
+	for _, opt := range opts {
+		opt(&got)
+	}
+	assert.Equal(t, 0.2, got.Temperature)
+	assert.Equal(t, 256, got.MaxTokens)
+	assert.Equal(t, 0.9, got.TopP)
+	assert.Equal(t, 40, got.TopK)
+	assert.Equal(t, []string{"###"}, got.StopWords)
+	assert.Equal(t, 42, got.Seed)
+	assert.Equal(t, 0.5, got.FrequencyPenalty)
+	assert.Equal(t, 0.3, got.PresencePenalty)
+	assert.Equal(t, 1.1, got.RepetitionPenalty)
+}
+
+func TestCallOptions_SkipsZeroValues(t *testing.T) {
+	opts := callOptions(llmConfig.ModelConfig{Temperature: 0.8})
+	assert.Len(t, opts, 1)
+}
+
+func TestClient_Generate_ReportsUsageAndCost(t *testing.T) {
+	tracker := NewUsageTracker(0)
+	client := &Client{
+		config: llmConfig.Config{
+			Provider: "openai",
+			Model:    llmConfig.ModelConfig{Name: "gpt-4o"},
+			Client:   llmConfig.ClientConfig{Timeout: time.Second},
+		},
+		pricing: DefaultPricing,
+		tracker: tracker,
+		callContent: func(ctx context.Context, _ wrapper.Model, msgs []wrapper.MessageContent, _ ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+			assert.Len(t, msgs, 1)
+			return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{
+				Content:        "answer",
+				StopReason:     "stop",
+				GenerationInfo: map[string]any{"PromptTokens": 1000, "CompletionTokens": 2000},
+			}}}, nil
+		},
+	}
+
+	resp, err := client.Generate(context.Background(), "question")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer", resp.Text)
+	assert.Equal(t, "stop", resp.StopReason)
+	assert.Equal(t, 1000, resp.Usage.PromptTokens)
+	assert.False(t, resp.Usage.Estimated)
+	assert.InDelta(t, 0.0025+0.02, resp.Usage.Cost, 1e-9)
+
+	total, calls := tracker.Total()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, resp.Usage.Cost, total.Cost)
+}
+
+func TestClient_Generate_EstimatesUsageAndEnforcesBudget(t *testing.T) {
+	tracker := NewUsageTracker(0.000001)
+	calls := 0
+	client := &Client{
+		config: llmConfig.Config{
+			Provider: "openai",
+			Model:    llmConfig.ModelConfig{Name: "gpt-4"},
+			Client:   llmConfig.ClientConfig{Timeout: time.Second},
+		},
+		pricing: DefaultPricing,
+		tracker: tracker,
+		callGen: func(ctx context.Context, _ wrapper.Model, prompt string, _ ...wrapper.CallOption) (string, error) {
+			calls++
+			return "a reasonably long answer about version control", nil
+		},
+	}
+
+	resp, err := client.Generate(context.Background(), "question")
+	assert.NoError(t, err)
+	assert.True(t, resp.Usage.Estimated)
+	assert.Greater(t, resp.Usage.CompletionTokens, 0)
+
+	_, err = client.Generate(context.Background(), "question")
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_WithStreamHandler(t *testing.T) {
+	var streamed bytes.Buffer
+	model := wrapper.NewEchoModel()
+	client, err := NewClient(llmConfig.Config{
+		Provider: "echo",
+		Model:    llmConfig.ModelConfig{Name: "any"},
+		Client:   llmConfig.ClientConfig{Timeout: time.Second, VerboseLogging: true},
+	}, &MockProvider{model: model}, nil, WithStreamHandler(WriterStreamHandler(&streamed)))
+	require.NoError(t, err)
+	client.callContent = wrapper.GenerateContent
+
+	resp, err := client.Chat(context.Background(), "stream this answer, please")
+	require.NoError(t, err)
+	assert.Equal(t, "stream this answer, please", resp)
+	assert.Equal(t, resp, streamed.String(), "the handler replaces the stdout default")
+}
@@ -0,0 +1,265 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ComparisonEntry is one target's answer in a comparison.
+type ComparisonEntry struct {
+	Target   Target
+	Response string
+	Latency  time.Duration
+	Usage    Usage
+	Error    string
+}
+
+// Comparison holds the answers of several targets to the same prompt.
+type Comparison struct {
+	Prompt  string
+	Entries []ComparisonEntry
+	// Similarity[i][j] is the cosine similarity between the answers of entries i and j.
+	// It is nil when similarity was not computed.
+	Similarity [][]float64
+}
+
+// CompareTargets sends the prompt to every target concurrently. Failures are
+// recorded per entry; entries keep the order of targets.
+func CompareTargets(ctx context.Context, prompt string, targets []Target, newGen func(Target) (Generator, error)) *Comparison {
+	cmp := &Comparison{Prompt: prompt, Entries: make([]ComparisonEntry, len(targets))}
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			entry := ComparisonEntry{Target: target}
+			defer func() { cmp.Entries[i] = entry }()
+
+			gen, err := newGen(target)
+			if err != nil {
+				entry.Error = err.Error()
+				return
+			}
+			start := time.Now()
+			resp, err := gen.Generate(ctx, prompt)
+			entry.Latency = time.Since(start)
+			if err != nil {
+				entry.Error = err.Error()
+				return
+			}
+			entry.Response = resp.Text
+			entry.Usage = resp.Usage
+		}(i, target)
+	}
+	wg.Wait()
+	return cmp
+}
+
+// AddSimilarity fills in the pairwise similarity of the successful answers.
+// Failed entries score 0 against everything.
+func (c *Comparison) AddSimilarity(ctx context.Context, service *SimilarityService) error {
+	var texts []string
+	var index []int
+	for i, e := range c.Entries {
+		if e.Error == "" {
+			texts = append(texts, e.Response)
+			index = append(index, i)
+		}
+	}
+	if len(texts) < 2 {
+		return errors.New("need at least two successful answers to compare")
+	}
+
+	matrix, err := service.Pairwise(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	c.Similarity = make([][]float64, len(c.Entries))
+	for i := range c.Similarity {
+		c.Similarity[i] = make([]float64, len(c.Entries))
+	}
+	for a, i := range index {
+		for b, j := range index {
+			c.Similarity[i][j] = matrix[a][b]
+		}
+	}
+	return nil
+}
+
+// Markdown renders the comparison as a markdown report: a metrics table, the
+// answers side by side, and the similarity matrix when available.
+func (c *Comparison) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Model Comparison\n\n## Prompt\n\n")
+	b.WriteString(quoteMarkdown(c.Prompt))
+	b.WriteString("\n\n## Metrics\n\n| Target | Latency | Prompt tokens | Completion tokens | Cost |\n|---|---|---|---|---|\n")
+	for _, e := range c.Entries {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			e.Target, e.latency(), e.tokens(e.Usage.PromptTokens), e.tokens(e.Usage.CompletionTokens), e.cost())
+	}
+
+	b.WriteString("\n## Responses\n\n|")
+	for _, e := range c.Entries {
+		b.WriteString(" " + e.Target.String() + " |")
+	}
+	b.WriteString("\n|" + strings.Repeat("---|", len(c.Entries)) + "\n|")
+	for _, e := range c.Entries {
+		b.WriteString(" " + markdownCell(e.answer()) + " |")
+	}
+	b.WriteString("\n")
+
+	if c.Similarity != nil {
+		b.WriteString("\n## Answer Similarity\n\n| |")
+		for _, e := range c.Entries {
+			b.WriteString(" " + e.Target.String() + " |")
+		}
+		b.WriteString("\n|---|" + strings.Repeat("---|", len(c.Entries)) + "\n")
+		for i, e := range c.Entries {
+			b.WriteString("| " + e.Target.String() + " |")
+			for j := range c.Entries {
+				b.WriteString(" " + c.similarity(i, j) + " |")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// HTML renders the comparison as a standalone HTML page with the answers in columns.
+func (c *Comparison) HTML() (string, error) {
+	type column struct {
+		Target, Latency, PromptTokens, CompletionTokens, Cost, Answer string
+		Failed                                                        bool
+	}
+	type row struct {
+		Target string
+		Scores []string
+	}
+	data := struct {
+		Prompt     string
+		Columns    []column
+		Similarity []row
+	}{Prompt: c.Prompt}
+
+	for i, e := range c.Entries {
+		data.Columns = append(data.Columns, column{
+			Target:           e.Target.String(),
+			Latency:          e.latency(),
+			PromptTokens:     e.tokens(e.Usage.PromptTokens),
+			CompletionTokens: e.tokens(e.Usage.CompletionTokens),
+			Cost:             e.cost(),
+			Answer:           e.answer(),
+			Failed:           e.Error != "",
+		})
+		if c.Similarity != nil {
+			r := row{Target: e.Target.String()}
+			for j := range c.Entries {
+				r.Scores = append(r.Scores, c.similarity(i, j))
+			}
+			data.Similarity = append(data.Similarity, r)
+		}
+	}
+
+	var b strings.Builder
+	if err := comparisonHTML.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render comparison: %w", err)
+	}
+	return b.String(), nil
+}
+
+var comparisonHTML = template.Must(template.New("compare").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Model Comparison</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.5em; vertical-align: top; text-align: left; }
+td.answer { white-space: pre-wrap; }
+td.failed { color: #b00; }
+pre { white-space: pre-wrap; background: #f6f6f6; padding: 1em; }
+</style>
+</head>
+<body>
+<h1>Model Comparison</h1>
+<h2>Prompt</h2>
+<pre>{{.Prompt}}</pre>
+<h2>Responses</h2>
+<table>
+<tr><th></th>{{range .Columns}}<th>{{.Target}}</th>{{end}}</tr>
+<tr><th>Latency</th>{{range .Columns}}<td>{{.Latency}}</td>{{end}}</tr>
+<tr><th>Prompt tokens</th>{{range .Columns}}<td>{{.PromptTokens}}</td>{{end}}</tr>
+<tr><th>Completion tokens</th>{{range .Columns}}<td>{{.CompletionTokens}}</td>{{end}}</tr>
+<tr><th>Cost</th>{{range .Columns}}<td>{{.Cost}}</td>{{end}}</tr>
+<tr><th>Answer</th>{{range .Columns}}<td class="answer{{if .Failed}} failed{{end}}">{{.Answer}}</td>{{end}}</tr>
+</table>
+{{if .Similarity}}<h2>Answer Similarity</h2>
+<table>
+<tr><th></th>{{range .Columns}}<th>{{.Target}}</th>{{end}}</tr>
+{{range .Similarity}}<tr><th>{{.Target}}</th>{{range .Scores}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+func (c *Comparison) similarity(i, j int) string {
+	if c.Entries[i].Error != "" || c.Entries[j].Error != "" {
+		return "–"
+	}
+	return fmt.Sprintf("%.3f", c.Similarity[i][j])
+}
+
+func (e ComparisonEntry) answer() string {
+	if e.Error != "" {
+		return "error: " + e.Error
+	}
+	return e.Response
+}
+
+func (e ComparisonEntry) latency() string {
+	if e.Latency == 0 {
+		return "–"
+	}
+	return e.Latency.Round(time.Millisecond).String()
+}
+
+func (e ComparisonEntry) tokens(n int) string {
+	if e.Error != "" {
+		return "–"
+	}
+	if e.Usage.Estimated {
+		return fmt.Sprintf("~%d", n)
+	}
+	return fmt.Sprint(n)
+}
+
+func (e ComparisonEntry) cost() string {
+	if e.Error != "" || !e.Usage.Priced {
+		return "unknown"
+	}
+	return fmt.Sprintf("$%.4f", e.Usage.Cost)
+}
+
+// markdownCell flattens text into a single markdown table cell.
+func markdownCell(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", `\|`)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+func quoteMarkdown(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = "> " + l
+	}
+	return strings.Join(lines, "\n")
+}
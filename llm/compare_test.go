@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Target
+		wantErr bool
+	}{
+		{"ollama:phi4", Target{"ollama", "phi4"}, false},
+		{"ollama:llama3:8b", Target{"ollama", "llama3:8b"}, false},
+		{"openai", Target{}, true},
+		{":gpt-4o", Target{}, true},
+		{"openai:", Target{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTarget(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+}
+
+func TestCompareTargets(t *testing.T) {
+	targets := []Target{{"ollama", "phi4"}, {"openai", "gpt-4o"}, {"broken", "x"}}
+	cmp := CompareTargets(context.Background(), "why?", targets, func(target Target) (Generator, error) {
+		if target.Provider == "broken" {
+			return nil, errors.New("unsupported LLM provider: broken")
+		}
+		return stubGenerator{cost: 0.01}, nil
+	})
+
+	require.Len(t, cmp.Entries, 3)
+	assert.Equal(t, targets[0], cmp.Entries[0].Target)
+	assert.Equal(t, "re: why?", cmp.Entries[0].Response)
+	assert.Equal(t, 4, cmp.Entries[1].Usage.CompletionTokens)
+	assert.Contains(t, cmp.Entries[2].Error, "unsupported LLM provider")
+}
+
+func TestComparison_AddSimilaritySkipsFailures(t *testing.T) {
+	cmp := &Comparison{Entries: []ComparisonEntry{
+		{Response: "a"}, {Error: "boom"}, {Response: "b"},
+	}}
+	service := NewSimilarityService(&mockEmbedder{output: [][]float32{{1, 0}, {0, 1}}})
+
+	require.NoError(t, cmp.AddSimilarity(context.Background(), service))
+	assert.InDelta(t, 1.0, cmp.Similarity[0][0], 1e-9)
+	assert.InDelta(t, 0.0, cmp.Similarity[0][2], 1e-9)
+	assert.Equal(t, "–", cmp.similarity(0, 1))
+
+	single := &Comparison{Entries: []ComparisonEntry{{Response: "a"}, {Error: "boom"}}}
+	assert.Error(t, single.AddSimilarity(context.Background(), service))
+}
+
+func TestComparison_Render(t *testing.T) {
+	cmp := &Comparison{
+		Prompt: "Explain <generics>",
+		Entries: []ComparisonEntry{
+			{Target: Target{"ollama", "phi4"}, Response: "line one\nline | two", Usage: Usage{PromptTokens: 5, CompletionTokens: 7, Priced: true}},
+			{Target: Target{"openai", "gpt-4o"}, Error: "timeout"},
+		},
+		Similarity: [][]float64{{1, 0}, {0, 1}},
+	}
+
+	md := cmp.Markdown()
+	assert.Contains(t, md, "| ollama:phi4 | openai:gpt-4o |")
+	assert.Contains(t, md, `line one<br>line \| two`)
+	assert.Contains(t, md, "error: timeout")
+	assert.Contains(t, md, "$0.0000")
+	assert.Contains(t, md, "## Answer Similarity")
+
+	html, err := cmp.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, html, "Explain &lt;generics&gt;")
+	assert.Contains(t, html, `<th>openai:gpt-4o</th>`)
+	assert.Contains(t, html, `class="answer failed"`)
+	assert.Equal(t, 1, strings.Count(html, "<h2>Answer Similarity</h2>"))
+}
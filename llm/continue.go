@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// ---------- Auto-Continuation ----------
+
+// ContinuePrompt asks the model to pick up a truncated answer.
+const ContinuePrompt = "Your previous answer was cut off. Continue exactly where it stopped, without repeating any text already written."
+
+// Overlap bounds when stitching a continuation onto the answer so far. Shorter
+// matches are likely coincidental and are kept.
+const (
+	minOverlap = 12
+	maxOverlap = 400
+)
+
+// lengthStopReasons are the finish reasons providers report for output-token caps.
+var lengthStopReasons = []string{"length", "max_tokens", "max_output_tokens"}
+
+// isLengthStop reports whether the model stopped because it ran out of output tokens.
+func isLengthStop(reason string) bool {
+	for _, r := range lengthStopReasons {
+		if strings.EqualFold(reason, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// continueResponse asks for the rest of a truncated answer and appends it to resp.
+func (c *Client) continueResponse(ctx context.Context, prompt string, resp *Response) error {
+	msg, err := c.promptMessage(prompt)
+	if err != nil {
+		return err
+	}
+	messages := []wrapper.MessageContent{
+		msg,
+		wrapper.AIMessage(resp.Text),
+		wrapper.HumanMessage(ContinuePrompt),
+	}
+	var trimmer *overlapTrimmer
+	stream := c.streamHandler()
+	if stream != nil {
+		trimmer = &overlapTrimmer{prev: resp.Text, next: stream}
+		stream = trimmer.Handle
+	}
+
+	next, err := c.send(ctx, messages, stream)
+	if err != nil {
+		return err
+	}
+	if trimmer != nil {
+		if err := trimmer.Flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	resp.Text = stitch(resp.Text, next.Text)
+	resp.StopReason = next.StopReason
+	resp.Usage.Add(next.Usage)
+	resp.Latency += next.Latency
+	resp.Continuations++
+	return nil
+}
+
+// stitch appends next to prev, dropping any text next repeats from the end of prev.
+func stitch(prev, next string) string {
+	return prev + next[overlap(prev, next):]
+}
+
+// overlap returns the length of the longest suffix of prev that next starts with.
+func overlap(prev, next string) int {
+	for k := min(len(prev), len(next), maxOverlap); k >= minOverlap; k-- {
+		if strings.HasSuffix(prev, next[:k]) {
+			return k
+		}
+	}
+	return 0
+}
+
+// overlapTrimmer holds back the start of a streamed continuation until the
+// overlap with the answer so far is known, so repeated text is never shown.
+type overlapTrimmer struct {
+	prev    string
+	next    StreamHandler
+	buf     []byte
+	flushed bool
+}
+
+// Handle is the StreamHandler for the continuation.
+func (t *overlapTrimmer) Handle(ctx context.Context, chunk []byte) error {
+	if t.flushed {
+		return t.next(ctx, chunk)
+	}
+	t.buf = append(t.buf, chunk...)
+	if len(t.buf) < maxOverlap {
+		return nil
+	}
+	return t.Flush(ctx)
+}
+
+// Flush sends the held-back text minus the overlap.
+func (t *overlapTrimmer) Flush(ctx context.Context) error {
+	if t.flushed {
+		return nil
+	}
+	t.flushed = true
+	rest := t.buf[overlap(t.prev, string(t.buf)):]
+	if len(rest) == 0 {
+		return nil
+	}
+	return t.next(ctx, rest)
+}
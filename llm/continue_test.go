@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// continuationClient answers each call with the next part, streaming it in two chunks.
+func continuationClient(t *testing.T, autoContinue int, parts []string, stops []string, calls *[][]wrapper.MessageContent) *Client {
+	t.Helper()
+	return &Client{
+		config: llmConfig.Config{
+			Provider: "fake",
+			Model:    llmConfig.ModelConfig{Name: "any"},
+			Client:   llmConfig.ClientConfig{Timeout: time.Second, AutoContinue: autoContinue},
+		},
+		callContent: func(ctx context.Context, _ wrapper.Model, msgs []wrapper.MessageContent, opts ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+			i := len(*calls)
+			*calls = append(*calls, msgs)
+			var o llms.CallOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			if o.StreamingFunc != nil {
+				half := len(parts[i]) / 2
+				o.StreamingFunc(ctx, []byte(parts[i][:half]))
+				o.StreamingFunc(ctx, []byte(parts[i][half:]))
+			}
+			return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{Content: parts[i], StopReason: stops[i]}}}, nil
+		},
+	}
+}
+
+func TestClient_Generate_AutoContinue(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	var streamed bytes.Buffer
+	client := continuationClient(t, 3,
+		[]string{"Git stores snapshots of the whole project", "snapshots of the whole project, not diffs, and", " branches are cheap."},
+		[]string{"length", "length", "stop"}, &calls)
+	client.stream = WriterStreamHandler(&streamed)
+
+	resp, err := client.Generate(context.Background(), "Explain git")
+	require.NoError(t, err)
+
+	want := "Git stores snapshots of the whole project, not diffs, and branches are cheap."
+	assert.Equal(t, want, resp.Text)
+	assert.Equal(t, want, streamed.String(), "the overlap is not streamed twice")
+	assert.Equal(t, 2, resp.Continuations)
+	assert.Equal(t, "stop", resp.StopReason)
+	require.Len(t, calls, 3)
+	assert.Equal(t, "Explain git\nGit stores snapshots of the whole project\n"+ContinuePrompt, wrapper.MessageText(calls[1]))
+	assert.Equal(t, llms.ChatMessageTypeAI, calls[1][1].Role)
+}
+
+func TestClient_Generate_AutoContinueLimit(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	client := continuationClient(t, 1, []string{"one", " two", " three"}, []string{"length", "length", "length"}, &calls)
+
+	resp, err := client.Generate(context.Background(), "count")
+	require.NoError(t, err)
+	assert.Equal(t, "one two", resp.Text)
+	assert.Equal(t, 1, resp.Continuations)
+	assert.Equal(t, "length", resp.StopReason)
+	assert.Len(t, calls, 2)
+}
+
+func TestClient_Generate_NoAutoContinueByDefault(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	client := continuationClient(t, 0, []string{"cut off"}, []string{"length"}, &calls)
+
+	resp, err := client.Generate(context.Background(), "q")
+	require.NoError(t, err)
+	assert.Equal(t, "cut off", resp.Text)
+	assert.Zero(t, resp.Continuations)
+	assert.Len(t, calls, 1)
+}
+
+func TestStitch(t *testing.T) {
+	assert.Equal(t, "The quick brown fox jumps", stitch("The quick brown", " fox jumps"))
+	assert.Equal(t, "The quick brown fox jumps", stitch("The quick brown", "The quick brown fox jumps"))
+	assert.Equal(t, "abc and the", stitch("abc and", " the"), "short matches are not treated as overlap")
+	assert.True(t, isLengthStop("MAX_TOKENS"))
+	assert.False(t, isLengthStop("stop"))
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultCoverageThreshold is the similarity a concept needs with some section to count as covered.
+const DefaultCoverageThreshold = 0.45
+
+// maxSectionRunes keeps a section within embedding model input limits.
+const maxSectionRunes = 6000
+
+// Section is a markdown heading and the text under it.
+type Section struct {
+	Heading string
+	Body    string
+}
+
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+
+// SplitSections splits markdown at its ATX headings, ignoring "#" lines inside
+// code fences. Text before the first heading becomes an untitled section.
+func SplitSections(markdown string) []Section {
+	var sections []Section
+	current := Section{}
+	var body []string
+	inFence := false
+
+	flush := func() {
+		current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.Heading != "" || current.Body != "" {
+			sections = append(sections, current)
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil && !inFence {
+			flush()
+			current = Section{Heading: m[1]}
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return sections
+}
+
+func (s Section) text() string {
+	text := strings.TrimSpace(s.Heading + "\n" + s.Body)
+	if r := []rune(text); len(r) > maxSectionRunes {
+		text = string(r[:maxSectionRunes])
+	}
+	return text
+}
+
+func (s Section) title() string {
+	if s.Heading == "" {
+		return "(introduction)"
+	}
+	return s.Heading
+}
+
+// ConceptCoverage is the best-matching section for one concept.
+type ConceptCoverage struct {
+	Concept string  `json:"concept"`
+	Section string  `json:"section"`
+	Score   float64 `json:"score"`
+	Covered bool    `json:"covered"`
+}
+
+// CoverageReport lists how well an answer covers each concept.
+type CoverageReport struct {
+	Threshold float64           `json:"threshold"`
+	Concepts  []ConceptCoverage `json:"concepts"`
+}
+
+// CheckCoverage embeds the concepts and the answer's sections and matches each
+// concept to its most similar section.
+func CheckCoverage(ctx context.Context, service *SimilarityService, concepts []string, answer string, threshold float64) (*CoverageReport, error) {
+	if len(concepts) == 0 {
+		return nil, errors.New("no concepts to check")
+	}
+	sections := SplitSections(answer)
+	if len(sections) == 0 {
+		return nil, errors.New("answer is empty")
+	}
+
+	inputs := make([]string, 0, len(concepts)+len(sections))
+	for _, c := range concepts {
+		inputs = append(inputs, plainConcept(c))
+	}
+	for _, s := range sections {
+		inputs = append(inputs, s.text())
+	}
+	vecs, err := service.GetEmbeddings(ctx, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed concepts and sections: %w", err)
+	}
+	if len(vecs) != len(inputs) {
+		return nil, errors.New("not enough embeddings returned")
+	}
+
+	report := &CoverageReport{Threshold: threshold}
+	for i, concept := range concepts {
+		best := ConceptCoverage{Concept: concept, Score: -1}
+		for j, section := range sections {
+			if score := cosine(vecs[i], vecs[len(concepts)+j]); score > best.Score {
+				best.Score = score
+				best.Section = section.title()
+			}
+		}
+		best.Covered = best.Score >= threshold
+		report.Concepts = append(report.Concepts, best)
+	}
+	return report, nil
+}
+
+// Missing returns the concepts whose best score is below the threshold.
+func (r *CoverageReport) Missing() []string {
+	var missing []string
+	for _, c := range r.Concepts {
+		if !c.Covered {
+			missing = append(missing, c.Concept)
+		}
+	}
+	return missing
+}
+
+// String renders one line per concept, flagging the ones below the threshold.
+func (r *CoverageReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Concepts {
+		mark := "✓"
+		if !c.Covered {
+			mark = "✗"
+		}
+		fmt.Fprintf(&b, "%s %.2f  %s → %s\n", mark, c.Score, plainConcept(c.Concept), c.Section)
+	}
+	fmt.Fprintf(&b, "\n%d/%d concepts covered (threshold %.2f)\n", len(r.Concepts)-len(r.Missing()), len(r.Concepts), r.Threshold)
+	return b.String()
+}
+
+// CoveragePrompt asks the model for additional sections covering the missing concepts.
+func CoveragePrompt(prompt, answer string, missing []string) string {
+	return fmt.Sprintf("%s\n\nYou already wrote this answer:\n<<<\n%s\n>>>\n\n"+
+		"It does not cover these concepts:\n- %s\n\n"+
+		"Write only the additional markdown sections that cover them, matching the style, "+
+		"audience and formatting of the answer. Do not repeat existing content.",
+		strings.TrimSpace(prompt), strings.TrimSpace(answer), strings.Join(missing, "\n- "))
+}
+
+// plainConcept strips markdown emphasis, e.g. "**Commits**: ..." → "Commits: ...".
+func plainConcept(c string) string {
+	return strings.TrimSpace(strings.NewReplacer("**", "", "__", "", "`", "").Replace(c))
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keywordEmbedder maps text to a vector with one dimension per keyword it mentions.
+type keywordEmbedder struct{ keywords []string }
+
+func (k keywordEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		vec := make([]float32, len(k.keywords)+1)
+		vec[len(k.keywords)] = 0.1
+		for d, kw := range k.keywords {
+			if strings.Contains(strings.ToLower(in), kw) {
+				vec[d] = 1
+			}
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func TestSplitSections(t *testing.T) {
+	md := "Intro text\n\n# Git 101 #\nBasics\n\n```sh\n# not a heading\ngit init\n```\n## Branches\nParallel work\n"
+	sections := SplitSections(md)
+	require.Len(t, sections, 3)
+	assert.Equal(t, Section{Body: "Intro text"}, sections[0])
+	assert.Equal(t, "Git 101", sections[1].Heading)
+	assert.Contains(t, sections[1].Body, "# not a heading")
+	assert.Equal(t, Section{Heading: "Branches", Body: "Parallel work"}, sections[2])
+	assert.Equal(t, "(introduction)", sections[0].title())
+}
+
+func TestCheckCoverage(t *testing.T) {
+	service := NewSimilarityService(keywordEmbedder{keywords: []string{"commit", "branch", "merg"}})
+	answer := "# Commits\nA commit is a snapshot.\n\n# Branches\nA branch is a parallel path."
+	concepts := []string{"**Commits**: snapshots", "**Branches**: parallel paths", "**Merging**: combining paths"}
+
+	report, err := CheckCoverage(context.Background(), service, concepts, answer, 0.5)
+	require.NoError(t, err)
+	require.Len(t, report.Concepts, 3)
+	assert.Equal(t, "Commits", report.Concepts[0].Section)
+	assert.True(t, report.Concepts[0].Covered)
+	assert.Equal(t, "Branches", report.Concepts[1].Section)
+	assert.False(t, report.Concepts[2].Covered)
+	assert.Equal(t, []string{"**Merging**: combining paths"}, report.Missing())
+	assert.Contains(t, report.String(), "✗")
+	assert.Contains(t, report.String(), "Merging: combining paths")
+	assert.Contains(t, report.String(), "2/3 concepts covered")
+
+	_, err = CheckCoverage(context.Background(), service, nil, answer, 0.5)
+	assert.Error(t, err)
+}
+
+func TestCoveragePrompt(t *testing.T) {
+	p := CoveragePrompt("Explain Git", "# Commits", []string{"Merging", "Staging"})
+	assert.Contains(t, p, "Explain Git")
+	assert.Contains(t, p, "- Merging\n- Staging")
+}
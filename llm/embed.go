@@ -39,6 +39,25 @@ func (s *SimilarityService) Compare(ctx context.Context, a, b string) (float64,
 	return cosine(vecs[0], vecs[1]), nil
 }
 
+// Pairwise returns the cosine similarity matrix for the embeddings of inputs.
+func (s *SimilarityService) Pairwise(ctx context.Context, inputs []string) ([][]float64, error) {
+	vecs, err := s.GetEmbeddings(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(inputs) {
+		return nil, errors.New("not enough embeddings returned")
+	}
+	matrix := make([][]float64, len(vecs))
+	for i := range vecs {
+		matrix[i] = make([]float64, len(vecs))
+		for j := range vecs {
+			matrix[i][j] = cosine(vecs[i], vecs[j])
+		}
+	}
+	return matrix, nil
+}
+
 // cosine calculates cosine similarity between two vectors.
 func cosine(a, b []float32) float64 {
 	if len(a) != len(b) {
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// Judge scores on a 1..MaxRubricScore scale.
+const (
+	MinRubricScore = 1
+	MaxRubricScore = 5
+)
+
+// DefaultEvalThreshold is the mean score an answer needs to pass.
+const DefaultEvalThreshold = 3.5
+
+// RubricItem is one criterion an answer is judged against.
+type RubricItem struct {
+	ID        string `json:"id"`
+	Category  string `json:"category"`
+	Criterion string `json:"criterion"`
+}
+
+// Rubric is the set of criteria derived from a topic config.
+type Rubric struct {
+	Topic    string
+	Audience string
+	Items    []RubricItem
+}
+
+// BuildRubric turns the explanation requirements, constraints, output format
+// and tone of a topic config into rubric items.
+func BuildRubric(cfg promptConfig.TopicConfig) Rubric {
+	r := Rubric{Topic: cfg.Topic, Audience: cfg.Audience}
+	add := func(prefix, category string, criteria []string) {
+		for i, c := range criteria {
+			if c = strings.TrimSpace(c); c != "" {
+				r.Items = append(r.Items, RubricItem{ID: fmt.Sprintf("%s-%d", prefix, i+1), Category: category, Criterion: c})
+			}
+		}
+	}
+	add("requirement", "explanation requirement", cfg.ExplanationRequirements)
+	add("constraint", "constraint", cfg.Constraints)
+	add("format", "output format", cfg.OutputFormat)
+	if tone := strings.TrimSpace(cfg.Tone); tone != "" {
+		r.Items = append(r.Items, RubricItem{ID: "tone", Category: "tone", Criterion: "The tone is " + tone})
+	}
+	return r
+}
+
+// Schema returns the JSON Schema the judge's verdict must match: one score per rubric item.
+func (r Rubric) Schema() (*Schema, error) {
+	ids := make([]any, len(r.Items))
+	for i, item := range r.Items {
+		ids[i] = item.ID
+	}
+	return SchemaFromMap(map[string]any{
+		"type":     "object",
+		"required": []any{"scores"},
+		"properties": map[string]any{
+			"scores": map[string]any{
+				"type":     "array",
+				"minItems": len(r.Items),
+				"maxItems": len(r.Items),
+				"items": map[string]any{
+					"type":                 "object",
+					"required":             []any{"id", "score", "justification"},
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"id":            map[string]any{"type": "string", "enum": ids},
+						"score":         map[string]any{"type": "integer", "minimum": MinRubricScore, "maximum": MaxRubricScore},
+						"justification": map[string]any{"type": "string", "minLength": 1},
+					},
+				},
+			},
+		},
+	})
+}
+
+// Prompt asks the judge to score answer against every rubric item.
+func (r Rubric) Prompt(answer string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are a strict reviewer grading an explanation of %q written for %s.\n", r.Topic, r.Audience)
+	fmt.Fprintf(&b, "Score how well the answer meets each criterion from %d (not met) to %d (fully met), "+
+		"and justify each score in one or two sentences citing the answer.\n\nCriteria:\n", MinRubricScore, MaxRubricScore)
+	for _, item := range r.Items {
+		fmt.Fprintf(&b, "- [%s] (%s) %s\n", item.ID, item.Category, item.Criterion)
+	}
+	fmt.Fprintf(&b, "\nAnswer to grade:\n<<<\n%s\n>>>\n", strings.TrimSpace(answer))
+	return b.String()
+}
+
+// ItemScore is the judge's verdict on one rubric item.
+type ItemScore struct {
+	RubricItem
+	Score         int    `json:"score"`
+	Justification string `json:"justification"`
+}
+
+// Scorecard is the result of judging an answer.
+type Scorecard struct {
+	Answer    string      `json:"answer,omitempty"`
+	Judge     string      `json:"judge,omitempty"`
+	Items     []ItemScore `json:"items"`
+	Score     float64     `json:"score"` // Mean item score
+	Threshold float64     `json:"threshold"`
+	Passed    bool        `json:"passed"`
+}
+
+// JSONGenerator produces schema-validated JSON; *Client implements it.
+type JSONGenerator interface {
+	ChatJSON(ctx context.Context, prompt string, schema *Schema, maxRepairs int) (string, error)
+}
+
+// Evaluate asks judge to score answer against the rubric and returns the scorecard.
+func Evaluate(ctx context.Context, judge JSONGenerator, rubric Rubric, answer string, threshold float64, maxRepairs int) (*Scorecard, error) {
+	if len(rubric.Items) == 0 {
+		return nil, fmt.Errorf("rubric is empty: the topic config has no requirements, constraints, output format or tone")
+	}
+	schema, err := rubric.Schema()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := judge.ChatJSON(ctx, rubric.Prompt(answer), schema, maxRepairs)
+	if err != nil {
+		return nil, fmt.Errorf("judge failed: %w", err)
+	}
+
+	var verdict struct {
+		Scores []struct {
+			ID            string `json:"id"`
+			Score         int    `json:"score"`
+			Justification string `json:"justification"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(doc), &verdict); err != nil {
+		return nil, fmt.Errorf("invalid judge verdict: %w", err)
+	}
+	byID := map[string]int{}
+	for i, s := range verdict.Scores {
+		byID[s.ID] = i
+	}
+
+	card := &Scorecard{Threshold: threshold}
+	total := 0
+	for _, item := range rubric.Items {
+		i, ok := byID[item.ID]
+		if !ok {
+			return nil, fmt.Errorf("judge did not score rubric item %s", item.ID)
+		}
+		s := verdict.Scores[i]
+		card.Items = append(card.Items, ItemScore{RubricItem: item, Score: s.Score, Justification: s.Justification})
+		total += s.Score
+	}
+	card.Score = float64(total) / float64(len(card.Items))
+	card.Passed = card.Score >= threshold
+	return card, nil
+}
+
+// String renders the scorecard as a plain-text table.
+func (c *Scorecard) String() string {
+	var b strings.Builder
+	for _, item := range c.Items {
+		fmt.Fprintf(&b, "%-14s %d/%d  %s\n", item.ID, item.Score, MaxRubricScore, item.Criterion)
+		fmt.Fprintf(&b, "%-14s       %s\n", "", item.Justification)
+	}
+	verdict := "PASS"
+	if !c.Passed {
+		verdict = "FAIL"
+	}
+	fmt.Fprintf(&b, "\nScore: %.2f/%d (threshold %.2f) %s\n", c.Score, MaxRubricScore, c.Threshold, verdict)
+	return b.String()
+}
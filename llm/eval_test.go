@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// scriptedJudge validates its canned verdict against the requested schema, like ChatJSON.
+type scriptedJudge struct {
+	verdict string
+	prompt  string
+}
+
+func (j *scriptedJudge) ChatJSON(_ context.Context, prompt string, schema *Schema, _ int) (string, error) {
+	j.prompt = prompt
+	if errs := schema.Validate([]byte(j.verdict)); len(errs) > 0 {
+		return "", &SchemaValidationError{Errors: errs, Response: j.verdict}
+	}
+	return j.verdict, nil
+}
+
+func testRubric() Rubric {
+	return BuildRubric(promptConfig.TopicConfig{
+		Topic:                   "Git",
+		Audience:                "students",
+		ExplanationRequirements: []string{"Compare Git to a road trip", " "},
+		Constraints:             []string{"Avoid jargon"},
+		OutputFormat:            []string{"Start with a catchy title"},
+		Tone:                    "friendly",
+	})
+}
+
+func verdictJSON(t *testing.T, scores map[string]int) string {
+	t.Helper()
+	var items []map[string]any
+	for _, id := range []string{"requirement-1", "constraint-1", "format-1", "tone"} {
+		if s, ok := scores[id]; ok {
+			items = append(items, map[string]any{"id": id, "score": s, "justification": "because"})
+		}
+	}
+	data, err := json.Marshal(map[string]any{"scores": items})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestBuildRubric(t *testing.T) {
+	r := testRubric()
+	require.Len(t, r.Items, 4)
+	assert.Equal(t, RubricItem{ID: "requirement-1", Category: "explanation requirement", Criterion: "Compare Git to a road trip"}, r.Items[0])
+	assert.Equal(t, "constraint-1", r.Items[1].ID)
+	assert.Equal(t, "format-1", r.Items[2].ID)
+	assert.Equal(t, "The tone is friendly", r.Items[3].Criterion)
+}
+
+func TestEvaluate(t *testing.T) {
+	judge := &scriptedJudge{verdict: verdictJSON(t, map[string]int{"requirement-1": 5, "constraint-1": 4, "format-1": 3, "tone": 4})}
+
+	card, err := Evaluate(context.Background(), judge, testRubric(), "# Git Road Trip", 3.5, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 4.0, card.Score, 1e-9)
+	assert.True(t, card.Passed)
+	assert.Equal(t, "because", card.Items[2].Justification)
+	assert.Contains(t, judge.prompt, "[constraint-1] (constraint) Avoid jargon")
+	assert.Contains(t, judge.prompt, "# Git Road Trip")
+	assert.Contains(t, card.String(), "Score: 4.00/5 (threshold 3.50) PASS")
+
+	card, err = Evaluate(context.Background(), judge, testRubric(), "answer", 4.5, 0)
+	require.NoError(t, err)
+	assert.False(t, card.Passed)
+	assert.True(t, strings.HasSuffix(card.String(), "FAIL\n"))
+}
+
+func TestEvaluate_RejectsIncompleteVerdict(t *testing.T) {
+	judge := &scriptedJudge{verdict: verdictJSON(t, map[string]int{"requirement-1": 5, "tone": 9})}
+	_, err := Evaluate(context.Background(), judge, testRubric(), "answer", 3.5, 0)
+	var verr *SchemaValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Contains(t, strings.Join(verr.Errors, "\n"), "expected at least 4 items")
+	assert.Contains(t, strings.Join(verr.Errors, "\n"), "greater than maximum 5")
+
+	_, err = Evaluate(context.Background(), judge, Rubric{}, "answer", 3.5, 0)
+	assert.ErrorContains(t, err, "rubric is empty")
+}
@@ -3,6 +3,10 @@ package llm
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
 )
 
 // StreamHandler defines a function signature for streaming chunk processing
@@ -10,6 +14,118 @@ type StreamHandler func(ctx context.Context, chunk []byte) error
 
 // defaultStreamHandler prints output chunks to stdout
 func defaultStreamHandler(ctx context.Context, chunk []byte) error {
-	fmt.Print(string(chunk))
+	_, err := os.Stdout.Write(chunk)
+	return err
+}
+
+// WriterStreamHandler writes every chunk to w as it arrives.
+func WriterStreamHandler(w io.Writer) StreamHandler {
+	return func(_ context.Context, chunk []byte) error {
+		_, err := w.Write(chunk)
+		return err
+	}
+}
+
+// TeeStreamHandler passes every chunk to each handler in order, stopping at the first error.
+func TeeStreamHandler(handlers ...StreamHandler) StreamHandler {
+	return func(ctx context.Context, chunk []byte) error {
+		for _, h := range handlers {
+			if err := h(ctx, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// FileStream appends chunks to a file as they arrive, so a long answer is on
+// disk even if the call never completes.
+type FileStream struct {
+	f *os.File
+}
+
+// CreateFileStream truncates or creates the file at path.
+func CreateFileStream(path string) (*FileStream, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating stream file '%s': %w", path, err)
+	}
+	return &FileStream{f: f}, nil
+}
+
+// Handle is the StreamHandler writing to the file.
+func (s *FileStream) Handle(_ context.Context, chunk []byte) error {
+	_, err := s.f.Write(chunk)
+	return err
+}
+
+// Close closes the file.
+func (s *FileStream) Close() error {
+	return s.f.Close()
+}
+
+// RateMeter measures streaming throughput.
+type RateMeter struct {
+	mu     sync.Mutex
+	start  time.Time
+	first  time.Time
+	last   time.Time
+	chunks int
+	text   []byte
+}
+
+// RateStats summarizes a stream measured by a RateMeter.
+type RateStats struct {
+	Chunks          int
+	Tokens          int           // Estimated from the streamed text
+	FirstChunk      time.Duration // From the meter's creation to the first chunk
+	Elapsed         time.Duration // From the meter's creation to the last chunk
+	TokensPerSecond float64       // Generation rate between the first and last chunk
+}
+
+// NewRateMeter starts a meter; create it just before the call it measures.
+func NewRateMeter() *RateMeter {
+	return &RateMeter{start: time.Now()}
+}
+
+// Handle is the StreamHandler recording each chunk.
+func (m *RateMeter) Handle(_ context.Context, chunk []byte) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.chunks == 0 {
+		m.first = now
+	}
+	m.last = now
+	m.chunks++
+	m.text = append(m.text, chunk...)
 	return nil
 }
+
+// Stats returns the throughput measured so far.
+func (m *RateMeter) Stats() RateStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.chunks == 0 {
+		return RateStats{}
+	}
+	s := RateStats{
+		Chunks:     m.chunks,
+		Tokens:     EstimateTokens(string(m.text)),
+		FirstChunk: m.first.Sub(m.start),
+		Elapsed:    m.last.Sub(m.start),
+	}
+	window := m.last.Sub(m.first)
+	if window <= 0 {
+		window = s.Elapsed
+	}
+	if window > 0 {
+		s.TokensPerSecond = float64(s.Tokens) / window.Seconds()
+	}
+	return s
+}
+
+func (s RateStats) String() string {
+	return fmt.Sprintf("~%d tokens in %d chunks, first after %s, %.1f tok/s",
+		s.Tokens, s.Chunks, s.FirstChunk.Round(time.Millisecond), s.TokensPerSecond)
+}
@@ -3,8 +3,11 @@ package llm
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultStreamHandler(t *testing.T) {
@@ -47,3 +50,68 @@ func TestDefaultStreamHandler(t *testing.T) {
 		t.Errorf("Expected output %q, got %q", expected, output)
 	}
 }
+
+func TestTeeStreamHandler_WriterAndFile(t *testing.T) {
+	var buf bytes.Buffer
+	path := t.TempDir() + "/answer.md"
+	file, err := CreateFileStream(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := TeeStreamHandler(WriterStreamHandler(&buf), file.Handle)
+	for _, chunk := range []string{"Hello, ", "stream!"} {
+		if err := handler(context.Background(), []byte(chunk)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The file is written incrementally, before Close.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Hello, stream!" || buf.String() != "Hello, stream!" {
+		t.Errorf("expected both sinks to hold the stream, got file %q and writer %q", data, buf.String())
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTeeStreamHandler_StopsAtFirstError(t *testing.T) {
+	calls := 0
+	failing := func(context.Context, []byte) error { calls++; return errors.New("disk full") }
+	counting := func(context.Context, []byte) error { calls++; return nil }
+
+	err := TeeStreamHandler(failing, counting)(context.Background(), []byte("x"))
+	if err == nil || calls != 1 {
+		t.Errorf("expected the first error to stop the tee, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRateMeter(t *testing.T) {
+	meter := NewRateMeter()
+	if stats := meter.Stats(); stats.Chunks != 0 {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	_ = meter.Handle(context.Background(), []byte("one two three "))
+	time.Sleep(10 * time.Millisecond)
+	_ = meter.Handle(context.Background(), []byte("four five six"))
+
+	stats := meter.Stats()
+	if stats.Chunks != 2 || stats.Tokens == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.FirstChunk < 5*time.Millisecond || stats.Elapsed < stats.FirstChunk {
+		t.Errorf("unexpected timings: %+v", stats)
+	}
+	if stats.TokensPerSecond <= 0 {
+		t.Errorf("expected a positive rate, got %v", stats.TokensPerSecond)
+	}
+	if !strings.Contains(stats.String(), "tok/s") {
+		t.Errorf("unexpected summary %q", stats.String())
+	}
+}
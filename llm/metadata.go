@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ResponseMetadata describes how a saved response was produced. It is written
+// next to the response as <name>.meta.json.
+type ResponseMetadata struct {
+	Provider      string     `json:"provider"`
+	Model         string     `json:"model"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Partial       bool       `json:"partial"` // The call was interrupted; the response is incomplete
+	StopReason    string     `json:"stop_reason,omitempty"`
+	LatencyMS     int64      `json:"latency_ms,omitempty"`
+	FirstTokenMS  int64      `json:"first_token_ms,omitempty"`
+	Continuations int        `json:"continuations,omitempty"` // Follow-up requests after length stops
+	Attachments   []string   `json:"attachments,omitempty"`   // Files sent with the prompt
+	ToolCalls     []ToolCall `json:"tool_calls,omitempty"`    // Tools the model ran, in order
+	Usage         *Usage     `json:"usage,omitempty"`
+}
+
+// NewResponseMetadata fills in the metadata of a completed response; resp may be nil.
+func NewResponseMetadata(target Target, resp *Response) ResponseMetadata {
+	meta := ResponseMetadata{Provider: target.Provider, Model: target.Model, CreatedAt: time.Now().UTC()}
+	if resp != nil {
+		meta.StopReason = resp.StopReason
+		meta.LatencyMS = resp.Latency.Milliseconds()
+		meta.FirstTokenMS = resp.FirstToken.Milliseconds()
+		meta.Continuations = resp.Continuations
+		meta.ToolCalls = resp.ToolCalls
+		usage := resp.Usage
+		meta.Usage = &usage
+	}
+	return meta
+}
+
+// MetadataPath returns the metadata file for a response file: answer.md → answer.meta.json.
+func MetadataPath(responsePath string) string {
+	return strings.TrimSuffix(responsePath, filepath.Ext(responsePath)) + ".meta.json"
+}
+
+// SaveMetadata writes meta next to the response at responsePath.
+func SaveMetadata(responsePath string, meta ResponseMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := MetadataPath(responsePath)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing metadata '%s': %w", path, err)
+	}
+	return nil
+}
+
+// LoadMetadata reads the metadata saved next to the response at responsePath.
+func LoadMetadata(responsePath string) (ResponseMetadata, error) {
+	var meta ResponseMetadata
+	data, err := os.ReadFile(MetadataPath(responsePath))
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
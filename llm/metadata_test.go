@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataPath(t *testing.T) {
+	assert.Equal(t, "out/answer.meta.json", MetadataPath("out/answer.md"))
+	assert.Equal(t, "response.meta.json", MetadataPath("response"))
+}
+
+func TestSaveMetadata_roundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+	resp := &Response{Text: "hi", StopReason: "stop", Latency: 1500 * time.Millisecond, Usage: Usage{PromptTokens: 3, CompletionTokens: 1}}
+	meta := NewResponseMetadata(Target{Provider: "echo", Model: "m"}, resp)
+	meta.Partial = true
+
+	require.NoError(t, SaveMetadata(path, meta))
+	got, err := LoadMetadata(path)
+	require.NoError(t, err)
+	assert.True(t, got.Partial)
+	assert.Equal(t, "echo", got.Provider)
+	assert.Equal(t, "stop", got.StopReason)
+	assert.EqualValues(t, 1500, got.LatencyMS)
+	require.NotNil(t, got.Usage)
+	assert.Equal(t, 3, got.Usage.PromptTokens)
+}
+
+func TestNewResponseMetadata_withoutResponse(t *testing.T) {
+	meta := NewResponseMetadata(Target{Provider: "openai", Model: "gpt-4o"}, nil)
+	assert.Nil(t, meta.Usage)
+	assert.False(t, meta.CreatedAt.IsZero())
+}
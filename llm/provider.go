@@ -2,6 +2,7 @@ package llm
 
 import (
 	"fmt"
+	"sync"
 
 	"raja.aiml/ai.explorer/config/llm"
 	langchainWrapper "raja.aiml/ai.explorer/llm/wrapper"
@@ -9,10 +10,55 @@ import (
 
 // initLLMProvider initializes the LLM model using the langchaingo wrapper.
 func InitLLMProvider(cfg llm.Config) (langchainWrapper.Model, error) {
-	provider := &langchainWrapper.LangchaingoProvider{}
+	provider := &langchainWrapper.LangchaingoProvider{Options: cfg.Model.Options}
 	model, err := provider.Init(cfg.Provider, cfg.Model.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM provider: %w", err)
 	}
 	return model, nil
 }
+
+// Provider types accepted in config-declared providers.
+const (
+	ProviderTypeOpenAICompatible = "openai-compatible"
+	ProviderTypeOllama           = "ollama"
+)
+
+var (
+	providerTypesMu sync.RWMutex
+	providerTypes   = map[string]string{"ollama": ProviderTypeOllama, "openai": "openai", "fake": "fake", "echo": "echo"}
+)
+
+// ProviderType returns the type of the provider registered under name: its
+// own name for built-in providers, or the type a config declared it with.
+func ProviderType(name string) string {
+	providerTypesMu.RLock()
+	defer providerTypesMu.RUnlock()
+	return providerTypes[name]
+}
+
+// RegisterConfiguredProviders adds every provider declared in cfg.Providers to the registry.
+func RegisterConfiguredProviders(cfg llm.Config) error {
+	for _, p := range cfg.Providers {
+		if p.Name == "" {
+			return fmt.Errorf("provider declared without a name")
+		}
+		switch p.Type {
+		case ProviderTypeOpenAICompatible:
+			langchainWrapper.RegisterProvider(p.Name, langchainWrapper.NewOpenAICompatibleFactory(langchainWrapper.OpenAICompatibleConfig{
+				BaseURL:      p.BaseURL,
+				APIKeyEnv:    p.APIKeyEnv,
+				Organization: p.Organization,
+				Headers:      p.Headers,
+			}))
+		case ProviderTypeOllama:
+			langchainWrapper.RegisterProvider(p.Name, langchainWrapper.NewOllamaFactory(p.BaseURL, p.Headers))
+		default:
+			return fmt.Errorf("provider %q has unsupported type %q", p.Name, p.Type)
+		}
+		providerTypesMu.Lock()
+		providerTypes[p.Name] = p.Type
+		providerTypesMu.Unlock()
+	}
+	return nil
+}
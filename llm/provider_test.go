@@ -83,3 +83,34 @@ func containsError(err error, expected string) bool {
 	}
 	return false
 }
+
+func TestRegisterConfiguredProviders(t *testing.T) {
+	cfg := llmConfig.Config{
+		Providers: []llmConfig.ProviderConfig{
+			{Name: "local-vllm", Type: ProviderTypeOpenAICompatible, BaseURL: "http://localhost:8000/v1"},
+			{Name: "gpu-ollama", Type: ProviderTypeOllama, BaseURL: "http://gpu-box:11434"},
+		},
+	}
+	if err := RegisterConfiguredProviders(cfg); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, name := range []string{"local-vllm", "gpu-ollama"} {
+		model, err := InitLLMProvider(llmConfig.Config{Provider: name, Model: llmConfig.ModelConfig{Name: "m"}})
+		if err != nil {
+			t.Fatalf("Expected provider %q to initialize, got: %v", name, err)
+		}
+		if model == nil {
+			t.Fatalf("Expected non-nil model for provider %q", name)
+		}
+	}
+}
+
+func TestRegisterConfiguredProviders_InvalidType(t *testing.T) {
+	cfg := llmConfig.Config{
+		Providers: []llmConfig.ProviderConfig{{Name: "bad", Type: "carrier-pigeon"}},
+	}
+	if err := RegisterConfiguredProviders(cfg); err == nil {
+		t.Fatal("Expected error for unsupported provider type, got nil")
+	}
+}
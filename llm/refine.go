@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// ---------- Self-refinement ----------
+
+// CritiqueIssue is one rule a draft breaks.
+type CritiqueIssue struct {
+	Rule    string `json:"rule"`
+	Problem string `json:"problem"`
+}
+
+// Critique is a critic's verdict on a draft.
+type Critique struct {
+	Approved bool            `json:"approved"`
+	Issues   []CritiqueIssue `json:"issues"`
+}
+
+// RefineRound is one critique of a draft.
+type RefineRound struct {
+	Round    int
+	Draft    string
+	Critique Critique
+}
+
+// Refiner has a critic check drafts against a topic's rules and a writer revise
+// them, until the critic approves or the rounds run out.
+type Refiner struct {
+	Critic     JSONGenerator                       // Checks drafts against Rules
+	Revise     func(prompt string) (string, error) // Writes the revisions
+	Rules      []string
+	Rounds     int               // Revisions allowed
+	MaxRepairs int               // Re-prompts allowed when a critique fails validation
+	OnCritique func(RefineRound) // Called after each critique, before the revision
+}
+
+// RefineRules returns the constraints, formatting and output format rules of a topic config.
+func RefineRules(cfg promptConfig.TopicConfig) []string {
+	var rules []string
+	for _, group := range [][]string{cfg.Constraints, cfg.Formatting, cfg.OutputFormat} {
+		for _, rule := range group {
+			if rule = strings.TrimSpace(rule); rule != "" {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// Refine critiques and revises draft, the answer to prompt, and returns the
+// latest draft with the rounds run. On error, the latest complete draft is
+// still returned.
+func (r *Refiner) Refine(ctx context.Context, prompt, draft string) (string, []RefineRound, error) {
+	if len(r.Rules) == 0 {
+		return draft, nil, errors.New("nothing to check: the topic config has no constraints, formatting or output format rules")
+	}
+	schema, err := SchemaFromMap(critiqueSchema)
+	if err != nil {
+		return draft, nil, err
+	}
+
+	var rounds []RefineRound
+	for round := 1; round <= r.Rounds; round++ {
+		doc, err := r.Critic.ChatJSON(ctx, CritiquePrompt(r.Rules, draft), schema, r.MaxRepairs)
+		if err != nil {
+			return draft, rounds, fmt.Errorf("critic failed: %w", err)
+		}
+		var c Critique
+		if err := json.Unmarshal([]byte(doc), &c); err != nil {
+			return draft, rounds, fmt.Errorf("invalid critique: %w", err)
+		}
+		if len(c.Issues) == 0 {
+			c.Approved = true
+		}
+		rounds = append(rounds, RefineRound{Round: round, Draft: draft, Critique: c})
+		if r.OnCritique != nil {
+			r.OnCritique(rounds[len(rounds)-1])
+		}
+		if c.Approved {
+			break
+		}
+
+		revision, err := r.Revise(RevisionPrompt(prompt, draft, c))
+		if err != nil {
+			return draft, rounds, err
+		}
+		draft = revision
+	}
+	return draft, rounds, nil
+}
+
+// critiqueSchema is the JSON Schema a critique must match.
+var critiqueSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"approved", "issues"},
+	"properties": map[string]any{
+		"approved": map[string]any{"type": "boolean"},
+		"issues": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":                 "object",
+				"required":             []any{"rule", "problem"},
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"rule":    map[string]any{"type": "string", "minLength": 1},
+					"problem": map[string]any{"type": "string", "minLength": 1},
+				},
+			},
+		},
+	},
+}
+
+// CritiquePrompt asks the critic which rules the draft breaks.
+func CritiquePrompt(rules []string, draft string) string {
+	var b strings.Builder
+	b.WriteString("You are a strict editor checking a draft against the rules it had to follow.\n")
+	b.WriteString("List every rule the draft breaks, naming the rule and describing the problem with a short quote from the draft. " +
+		"Approve the draft only if it follows every rule; do not list style preferences that no rule asks for.\n\nRules:\n")
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "- %s\n", rule)
+	}
+	fmt.Fprintf(&b, "\nDraft:\n<<<\n%s\n>>>\n", strings.TrimSpace(draft))
+	return b.String()
+}
+
+// RevisionPrompt asks for a revision of draft that fixes the critique's issues.
+func RevisionPrompt(prompt, draft string, c Critique) string {
+	var issues strings.Builder
+	for _, issue := range c.Issues {
+		fmt.Fprintf(&issues, "- %s: %s\n", issue.Rule, issue.Problem)
+	}
+	return fmt.Sprintf("%s\n\nYou already wrote this draft:\n<<<\n%s\n>>>\n\n"+
+		"An editor found these problems:\n%s\n"+
+		"Rewrite the complete answer so it fixes every problem, keeping everything that was already right. "+
+		"Reply with the revised answer only.",
+		strings.TrimSpace(prompt), strings.TrimSpace(draft), issues.String())
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// critiqueSequence returns its critiques in turn, validating each like ChatJSON.
+type critiqueSequence struct {
+	critiques []string
+	prompts   []string
+}
+
+func (c *critiqueSequence) ChatJSON(_ context.Context, prompt string, schema *Schema, _ int) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	doc := c.critiques[min(len(c.prompts), len(c.critiques))-1]
+	if errs := schema.Validate([]byte(doc)); len(errs) > 0 {
+		return "", &SchemaValidationError{Errors: errs, Response: doc}
+	}
+	return doc, nil
+}
+
+const (
+	rejectCritique  = `{"approved": false, "issues": [{"rule": "Keep paragraphs short", "problem": "The intro is one long paragraph."}]}`
+	approveCritique = `{"approved": true, "issues": []}`
+)
+
+// numberedRevisions returns "draft 2", "draft 3", ... and records the revision prompts.
+func numberedRevisions(prompts *[]string) func(string) (string, error) {
+	return func(prompt string) (string, error) {
+		*prompts = append(*prompts, prompt)
+		return fmt.Sprintf("draft %d", len(*prompts)+1), nil
+	}
+}
+
+func TestRefineRules(t *testing.T) {
+	rules := RefineRules(promptConfig.TopicConfig{
+		Constraints:             []string{"Keep paragraphs short", " "},
+		Formatting:              []string{"Use bullet points"},
+		OutputFormat:            []string{"Start with a catchy title"},
+		ExplanationRequirements: []string{"Not a rule"},
+	})
+	assert.Equal(t, []string{"Keep paragraphs short", "Use bullet points", "Start with a catchy title"}, rules)
+}
+
+func TestRefine_stopsWhenApproved(t *testing.T) {
+	critic := &critiqueSequence{critiques: []string{rejectCritique, approveCritique}}
+	var revisions []string
+	var seen []RefineRound
+	r := &Refiner{
+		Critic:     critic,
+		Revise:     numberedRevisions(&revisions),
+		Rules:      []string{"Keep paragraphs short"},
+		Rounds:     3,
+		OnCritique: func(round RefineRound) { seen = append(seen, round) },
+	}
+
+	final, rounds, err := r.Refine(context.Background(), "Explain Git", "draft 1")
+	require.NoError(t, err)
+	assert.Equal(t, "draft 2", final)
+	require.Len(t, rounds, 2)
+	assert.Equal(t, rounds, seen)
+	assert.False(t, rounds[0].Critique.Approved)
+	assert.Equal(t, "draft 2", rounds[1].Draft)
+	assert.True(t, rounds[1].Critique.Approved)
+
+	assert.Contains(t, critic.prompts[0], "- Keep paragraphs short\n")
+	assert.Contains(t, critic.prompts[0], "<<<\ndraft 1\n>>>")
+	require.Len(t, revisions, 1)
+	assert.Contains(t, revisions[0], "Explain Git")
+	assert.Contains(t, revisions[0], "- Keep paragraphs short: The intro is one long paragraph.")
+}
+
+func TestRefine_stopsAfterRounds(t *testing.T) {
+	var revisions []string
+	r := &Refiner{
+		Critic: &critiqueSequence{critiques: []string{rejectCritique}},
+		Revise: numberedRevisions(&revisions),
+		Rules:  []string{"Keep paragraphs short"},
+		Rounds: 2,
+	}
+	final, rounds, err := r.Refine(context.Background(), "Explain Git", "draft 1")
+	require.NoError(t, err)
+	assert.Equal(t, "draft 3", final)
+	assert.Len(t, rounds, 2)
+	assert.Len(t, revisions, 2)
+}
+
+func TestRefine_issuesDecideApproval(t *testing.T) {
+	r := &Refiner{
+		Critic: &critiqueSequence{critiques: []string{`{"approved": false, "issues": []}`}},
+		Revise: func(string) (string, error) { return "", errors.New("should not revise") },
+		Rules:  []string{"Keep paragraphs short"},
+		Rounds: 2,
+	}
+	final, rounds, err := r.Refine(context.Background(), "Explain Git", "draft 1")
+	require.NoError(t, err)
+	assert.Equal(t, "draft 1", final)
+	require.Len(t, rounds, 1)
+	assert.True(t, rounds[0].Critique.Approved)
+}
+
+func TestRefine_errors(t *testing.T) {
+	_, _, err := (&Refiner{Rounds: 1}).Refine(context.Background(), "p", "draft 1")
+	assert.ErrorContains(t, err, "no constraints, formatting or output format rules")
+
+	r := &Refiner{
+		Critic: &critiqueSequence{critiques: []string{rejectCritique}},
+		Revise: func(string) (string, error) { return "", context.Canceled },
+		Rules:  []string{"Keep paragraphs short"},
+		Rounds: 2,
+	}
+	final, rounds, err := r.Refine(context.Background(), "p", "draft 1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "draft 1", final)
+	assert.Len(t, rounds, 1)
+
+	r.Critic = &critiqueSequence{critiques: []string{`{"approved": "yes"}`}}
+	_, _, err = r.Refine(context.Background(), "p", "draft 1")
+	assert.ErrorContains(t, err, "critic failed")
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// ---------- Self-consistency sampling ----------
+
+// Sample is one of several answers generated for the same prompt.
+type Sample struct {
+	Index      int       `json:"index"` // 1-based, in request order
+	Text       string    `json:"-"`
+	Response   *Response `json:"-"`
+	Similarity float64   `json:"similarity"` // Cosine similarity to the centroid of all samples
+	Error      string    `json:"error,omitempty"`
+}
+
+// Sampling is the outcome of sampling a prompt several times.
+type Sampling struct {
+	Samples []Sample `json:"samples"`
+	Chosen  int      `json:"chosen"`           // Index of the chosen sample
+	Method  string   `json:"method"`           // "centroid", "judge" or "first"
+	Reason  string   `json:"reason,omitempty"` // Why the judge picked the chosen sample
+}
+
+// Selection methods.
+const (
+	PickCentroid = "centroid"
+	PickJudge    = "judge"
+	PickFirst    = "first" // Used when the samples could not be compared
+)
+
+// GenerateSamples sends prompt n times concurrently. Failures are recorded per
+// sample; it returns an error only when every sample failed.
+func GenerateSamples(ctx context.Context, gen Generator, prompt string, n int) (*Sampling, error) {
+	samples := make([]Sample, n)
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := Sample{Index: i + 1}
+			if resp, err := gen.Generate(ctx, prompt); err != nil {
+				s.Error = err.Error()
+			} else {
+				s.Text, s.Response = resp.Text, resp
+			}
+			samples[i] = s
+		}(i)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sampling := &Sampling{Samples: samples, Method: PickFirst}
+	ok := sampling.Succeeded()
+	if len(ok) == 0 {
+		return nil, fmt.Errorf("all %d samples failed: %s", n, samples[0].Error)
+	}
+	sampling.Chosen = ok[0].Index
+	return sampling, nil
+}
+
+// Succeeded returns the samples that produced an answer.
+func (s *Sampling) Succeeded() []Sample {
+	var ok []Sample
+	for _, sample := range s.Samples {
+		if sample.Error == "" {
+			ok = append(ok, sample)
+		}
+	}
+	return ok
+}
+
+// Best returns the chosen sample.
+func (s *Sampling) Best() Sample {
+	return s.Samples[s.Chosen-1]
+}
+
+// AddSimilarity scores each answer by its similarity to the centroid of all
+// answers and chooses the most central one.
+func (s *Sampling) AddSimilarity(ctx context.Context, service *SimilarityService) error {
+	ok := s.Succeeded()
+	if len(ok) < 2 {
+		return errors.New("need at least two successful samples to compare")
+	}
+	texts := make([]string, len(ok))
+	for i, sample := range ok {
+		texts[i] = sample.Text
+	}
+	vecs, err := service.GetEmbeddings(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vecs) != len(texts) {
+		return errors.New("not enough embeddings returned")
+	}
+
+	centroid := make([]float32, len(vecs[0]))
+	for _, v := range vecs {
+		norm := math.Sqrt(dot(v, v))
+		if norm == 0 || len(v) != len(centroid) {
+			continue
+		}
+		for i := range v {
+			centroid[i] += float32(float64(v[i]) / norm)
+		}
+	}
+
+	best := -2.0
+	for i, sample := range ok {
+		score := cosine(vecs[i], centroid)
+		s.Samples[sample.Index-1].Similarity = score
+		if score > best {
+			best, s.Chosen = score, sample.Index
+		}
+	}
+	s.Method = PickCentroid
+	return nil
+}
+
+// PickWithJudge asks judge for the best answer to prompt and chooses it.
+func (s *Sampling) PickWithJudge(ctx context.Context, judge JSONGenerator, prompt string, maxRepairs int) error {
+	ok := s.Succeeded()
+	if len(ok) < 2 {
+		return errors.New("need at least two successful samples to compare")
+	}
+	indexes := make([]any, len(ok))
+	for i, sample := range ok {
+		indexes[i] = sample.Index
+	}
+	schema, err := SchemaFromMap(map[string]any{
+		"type":     "object",
+		"required": []any{"best", "reason"},
+		"properties": map[string]any{
+			"best":   map[string]any{"type": "integer", "enum": indexes},
+			"reason": map[string]any{"type": "string", "minLength": 1},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	doc, err := judge.ChatJSON(ctx, PickPrompt(prompt, ok), schema, maxRepairs)
+	if err != nil {
+		return fmt.Errorf("judge failed: %w", err)
+	}
+	var verdict struct {
+		Best   int    `json:"best"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(doc), &verdict); err != nil {
+		return fmt.Errorf("invalid judge verdict: %w", err)
+	}
+	s.Chosen, s.Reason, s.Method = verdict.Best, verdict.Reason, PickJudge
+	return nil
+}
+
+// PickPrompt asks a judge to choose the best of several answers to prompt.
+func PickPrompt(prompt string, samples []Sample) string {
+	var b strings.Builder
+	b.WriteString("You are a strict reviewer. Several answers were written for the prompt below. " +
+		"Pick the one that best follows the prompt's instructions and is most accurate and clear, " +
+		"and explain your pick in one or two sentences.\n\n")
+	fmt.Fprintf(&b, "Prompt:\n<<<\n%s\n>>>\n", strings.TrimSpace(prompt))
+	for _, sample := range samples {
+		fmt.Fprintf(&b, "\nAnswer %d:\n<<<\n%s\n>>>\n", sample.Index, strings.TrimSpace(sample.Text))
+	}
+	return b.String()
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingGenerator counts its calls and fails the ones numbered in fail.
+type countingGenerator struct {
+	calls atomic.Int32
+	fail  map[int32]bool
+}
+
+func (g *countingGenerator) Generate(context.Context, string) (*Response, error) {
+	n := g.calls.Add(1)
+	if g.fail[n] {
+		return nil, errors.New("503 service unavailable")
+	}
+	return &Response{Text: "answer"}, nil
+}
+
+func TestGenerateSamples(t *testing.T) {
+	gen := &countingGenerator{fail: map[int32]bool{2: true}}
+	sampling, err := GenerateSamples(context.Background(), gen, "Explain Git", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), gen.calls.Load())
+	require.Len(t, sampling.Samples, 3)
+	assert.Len(t, sampling.Succeeded(), 2)
+	assert.Equal(t, PickFirst, sampling.Method)
+	assert.Equal(t, "answer", sampling.Best().Text)
+
+	_, err = GenerateSamples(context.Background(), &countingGenerator{fail: map[int32]bool{1: true, 2: true}}, "Explain Git", 2)
+	assert.ErrorContains(t, err, "all 2 samples failed")
+}
+
+func testSampling(texts ...string) *Sampling {
+	s := &Sampling{Chosen: 1, Method: PickFirst}
+	for i, text := range texts {
+		s.Samples = append(s.Samples, Sample{Index: i + 1, Text: text})
+	}
+	return s
+}
+
+func TestSampling_AddSimilarity_choosesCentralAnswer(t *testing.T) {
+	s := testSampling("outlier", "typical", "close")
+	s.Samples = append(s.Samples, Sample{Index: 4, Error: "timeout"})
+	embedder := &mockEmbedder{output: [][]float32{{0, 1}, {1, 0.1}, {1, -0.3}}}
+
+	require.NoError(t, s.AddSimilarity(context.Background(), NewSimilarityService(embedder)))
+	assert.Equal(t, PickCentroid, s.Method)
+	assert.Equal(t, 2, s.Chosen)
+	assert.Greater(t, s.Samples[1].Similarity, s.Samples[2].Similarity)
+	assert.Greater(t, s.Samples[2].Similarity, s.Samples[0].Similarity)
+	assert.Zero(t, s.Samples[3].Similarity)
+
+	err := testSampling("only").AddSimilarity(context.Background(), NewSimilarityService(embedder))
+	assert.ErrorContains(t, err, "at least two")
+}
+
+func TestSampling_PickWithJudge(t *testing.T) {
+	s := testSampling("first", "second")
+	judge := &scriptedJudge{verdict: `{"best": 2, "reason": "Shorter paragraphs."}`}
+	require.NoError(t, s.PickWithJudge(context.Background(), judge, "Explain Git", 0))
+	assert.Equal(t, 2, s.Chosen)
+	assert.Equal(t, PickJudge, s.Method)
+	assert.Equal(t, "Shorter paragraphs.", s.Reason)
+	assert.True(t, strings.Contains(judge.prompt, "Answer 2:\n<<<\nsecond\n>>>"))
+
+	s = testSampling("first", "second")
+	err := s.PickWithJudge(context.Background(), &scriptedJudge{verdict: `{"best": 3, "reason": "none"}`}, "Explain Git", 0)
+	assert.ErrorContains(t, err, "judge failed")
+	assert.Equal(t, 1, s.Chosen)
+}
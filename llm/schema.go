@@ -0,0 +1,237 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a parsed JSON Schema. Validation covers the keywords needed for
+// structured LLM output: type, properties, required, additionalProperties,
+// items, enum, const, min/max bounds for strings, arrays and numbers, and pattern.
+type Schema struct {
+	raw map[string]any
+}
+
+// ParseSchema parses a JSON Schema document.
+func ParseSchema(data []byte) (*Schema, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return &Schema{raw: raw}, nil
+}
+
+// SchemaFromMap builds a Schema from a decoded document, e.g. a YAML `output_schema:` block.
+func SchemaFromMap(m map[string]any) (*Schema, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return ParseSchema(data)
+}
+
+// LoadSchema reads and parses a JSON Schema file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema file '%s': %w", path, err)
+	}
+	return ParseSchema(data)
+}
+
+// String returns the schema as indented JSON, suitable for embedding in a prompt.
+func (s *Schema) String() string {
+	data, _ := json.MarshalIndent(s.raw, "", "  ")
+	return string(data)
+}
+
+// Validate checks a JSON document against the schema and returns every violation found.
+func (s *Schema) Validate(data []byte) []string {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+	}
+	var errs []string
+	validateValue(s.raw, doc, "$", &errs)
+	return errs
+}
+
+func validateValue(schema map[string]any, v any, path string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, v) {
+		fail("expected type %v, got %s", t, jsonType(v))
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, v) {
+		fail("value %v is not one of %v", v, enum)
+	}
+	if c, ok := schema["const"]; ok && !equalValues(c, v) {
+		fail("value %v does not equal %v", v, c)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if req, ok := schema["required"].([]any); ok {
+			for _, r := range req {
+				name, _ := r.(string)
+				if _, present := val[name]; !present {
+					fail("missing required property %q", name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if sub, ok := props[k].(map[string]any); ok {
+				validateValue(sub, val[k], path+"."+k, errs)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unexpected property %q", k)
+				}
+			case map[string]any:
+				validateValue(extra, val[k], path+"."+k, errs)
+			}
+		}
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(val)) < n {
+			fail("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(val)) > n {
+			fail("expected at most %v items, got %d", n, len(val))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := float64(len([]rune(val)))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			fail("expected at least %v characters", n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			fail("expected at most %v characters", n)
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(val) {
+				fail("value %q does not match pattern %q", val, p)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && val < n {
+			fail("value %v is less than minimum %v", val, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && val > n {
+			fail("value %v is greater than maximum %v", val, n)
+		}
+	}
+}
+
+func matchesType(t any, v any) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, v)
+	case []any:
+		for _, option := range tt {
+			if name, ok := option.(string); ok && isType(name, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, v any) bool {
+	switch name {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	default:
+		return jsonType(v) == name
+	}
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func containsValue(options []any, v any) bool {
+	for _, o := range options {
+		if equalValues(o, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalValues(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// ExtractJSON returns the JSON document in a model response, tolerating
+// surrounding prose and markdown code fences.
+func ExtractJSON(response string) string {
+	text := strings.TrimSpace(response)
+	if start := strings.Index(text, "```"); start >= 0 {
+		rest := text[start+3:]
+		if nl := strings.Index(rest, "\n"); nl >= 0 {
+			rest = rest[nl+1:]
+		}
+		if end := strings.Index(rest, "```"); end >= 0 {
+			return strings.TrimSpace(rest[:end])
+		}
+	}
+	first := strings.IndexAny(text, "{[")
+	if first < 0 {
+		return text
+	}
+	closer := "}"
+	if text[first] == '[' {
+		closer = "]"
+	}
+	last := strings.LastIndex(text, closer)
+	if last < first {
+		return text[first:]
+	}
+	return text[first : last+1]
+}
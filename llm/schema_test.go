@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const quizSchema = `{
+  "type": "object",
+  "required": ["topic", "questions"],
+  "additionalProperties": false,
+  "properties": {
+    "topic": {"type": "string", "minLength": 1},
+    "difficulty": {"enum": ["easy", "medium", "hard"]},
+    "questions": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "required": ["question", "answer"],
+        "properties": {
+          "question": {"type": "string"},
+          "answer": {"type": "string"},
+          "points": {"type": "integer", "minimum": 1, "maximum": 5}
+        }
+      }
+    }
+  }
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := ParseSchema([]byte(quizSchema))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		doc      string
+		contains []string
+	}{
+		{"valid", `{"topic":"git","difficulty":"easy","questions":[{"question":"q","answer":"a","points":2}]}`, nil},
+		{"missing required", `{"questions":[{"question":"q","answer":"a"}]}`, []string{`$: missing required property "topic"`}},
+		{"wrong type", `{"topic":3,"questions":[]}`, []string{"$.topic: expected type string, got number", "expected at least 1 items"}},
+		{"enum and extra", `{"topic":"git","difficulty":"insane","extra":1,"questions":[{"question":"q","answer":"a"}]}`, []string{"is not one of", `unexpected property "extra"`}},
+		{"nested bounds", `{"topic":"git","questions":[{"question":"q","answer":"a","points":2.5},{"question":"q","answer":"a","points":9}]}`, []string{"$.questions[0].points: expected type integer", "$.questions[1].points: value 9 is greater than maximum 5"}},
+		{"not json", `{"topic":`, []string{"response is not valid JSON"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := schema.Validate([]byte(c.doc))
+			if c.contains == nil {
+				assert.Empty(t, errs)
+				return
+			}
+			for _, want := range c.contains {
+				assert.Contains(t, joinErrs(errs), want)
+			}
+		})
+	}
+}
+
+func TestSchemaFromMap_NormalizesYAMLValues(t *testing.T) {
+	schema, err := SchemaFromMap(map[string]any{
+		"type":     "array",
+		"maxItems": 2, // YAML decodes this as int
+		"items":    map[string]any{"type": "string"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, schema.Validate([]byte(`["a","b"]`)))
+	assert.NotEmpty(t, schema.Validate([]byte(`["a","b","c"]`)))
+}
+
+func TestExtractJSON(t *testing.T) {
+	assert.Equal(t, `{"a":1}`, ExtractJSON("Sure! Here it is:\n```json\n{\"a\":1}\n```\nEnjoy."))
+	assert.Equal(t, `{"a":{"b":2}}`, ExtractJSON(`The result is {"a":{"b":2}} as requested.`))
+	assert.Equal(t, `[1,2]`, ExtractJSON(`[1,2]`))
+}
+
+func joinErrs(errs []string) string {
+	out := ""
+	for _, e := range errs {
+		out += e + "\n"
+	}
+	return out
+}
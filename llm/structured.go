@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// DefaultJSONRepairs is how many times ChatJSON re-prompts after a validation failure.
+const DefaultJSONRepairs = 2
+
+// SchemaValidationError is returned when no response validated within the repair budget.
+type SchemaValidationError struct {
+	Errors   []string // Violations found in the last response
+	Response string   // Last JSON candidate returned by the model
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("response did not match schema: %s", strings.Join(e.Errors, "; "))
+}
+
+// ChatJSON asks for a JSON response matching schema, enabling the provider's JSON
+// mode, and re-prompts with the validation errors up to maxRepairs times.
+// It returns the validated document, indented.
+func (c *Client) ChatJSON(ctx context.Context, prompt string, schema *Schema, maxRepairs int) (string, error) {
+	current := structuredPrompt(prompt, schema)
+	for attempt := 0; ; attempt++ {
+		resp, err := c.chat(ctx, current, wrapper.WithJSONMode())
+		if err != nil {
+			return "", err
+		}
+
+		doc := ExtractJSON(resp.Text)
+		errs := schema.Validate([]byte(doc))
+		if len(errs) == 0 {
+			return indentJSON(doc), nil
+		}
+		if attempt >= maxRepairs {
+			return "", &SchemaValidationError{Errors: errs, Response: doc}
+		}
+		current = repairPrompt(prompt, schema, doc, errs)
+	}
+}
+
+func structuredPrompt(prompt string, schema *Schema) string {
+	return fmt.Sprintf("%s\n\nRespond with a single JSON document that matches this JSON Schema. "+
+		"Do not include any text outside the JSON.\n\n%s\n", prompt, schema)
+}
+
+func repairPrompt(prompt string, schema *Schema, previous string, errs []string) string {
+	return fmt.Sprintf("%s\n\nYour previous JSON response did not validate:\n- %s\n\nPrevious response:\n%s\n\n"+
+		"Return corrected JSON only.", structuredPrompt(prompt, schema), strings.Join(errs, "\n- "), previous)
+}
+
+func indentJSON(doc string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(doc), "", "  "); err != nil {
+		return doc
+	}
+	return buf.String()
+}
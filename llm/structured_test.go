@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// scriptedClient returns a client whose generator replays responses in order and records prompts.
+func scriptedClient(responses []string, prompts *[]string, jsonMode *[]bool) *Client {
+	i := 0
+	return &Client{
+		config: llmConfig.Config{Client: llmConfig.ClientConfig{Timeout: time.Second}},
+		callGen: func(ctx context.Context, _ wrapper.Model, prompt string, opts ...wrapper.CallOption) (string, error) {
+			var o llms.CallOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			*prompts = append(*prompts, prompt)
+			if jsonMode != nil {
+				*jsonMode = append(*jsonMode, o.JSONMode)
+			}
+			if i >= len(responses) {
+				return "", errors.New("no more scripted responses")
+			}
+			i++
+			return responses[i-1], nil
+		},
+	}
+}
+
+func TestChatJSON_RepairsInvalidResponse(t *testing.T) {
+	schema, err := ParseSchema([]byte(quizSchema))
+	require.NoError(t, err)
+
+	var prompts []string
+	var jsonMode []bool
+	client := scriptedClient([]string{
+		`{"questions": []}`,
+		"```json\n{\"topic\":\"git\",\"questions\":[{\"question\":\"q\",\"answer\":\"a\"}]}\n```",
+	}, &prompts, &jsonMode)
+
+	doc, err := client.ChatJSON(context.Background(), "Make a git quiz", schema, 2)
+	require.NoError(t, err)
+	assert.Contains(t, doc, `"topic": "git"`)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "JSON Schema")
+	assert.Contains(t, prompts[1], `missing required property "topic"`)
+	assert.Equal(t, []bool{true, true}, jsonMode)
+}
+
+func TestChatJSON_GivesUpAfterRepairs(t *testing.T) {
+	schema, err := ParseSchema([]byte(quizSchema))
+	require.NoError(t, err)
+
+	var prompts []string
+	client := scriptedClient([]string{`{}`, `{}`}, &prompts, nil)
+
+	_, err = client.ChatJSON(context.Background(), "Make a git quiz", schema, 1)
+	var verr *SchemaValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.True(t, strings.Contains(verr.Error(), "topic"))
+	assert.Len(t, prompts, 2)
+}
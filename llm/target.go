@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target identifies a provider and model, written "provider:model" on the command line.
+type Target struct {
+	Provider string
+	Model    string
+}
+
+// ParseTarget parses "provider:model". Only the first colon separates the two,
+// so Ollama tags such as "ollama:llama3:8b" are kept intact.
+func ParseTarget(s string) (Target, error) {
+	provider, model, ok := strings.Cut(s, ":")
+	if !ok || provider == "" || model == "" {
+		return Target{}, fmt.Errorf("invalid target %q: expected provider:model", s)
+	}
+	return Target{Provider: provider, Model: model}, nil
+}
+
+// ParseTargets parses a list of "provider:model" strings.
+func ParseTargets(values []string) ([]Target, error) {
+	targets := make([]Target, 0, len(values))
+	for _, v := range values {
+		t, err := ParseTarget(v)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (t Target) String() string {
+	return t.Provider + ":" + t.Model
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ---------- Call Timeouts ----------
+
+// ErrTimeout matches every call timeout; the specific errors below tell which limit was hit.
+var ErrTimeout = errors.New("LLM call timed out")
+
+var (
+	ErrTotalTimeout      = fmt.Errorf("%w: total time limit exceeded", ErrTimeout)
+	ErrFirstTokenTimeout = fmt.Errorf("%w: no first token in time", ErrTimeout)
+	ErrIdleTimeout       = fmt.Errorf("%w: stream stalled between chunks", ErrTimeout)
+)
+
+// Timing reports how long a call took to start and to finish.
+type Timing struct {
+	FirstToken time.Duration // Until the first streamed chunk, or the whole response when not streamed
+	Total      time.Duration
+	Streamed   bool
+}
+
+func (t Timing) String() string {
+	if !t.Streamed {
+		return fmt.Sprintf("response after %s (not streamed)", t.Total.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("first token after %s, total %s", t.FirstToken.Round(time.Millisecond), t.Total.Round(time.Millisecond))
+}
+
+// streamWatchdog cancels a streaming call when the first chunk or the next
+// chunk takes too long, and records when the first chunk arrived.
+type streamWatchdog struct {
+	cancel     context.CancelCauseFunc
+	firstToken time.Duration
+	idle       time.Duration
+	start      time.Time
+
+	mu    sync.Mutex
+	timer *time.Timer
+	first time.Time
+}
+
+// newStreamWatchdog returns a context cancelled by the watchdog. Zero limits are not enforced.
+func newStreamWatchdog(ctx context.Context, firstToken, idle time.Duration) (context.Context, *streamWatchdog) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	w := &streamWatchdog{cancel: cancel, firstToken: firstToken, idle: idle, start: time.Now()}
+	if firstToken > 0 {
+		w.timer = time.AfterFunc(firstToken, func() {
+			cancel(fmt.Errorf("%w (limit %s)", ErrFirstTokenTimeout, firstToken))
+		})
+	}
+	return ctx, w
+}
+
+// Handle is the StreamHandler restarting the idle timer on every chunk.
+func (w *streamWatchdog) Handle(_ context.Context, _ []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.first.IsZero() {
+		w.first = time.Now()
+		if w.timer != nil { // The first-token timer
+			w.timer.Stop()
+			w.timer = nil
+		}
+	}
+	if w.idle <= 0 {
+		return nil
+	}
+	if w.timer == nil {
+		idle := w.idle
+		w.timer = time.AfterFunc(idle, func() {
+			w.cancel(fmt.Errorf("%w (limit %s)", ErrIdleTimeout, idle))
+		})
+		return nil
+	}
+	w.timer.Reset(w.idle)
+	return nil
+}
+
+// firstChunk returns the time to the first chunk, or false if none arrived.
+func (w *streamWatchdog) firstChunk() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.first.IsZero() {
+		return 0, false
+	}
+	return w.first.Sub(w.start), true
+}
+
+// stop releases the timer and the watchdog's context.
+func (w *streamWatchdog) stop() {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	w.cancel(nil)
+}
+
+// timeoutCause returns the timeout that cancelled ctx, if any.
+func timeoutCause(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrTimeout) {
+		return cause
+	}
+	return nil
+}
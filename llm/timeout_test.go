@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// slowEchoClient echoes prompts through the echo provider with the given timing options.
+func slowEchoClient(t *testing.T, client llmConfig.ClientConfig, options map[string]any, opts ...Option) *Client {
+	t.Helper()
+	factory, ok := wrapper.LookupProvider("echo")
+	require.True(t, ok)
+	model, err := factory("any", wrapper.ProviderOptions{Options: options})
+	require.NoError(t, err)
+	c, err := NewClient(llmConfig.Config{Provider: "echo", Model: llmConfig.ModelConfig{Name: "any"}, Client: client},
+		&MockProvider{model: model}, nil, opts...)
+	require.NoError(t, err)
+	c.callContent = wrapper.GenerateContent
+	return c
+}
+
+func TestClient_Timeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  llmConfig.ClientConfig
+		options map[string]any
+		want    error
+	}{
+		{
+			name:    "total",
+			client:  llmConfig.ClientConfig{Timeout: 50 * time.Millisecond},
+			options: map[string]any{"latency": "1s"},
+			want:    ErrTotalTimeout,
+		},
+		{
+			name:    "first token",
+			client:  llmConfig.ClientConfig{Timeout: time.Minute, FirstTokenTimeout: 50 * time.Millisecond},
+			options: map[string]any{"latency": "1s"},
+			want:    ErrFirstTokenTimeout,
+		},
+		{
+			name:    "idle",
+			client:  llmConfig.ClientConfig{Timeout: time.Minute, FirstTokenTimeout: time.Second, IdleTimeout: 50 * time.Millisecond},
+			options: map[string]any{"chunk_size": 2, "chunk_delay": "1s"},
+			want:    ErrIdleTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := slowEchoClient(t, tt.client, tt.options)
+			start := time.Now()
+			_, err := client.Generate(context.Background(), "a prompt long enough for several chunks")
+			assert.ErrorIs(t, err, tt.want)
+			assert.ErrorIs(t, err, ErrTimeout)
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+		})
+	}
+}
+
+func TestClient_TimingReport(t *testing.T) {
+	var timings []Timing
+	report := WithTimingReport(func(target string, tm Timing) {
+		assert.Equal(t, "echo:any", target)
+		timings = append(timings, tm)
+	})
+
+	client := slowEchoClient(t, llmConfig.ClientConfig{Timeout: time.Minute, IdleTimeout: time.Second},
+		map[string]any{"latency": "20ms", "chunk_size": 4, "chunk_delay": "10ms"}, report)
+	resp, err := client.Generate(context.Background(), "streamed in chunks")
+	require.NoError(t, err)
+	require.Len(t, timings, 1)
+	assert.True(t, timings[0].Streamed)
+	assert.GreaterOrEqual(t, timings[0].FirstToken, 20*time.Millisecond)
+	assert.Less(t, timings[0].FirstToken, timings[0].Total)
+	assert.Equal(t, timings[0].FirstToken, resp.FirstToken)
+
+	client = slowEchoClient(t, llmConfig.ClientConfig{Timeout: time.Minute}, nil, report)
+	resp, err = client.Generate(context.Background(), "not streamed")
+	require.NoError(t, err)
+	require.Len(t, timings, 2)
+	assert.False(t, timings[1].Streamed)
+	assert.Equal(t, resp.Latency, resp.FirstToken)
+}
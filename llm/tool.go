@@ -0,0 +1,236 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// ---------- Tool Calling ----------
+
+// DefaultMaxToolIterations caps the model turns that may request tools in one Generate.
+const DefaultMaxToolIterations = 5
+
+// maxToolResultBytes keeps a tool's output from flooding the context.
+const maxToolResultBytes = 16 << 10
+
+// ErrToolIterations is returned when the model still requests tools after the iteration cap.
+var ErrToolIterations = errors.New("tool call limit reached")
+
+// toolName is what providers accept as a function name.
+var toolName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ToolHandler runs a tool with the model's JSON arguments and returns its result as text.
+type ToolHandler func(ctx context.Context, args json.RawMessage) (string, error)
+
+// Tool is a local function the model may call.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any // JSON Schema of the arguments object
+	Handler     ToolHandler
+
+	schema *Schema
+}
+
+// ToolCall is one tool invocation made while generating a response.
+type ToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Result    string          `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Duration  time.Duration   `json:"-"`
+}
+
+// String renders the call for logs, e.g. `git_log({"limit":5}) → 212 bytes in 8ms`.
+func (c ToolCall) String() string {
+	outcome := fmt.Sprintf("%d bytes", len(c.Result))
+	if c.Error != "" {
+		outcome = "error: " + c.Error
+	}
+	return fmt.Sprintf("%s(%s) → %s in %s", c.Name, c.Arguments, outcome, c.Duration.Round(time.Millisecond))
+}
+
+// ToolRegistry holds the tools offered to a model. It is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+}
+
+// NewToolRegistry returns a registry holding tools.
+func NewToolRegistry(tools ...Tool) (*ToolRegistry, error) {
+	r := &ToolRegistry{tools: map[string]Tool{}}
+	for _, t := range tools {
+		if err := r.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool, rejecting invalid names, duplicate names and invalid schemas.
+func (r *ToolRegistry) Register(t Tool) error {
+	if !toolName.MatchString(t.Name) {
+		return fmt.Errorf("invalid tool name %q: use up to 64 letters, digits, '_' or '-'", t.Name)
+	}
+	if t.Handler == nil {
+		return fmt.Errorf("tool %s has no handler", t.Name)
+	}
+	if t.Parameters == nil {
+		t.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	schema, err := SchemaFromMap(t.Parameters)
+	if err != nil {
+		return fmt.Errorf("tool %s: %w", t.Name, err)
+	}
+	t.schema = schema
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[t.Name]; ok {
+		return fmt.Errorf("tool %s is already registered", t.Name)
+	}
+	r.tools[t.Name] = t
+	return nil
+}
+
+// Names returns the sorted names of the registered tools.
+func (r *ToolRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namesLocked()
+}
+
+// Call validates args against the tool's schema and runs it. Unknown tools,
+// invalid arguments and handler failures are reported in the returned call so
+// the model can correct itself.
+func (r *ToolRegistry) Call(ctx context.Context, name, args string) ToolCall {
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+	call := ToolCall{Name: name, Arguments: json.RawMessage(args)}
+	if !json.Valid(call.Arguments) {
+		call.Arguments, _ = json.Marshal(args)
+		call.Error = "arguments are not valid JSON"
+		return call
+	}
+
+	r.mu.RLock()
+	t, ok := r.tools[name]
+	r.mu.RUnlock()
+	if !ok {
+		call.Error = fmt.Sprintf("unknown tool %q; available tools: %s", name, strings.Join(r.Names(), ", "))
+		return call
+	}
+	if errs := t.schema.Validate(call.Arguments); len(errs) > 0 {
+		call.Error = "invalid arguments: " + strings.Join(errs, "; ")
+		return call
+	}
+
+	start := time.Now()
+	result, err := t.Handler(ctx, call.Arguments)
+	call.Duration = time.Since(start)
+	if err != nil {
+		call.Error = err.Error()
+		return call
+	}
+	if len(result) > maxToolResultBytes {
+		result = result[:maxToolResultBytes] + "\n[truncated]"
+	}
+	call.Result = result
+	return call
+}
+
+// definitions returns the tools in the form providers accept.
+func (r *ToolRegistry) definitions() []wrapper.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]wrapper.Tool, 0, len(r.tools))
+	for _, name := range r.namesLocked() {
+		t := r.tools[name]
+		defs = append(defs, wrapper.FunctionTool(t.Name, t.Description, t.Parameters))
+	}
+	return defs
+}
+
+func (r *ToolRegistry) namesLocked() []string {
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// content is the tool message sent back to the model for the call.
+func (c ToolCall) content() string {
+	if c.Error != "" {
+		return "Error: " + c.Error
+	}
+	return c.Result
+}
+
+// SupportsTools reports whether the client's model accepts tool definitions.
+func (c *Client) SupportsTools() bool {
+	return wrapper.SupportsTools(c.model)
+}
+
+// toolsEnabled reports whether calls offer the client's tools.
+func (c *Client) toolsEnabled() bool {
+	return c.tools != nil && len(c.tools.Names()) > 0 && c.SupportsTools()
+}
+
+// sendWithTools sends messages offering the client's tools, runs the calls the
+// model requests and sends their results back, until the model answers
+// without requesting tools. The response carries every call made. Turns are
+// not streamed, since providers stream tool requests as raw JSON.
+func (c *Client) sendWithTools(ctx context.Context, messages []wrapper.MessageContent, extra ...wrapper.CallOption) (*Response, error) {
+	limit := c.config.Client.MaxToolIterations
+	if limit <= 0 {
+		limit = DefaultMaxToolIterations
+	}
+	extra = append(extra, wrapper.WithTools(c.tools.definitions()))
+
+	var calls []ToolCall
+	var usage Usage
+	var latency time.Duration
+	for turn := 0; ; turn++ {
+		resp, err := c.send(ctx, messages, nil, extra...)
+		if err != nil {
+			return nil, err
+		}
+		usage.Add(resp.Usage)
+		latency += resp.Latency
+		if len(resp.toolCalls) == 0 {
+			resp.ToolCalls, resp.Usage, resp.Latency = calls, usage, latency
+			return resp, nil
+		}
+		if turn == limit {
+			return nil, fmt.Errorf("%w: the model still requested tools after %d rounds", ErrToolIterations, limit)
+		}
+
+		messages = append(messages, wrapper.ToolCallMessage(resp.Text, resp.toolCalls))
+		for _, req := range resp.toolCalls {
+			if req.FunctionCall == nil {
+				continue
+			}
+			call := c.tools.Call(ctx, req.FunctionCall.Name, req.FunctionCall.Arguments)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+			if c.toolReport != nil {
+				c.toolReport(c.Target(), call)
+			}
+			messages = append(messages, wrapper.ToolResultMessage(req.ID, call.Name, call.content()))
+		}
+	}
+}
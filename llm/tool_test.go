@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func glossaryTestTool() Tool {
+	return Tool{
+		Name:        "glossary",
+		Description: "Look up a term",
+		Parameters: map[string]any{
+			"type":       "object",
+			"required":   []any{"term"},
+			"properties": map[string]any{"term": map[string]any{"type": "string"}},
+		},
+		Handler: func(_ context.Context, raw json.RawMessage) (string, error) {
+			var args struct{ Term string }
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", err
+			}
+			if args.Term == "rebase" {
+				return "", errors.New("not in the glossary")
+			}
+			return args.Term + ": a saved snapshot", nil
+		},
+	}
+}
+
+func TestToolRegistry_Register(t *testing.T) {
+	r, err := NewToolRegistry(glossaryTestTool())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"glossary"}, r.Names())
+
+	assert.ErrorContains(t, r.Register(glossaryTestTool()), "already registered")
+	assert.ErrorContains(t, r.Register(Tool{Name: "git log", Handler: glossaryTestTool().Handler}), "invalid tool name")
+	assert.ErrorContains(t, r.Register(Tool{Name: "git_log"}), "no handler")
+	require.NoError(t, r.Register(Tool{Name: "now", Handler: func(context.Context, json.RawMessage) (string, error) { return "noon", nil }}))
+	assert.Equal(t, "noon", r.Call(context.Background(), "now", "").Result)
+}
+
+func TestToolRegistry_Call(t *testing.T) {
+	r, err := NewToolRegistry(glossaryTestTool())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	call := r.Call(ctx, "glossary", `{"term":"commit"}`)
+	assert.Equal(t, "commit: a saved snapshot", call.Result)
+	assert.Empty(t, call.Error)
+
+	assert.Equal(t, "not in the glossary", r.Call(ctx, "glossary", `{"term":"rebase"}`).Error)
+	assert.Contains(t, r.Call(ctx, "glossary", `{}`).Error, "invalid arguments")
+	assert.Equal(t, "arguments are not valid JSON", r.Call(ctx, "glossary", `{"term":`).Error)
+	assert.Equal(t, `unknown tool "shell"; available tools: glossary`, r.Call(ctx, "shell", `{}`).Error)
+
+	big := Tool{Name: "big", Handler: func(context.Context, json.RawMessage) (string, error) {
+		return strings.Repeat("x", maxToolResultBytes+10), nil
+	}}
+	require.NoError(t, r.Register(big))
+	assert.True(t, strings.HasSuffix(r.Call(ctx, "big", "{}").Result, "\n[truncated]"))
+}
+
+// toolCallingClient requests the glossary tool for each term in turn, then answers.
+func toolCallingClient(t *testing.T, terms []string, maxIterations int, calls *[][]wrapper.MessageContent) *Client {
+	t.Helper()
+	tools, err := NewToolRegistry(glossaryTestTool())
+	require.NoError(t, err)
+	return &Client{
+		model: wrapper.NewFakeModel(&wrapper.FakeFixtures{}),
+		config: llmConfig.Config{
+			Provider: "fake",
+			Model:    llmConfig.ModelConfig{Name: "any"},
+			Client:   llmConfig.ClientConfig{Timeout: time.Second, MaxToolIterations: maxIterations},
+		},
+		tools: tools,
+		callContent: func(_ context.Context, _ wrapper.Model, msgs []wrapper.MessageContent, opts ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+			var o llms.CallOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			require.Len(t, o.Tools, 1)
+			assert.Equal(t, "glossary", o.Tools[0].Function.Name)
+
+			i := len(*calls)
+			*calls = append(*calls, msgs)
+			if i < len(terms) {
+				return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{
+					StopReason: "tool_calls",
+					ToolCalls: []llms.ToolCall{{
+						ID:           "call_" + terms[i],
+						Type:         "function",
+						FunctionCall: &llms.FunctionCall{Name: "glossary", Arguments: `{"term":"` + terms[i] + `"}`},
+					}},
+				}}}, nil
+			}
+			return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{Content: "A commit is a snapshot.", StopReason: "stop"}}}, nil
+		},
+	}
+}
+
+func TestClient_Generate_RunsTools(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	client := toolCallingClient(t, []string{"commit", "rebase"}, 5, &calls)
+	var reported []ToolCall
+	client.toolReport = func(_ string, call ToolCall) { reported = append(reported, call) }
+
+	resp, err := client.Generate(context.Background(), "What is a commit?")
+	require.NoError(t, err)
+	assert.Equal(t, "A commit is a snapshot.", resp.Text)
+	require.Len(t, resp.ToolCalls, 2)
+	assert.Equal(t, resp.ToolCalls, reported)
+	assert.Equal(t, "commit: a saved snapshot", resp.ToolCalls[0].Result)
+	assert.Equal(t, "not in the glossary", resp.ToolCalls[1].Error)
+	assert.Equal(t, 3*EstimateTokens("What is a commit?"), resp.Usage.PromptTokens, "usage covers every turn")
+
+	require.Len(t, calls, 3)
+	last := calls[2]
+	require.Len(t, last, 5)
+	assert.Equal(t, llms.ChatMessageTypeAI, last[1].Role)
+	assert.Equal(t, llms.ChatMessageTypeTool, last[2].Role)
+	assert.Equal(t, llms.ToolCallResponse{ToolCallID: "call_commit", Name: "glossary", Content: "commit: a saved snapshot"}, last[2].Parts[0])
+	assert.Equal(t, llms.ToolCallResponse{ToolCallID: "call_rebase", Name: "glossary", Content: "Error: not in the glossary"}, last[4].Parts[0])
+}
+
+func TestClient_Generate_ToolIterationLimit(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	client := toolCallingClient(t, []string{"a", "b", "c"}, 2, &calls)
+
+	_, err := client.Generate(context.Background(), "What is a commit?")
+	assert.ErrorIs(t, err, ErrToolIterations)
+	assert.Len(t, calls, 3)
+}
+
+func TestClient_Generate_SkipsToolsForUnsupportedModels(t *testing.T) {
+	var calls [][]wrapper.MessageContent
+	client := toolCallingClient(t, nil, 5, &calls)
+	client.model = wrapper.NewEchoModel()
+	client.callContent = func(context.Context, wrapper.Model, []wrapper.MessageContent, ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+		return &wrapper.ContentResponse{Choices: []*llms.ContentChoice{{Content: "plain"}}}, nil
+	}
+	assert.False(t, client.SupportsTools())
+	resp, err := client.Generate(context.Background(), "What is a commit?")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", resp.Text)
+	assert.Empty(t, resp.ToolCalls)
+}
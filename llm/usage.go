@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+)
+
+// ErrBudgetExceeded is returned when a call is attempted after the run's cost budget is spent.
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+// Usage reports token counts and cost for one or more LLM calls.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost_usd"`
+	Estimated        bool    `json:"estimated,omitempty"` // Token counts were estimated, not reported
+	Priced           bool    `json:"priced,omitempty"`    // A price was found for the model
+}
+
+// TotalTokens returns prompt plus completion tokens.
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add accumulates another usage record.
+func (u *Usage) Add(o Usage) {
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.Cost += o.Cost
+	u.Estimated = u.Estimated || o.Estimated
+	u.Priced = u.Priced || o.Priced
+}
+
+// String formats the usage for a one-line log entry.
+func (u Usage) String() string {
+	approx := ""
+	if u.Estimated {
+		approx = "~"
+	}
+	cost := "cost unknown"
+	if u.Priced {
+		cost = fmt.Sprintf("$%.4f", u.Cost)
+	}
+	return fmt.Sprintf("%s%d prompt + %s%d completion tokens, %s",
+		approx, u.PromptTokens, approx, u.CompletionTokens, cost)
+}
+
+// Pricing maps "provider:model" or bare model names to prices.
+type Pricing map[string]llmConfig.Price
+
+// DefaultPricing holds list prices (USD per million tokens) for common hosted models.
+// Local Ollama models are free.
+var DefaultPricing = Pricing{
+	"gpt-4o":        {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":   {Input: 0.15, Output: 0.60},
+	"gpt-4.1":       {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":  {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":  {Input: 0.10, Output: 0.40},
+	"gpt-4-turbo":   {Input: 10.00, Output: 30.00},
+	"gpt-4":         {Input: 30.00, Output: 60.00},
+	"gpt-3.5-turbo": {Input: 0.50, Output: 1.50},
+}
+
+// WithOverrides returns a copy of p with the given entries added or replaced.
+func (p Pricing) WithOverrides(overrides map[string]llmConfig.Price) Pricing {
+	merged := make(Pricing, len(p)+len(overrides))
+	for k, v := range p {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// freeProviderTypes run locally or offline and cost nothing unless priced explicitly.
+var freeProviderTypes = map[string]bool{ProviderTypeOllama: true, "fake": true, "echo": true}
+
+// Lookup finds the price for a provider/model pair. Local and offline providers default to free.
+func (p Pricing) Lookup(provider, model string) (llmConfig.Price, bool) {
+	if price, ok := p[provider+":"+model]; ok {
+		return price, true
+	}
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	if freeProviderTypes[ProviderType(provider)] {
+		return llmConfig.Price{}, true
+	}
+	return llmConfig.Price{}, false
+}
+
+// Apply fills in the cost of u for the given provider/model.
+func (p Pricing) Apply(provider, model string, u Usage) Usage {
+	price, ok := p.Lookup(provider, model)
+	u.Priced = ok
+	u.Cost = (float64(u.PromptTokens)*price.Input + float64(u.CompletionTokens)*price.Output) / 1e6
+	return u
+}
+
+// UsageTracker accumulates usage across the calls of a run and enforces a cost budget.
+type UsageTracker struct {
+	// Report, when set, is called with the usage of every recorded call.
+	Report func(target string, u Usage)
+
+	maxCost float64
+
+	mu      sync.Mutex
+	calls   int
+	total   Usage
+	byModel map[string]*Usage
+	order   []string
+}
+
+// NewUsageTracker creates a tracker; maxCost <= 0 disables the budget.
+func NewUsageTracker(maxCost float64) *UsageTracker {
+	return &UsageTracker{maxCost: maxCost, byModel: map[string]*Usage{}}
+}
+
+// Record adds the usage of one call made with the given "provider:model" target.
+func (t *UsageTracker) Record(target string, u Usage) {
+	if t.Report != nil {
+		t.Report(target, u)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls++
+	t.total.Add(u)
+	m, ok := t.byModel[target]
+	if !ok {
+		m = &Usage{}
+		t.byModel[target] = m
+		t.order = append(t.order, target)
+	}
+	m.Add(u)
+}
+
+// Total returns the accumulated usage and the number of calls recorded.
+func (t *UsageTracker) Total() (Usage, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total, t.calls
+}
+
+// CheckBudget returns ErrBudgetExceeded once the accumulated cost has reached the budget.
+func (t *UsageTracker) CheckBudget() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.maxCost > 0 && t.total.Cost >= t.maxCost {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f", ErrBudgetExceeded, t.total.Cost, t.maxCost)
+	}
+	return nil
+}
+
+// Summary renders a per-model usage table for the run.
+func (t *UsageTracker) Summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage summary (%d calls):\n", t.calls)
+	for _, target := range t.order {
+		fmt.Fprintf(&b, "  %-30s %s\n", target, t.byModel[target])
+	}
+	fmt.Fprintf(&b, "  %-30s %s\n", "total", t.total)
+	return b.String()
+}
+
+// EstimateTokens approximates the token count of text for providers that do not
+// report usage. It blends a word-based and a character-based estimate, which
+// tracks BPE tokenizers closely enough for budgeting.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	words := len(strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}))
+	punct := 0
+	for _, r := range text {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			punct++
+		}
+	}
+	byWords := (words*4+2)/3 + punct
+	byChars := (len([]rune(text)) + 3) / 4
+	if byWords > byChars {
+		return byWords
+	}
+	return byChars
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	llmConfig "raja.aiml/ai.explorer/config/llm"
+)
+
+func TestPricing_LookupAndOverrides(t *testing.T) {
+	pricing := DefaultPricing.WithOverrides(map[string]llmConfig.Price{
+		"vllm:llama-3": {Input: 0.1, Output: 0.2},
+		"gpt-4o":       {Input: 5, Output: 15},
+	})
+
+	price, ok := pricing.Lookup("openai", "gpt-4o")
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, price.Input)
+	assert.Equal(t, 2.50, DefaultPricing["gpt-4o"].Input, "defaults must not be mutated")
+
+	price, ok = pricing.Lookup("vllm", "llama-3")
+	assert.True(t, ok)
+	assert.Equal(t, 0.2, price.Output)
+
+	_, ok = pricing.Lookup("ollama", "phi4")
+	assert.True(t, ok, "local ollama models are free")
+
+	_, ok = pricing.Lookup("vllm", "unknown")
+	assert.False(t, ok)
+}
+
+func TestPricing_LookupByProviderType(t *testing.T) {
+	require.NoError(t, RegisterConfiguredProviders(llmConfig.Config{Providers: []llmConfig.ProviderConfig{
+		{Name: "gpu-box", Type: ProviderTypeOllama, BaseURL: "http://gpu-box:11434"},
+		{Name: "lab-vllm", Type: ProviderTypeOpenAICompatible, BaseURL: "http://vllm:8000/v1"},
+	}}))
+
+	_, ok := DefaultPricing.Lookup("gpu-box", "llama3")
+	assert.True(t, ok, "config-declared ollama servers are free")
+	_, ok = DefaultPricing.Lookup("lab-vllm", "llama3")
+	assert.False(t, ok)
+	_, ok = DefaultPricing.Lookup("fake", "git")
+	assert.True(t, ok)
+}
+
+func TestPricing_Apply(t *testing.T) {
+	u := DefaultPricing.Apply("openai", "gpt-4o", Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	assert.True(t, u.Priced)
+	assert.InDelta(t, 2.50+5.00, u.Cost, 1e-9)
+	assert.Contains(t, u.String(), "$7.5000")
+
+	u = DefaultPricing.Apply("vllm", "mystery", Usage{PromptTokens: 10})
+	assert.False(t, u.Priced)
+	assert.Contains(t, u.String(), "cost unknown")
+}
+
+func TestUsageTracker_BudgetAndSummary(t *testing.T) {
+	tracker := NewUsageTracker(0.01)
+	var reported []string
+	tracker.Report = func(target string, u Usage) { reported = append(reported, target) }
+
+	assert.NoError(t, tracker.CheckBudget())
+	tracker.Record("openai:gpt-4o", Usage{PromptTokens: 100, CompletionTokens: 50, Cost: 0.004, Priced: true})
+	tracker.Record("ollama:phi4", Usage{PromptTokens: 10, CompletionTokens: 5, Priced: true, Estimated: true})
+	assert.NoError(t, tracker.CheckBudget())
+	tracker.Record("openai:gpt-4o", Usage{PromptTokens: 100, CompletionTokens: 50, Cost: 0.007, Priced: true})
+
+	err := tracker.CheckBudget()
+	assert.True(t, errors.Is(err, ErrBudgetExceeded))
+
+	total, calls := tracker.Total()
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 315, total.TotalTokens())
+	assert.Equal(t, []string{"openai:gpt-4o", "ollama:phi4", "openai:gpt-4o"}, reported)
+
+	summary := tracker.Summary()
+	assert.Contains(t, summary, "Usage summary (3 calls)")
+	assert.Contains(t, summary, "openai:gpt-4o")
+	assert.Contains(t, summary, "$0.0110")
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.InDelta(t, 10, EstimateTokens("The quick brown fox jumps over the lazy dog."), 3)
+	long := "Git stores snapshots of your project in commits, which form a history you can branch and merge."
+	assert.InDelta(t, 21, EstimateTokens(long), 5)
+}
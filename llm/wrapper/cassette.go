@@ -0,0 +1,264 @@
+package wrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ---------- HTTP Record/Replay ----------
+
+// CassetteMode selects whether a Cassette records live traffic or replays it.
+type CassetteMode int
+
+const (
+	CassetteRecord CassetteMode = iota
+	CassetteReplay
+)
+
+// redacted replaces secrets in stored requests.
+const redacted = "REDACTED"
+
+// Headers and query parameters whose values are never written to disk.
+var (
+	secretHeaders = []string{"Authorization", "Api-Key", "X-Api-Key", "Openai-Organization", "Cookie", "Proxy-Authorization"}
+	secretParams  = []string{"key", "api_key", "apikey", "access_token", "token"}
+)
+
+// Interaction is a stored request and the responses recorded for it, in order.
+type Interaction struct {
+	Request   RecordedRequest    `json:"request"`
+	Responses []RecordedResponse `json:"responses"`
+}
+
+// RecordedRequest is a request with its secrets redacted.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a captured HTTP response.
+type RecordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body"`
+}
+
+// Cassette is an http.RoundTripper that records exchanges to a directory, one
+// JSON file per distinct request, or replays them without touching the network.
+// Requests match on method, redacted URL and normalized body; repeated requests
+// replay their recorded responses in turn, repeating the last.
+type Cassette struct {
+	dir  string
+	mode CassetteMode
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	served map[string]int
+}
+
+// NewCassette creates a cassette over dir. base is used when recording; nil means http.DefaultTransport.
+func NewCassette(dir string, mode CassetteMode, base http.RoundTripper) (*Cassette, error) {
+	if mode == CassetteRecord {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cassette dir '%s': %w", dir, err)
+		}
+	} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("cassette dir '%s' not found", dir)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Cassette{dir: dir, mode: mode, base: base, served: map[string]int{}}, nil
+}
+
+// Client returns an HTTP client that sends its requests through the cassette.
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Replaying reports whether the cassette serves stored responses.
+func (c *Cassette) Replaying() bool {
+	return c.mode == CassetteReplay
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: redactHeaders(req.Header),
+		Body:    normalizeBody(body),
+	}
+	key := interactionKey(recorded)
+
+	if c.mode == CassetteReplay {
+		return c.replay(req, recorded, key)
+	}
+	return c.record(req, recorded, key)
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest, key string) (*http.Response, error) {
+	resp, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interaction, err := c.load(key)
+	if errors.Is(err, os.ErrNotExist) {
+		interaction, err = &Interaction{Request: recorded}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	interaction.Responses = append(interaction.Responses, RecordedResponse{
+		Status:  resp.StatusCode,
+		Headers: redactHeaders(resp.Header),
+		Body:    string(data),
+	})
+	if err := c.save(key, interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest, key string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interaction, err := c.load(key)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(interaction.Responses) == 0) {
+		return nil, fmt.Errorf("cassette: no recorded response for %s %s in %s (request key %s); re-record with --record",
+			recorded.Method, recorded.URL, c.dir, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := c.served[key]
+	c.served[key] = n + 1
+	r := interaction.Responses[min(n, len(interaction.Responses)-1)]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *Cassette) load(key string) (*Interaction, error) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, err
+	}
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("invalid cassette file '%s': %w", c.path(key), err)
+	}
+	return &interaction, nil
+}
+
+func (c *Cassette) save(key string, interaction *Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), append(data, '\n'), 0644)
+}
+
+// readBody reads the request body and restores it for the real transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// normalizeBody compacts JSON with sorted keys so formatting differences do not break matching.
+func normalizeBody(body []byte) string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	return string(data)
+}
+
+func interactionKey(r RecordedRequest) string {
+	sum := sha256.Sum256([]byte(r.Method + " " + r.URL + "\n" + r.Body))
+	return hex.EncodeToString(sum[:8])
+}
+
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+func redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	q := clean.Query()
+	for _, name := range secretParams {
+		if q.Has(name) {
+			q.Set(name, redacted)
+		}
+	}
+	clean.RawQuery = q.Encode()
+	return clean.String()
+}
+
+// replaying reports whether client serves responses from a cassette, so
+// providers can skip credential checks that only matter for live calls.
+func replaying(client *http.Client) bool {
+	if client == nil {
+		return false
+	}
+	c, ok := client.Transport.(*Cassette)
+	return ok && c.Replaying()
+}
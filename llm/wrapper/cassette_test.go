@@ -0,0 +1,86 @@
+package wrapper_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	srv, _ := newOpenAIStandIn(t, "recorded answer")
+	t.Setenv("CASSETTE_TEST_KEY", "sk-very-secret")
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	factory := wrapper.NewOpenAICompatibleFactory(wrapper.OpenAICompatibleConfig{BaseURL: srv.URL, APIKeyEnv: "CASSETTE_TEST_KEY"})
+
+	recorder, err := wrapper.NewCassette(dir, wrapper.CassetteRecord, nil)
+	require.NoError(t, err)
+	model, err := factory("llama-3-8b", wrapper.ProviderOptions{HTTPClient: recorder.Client()})
+	require.NoError(t, err)
+	resp, err := wrapper.GenerateFromSinglePrompt(context.Background(), model, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "recorded answer", resp)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-very-secret")
+	assert.Contains(t, string(data), "REDACTED")
+
+	srv.Close()
+	player, err := wrapper.NewCassette(dir, wrapper.CassetteReplay, nil)
+	require.NoError(t, err)
+	model, err = factory("llama-3-8b", wrapper.ProviderOptions{HTTPClient: player.Client()})
+	require.NoError(t, err)
+	resp, err = wrapper.GenerateFromSinglePrompt(context.Background(), model, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "recorded answer", resp)
+
+	_, err = wrapper.GenerateFromSinglePrompt(context.Background(), model, "a different prompt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cassette: no recorded response for POST "+srv.URL+"/chat/completions")
+}
+
+func TestCassette_ReplayMatchesNormalizedBodyAndRedactedQuery(t *testing.T) {
+	dir := t.TempDir()
+	srv, _ := newOpenAIStandIn(t, "unused")
+	recorder, err := wrapper.NewCassette(dir, wrapper.CassetteRecord, nil)
+	require.NoError(t, err)
+
+	post := func(client *http.Client, body, key string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/generate?key="+key, strings.NewReader(body))
+		require.NoError(t, err)
+		return client.Do(req)
+	}
+	resp, err := post(recorder.Client(), `{"b": 2, "a": 1}`, "live-key")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	player, err := wrapper.NewCassette(dir, wrapper.CassetteReplay, nil)
+	require.NoError(t, err)
+	resp, err = post(player.Client(), `{"a":1,"b":2}`, "other-key")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = wrapper.NewCassette(filepath.Join(dir, "missing"), wrapper.CassetteReplay, nil)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestOpenAIProvider_ReplayWithoutAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	player, err := wrapper.NewCassette(t.TempDir(), wrapper.CassetteReplay, nil)
+	require.NoError(t, err)
+
+	model, err := (&wrapper.LangchaingoProvider{HTTPClient: player.Client()}).Init("openai", "gpt-4o")
+	require.NoError(t, err)
+	assert.NotNil(t, model)
+}
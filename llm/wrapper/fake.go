@@ -0,0 +1,384 @@
+package wrapper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+	"gopkg.in/yaml.v3"
+)
+
+// ---------- Offline Providers ----------
+
+// FakeFixturesEnv names the fixtures file used by the fake provider when no
+// "fixtures" option is given.
+const FakeFixturesEnv = "FAKE_LLM_FIXTURES"
+
+// FakeFixtures is the fixtures file of the fake provider.
+type FakeFixtures struct {
+	Latency    time.Duration `yaml:"latency"`     // Delay before the first chunk
+	ChunkSize  int           `yaml:"chunk_size"`  // Runes per streamed chunk (default 16)
+	ChunkDelay time.Duration `yaml:"chunk_delay"` // Delay between streamed chunks
+	ErrorRate  float64       `yaml:"error_rate"`  // Probability (0..1) that a call fails
+	Seed       int64         `yaml:"seed"`        // Seed for error injection (default 1)
+	Default    string        `yaml:"default"`     // Response when no fixture matches; empty fails the call
+	Fixtures   []Fixture     `yaml:"fixtures"`
+
+	mu sync.Mutex
+}
+
+// Fixture is a scripted response, matched by prompt regex or by the SHA-256
+// of the trimmed prompt. The first matching fixture wins.
+type Fixture struct {
+	Match        string         `yaml:"match"`
+	Hash         string         `yaml:"hash"`
+	Response     string         `yaml:"response"`
+	ResponseFile string         `yaml:"response_file"` // Relative to the fixtures file
+	Responses    []string       `yaml:"responses"`     // Returned in turn on repeated matches; the last repeats
+	StopReason   string         `yaml:"stop_reason"`
+	StopReasons  []string       `yaml:"stop_reasons"` // Per entry of Responses; missing entries use StopReason
+	Latency      time.Duration  `yaml:"latency"`
+	Error        string         `yaml:"error"`
+	ToolCalls    []FakeToolCall `yaml:"tool_calls"` // Requested when tools are offered, before any tool result is sent back
+
+	pattern *regexp.Regexp
+	calls   int
+}
+
+// FakeToolCall is a tool call the fake provider requests.
+type FakeToolCall struct {
+	Name      string         `yaml:"name"`
+	Arguments map[string]any `yaml:"arguments"`
+}
+
+// fakeReply is the scripted answer to one call.
+type fakeReply struct {
+	text       string
+	stopReason string
+	toolCalls  []FakeToolCall
+	latency    time.Duration
+}
+
+// PromptHash returns the fixture hash of a prompt.
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(prompt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadFakeFixtures reads a fixtures file and compiles its patterns.
+func LoadFakeFixtures(path string) (*FakeFixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures file '%s': %w", path, err)
+	}
+	var f FakeFixtures
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixtures file '%s': %w", path, err)
+	}
+	for i := range f.Fixtures {
+		fx := &f.Fixtures[i]
+		if fx.Match == "" && fx.Hash == "" {
+			return nil, fmt.Errorf("fixture %d in '%s' needs match or hash", i+1, path)
+		}
+		if fx.Match != "" {
+			if fx.pattern, err = regexp.Compile(fx.Match); err != nil {
+				return nil, fmt.Errorf("fixture %d in '%s': %w", i+1, path, err)
+			}
+		}
+		if fx.ResponseFile != "" {
+			file := fx.ResponseFile
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("fixture %d in '%s': %w", i+1, path, err)
+			}
+			fx.Response = string(content)
+		}
+	}
+	return &f, nil
+}
+
+// fakeSettings controls timing and error injection of a FakeModel.
+type fakeSettings struct {
+	latency    time.Duration
+	chunkSize  int
+	chunkDelay time.Duration
+	errorRate  float64
+	seed       int64
+}
+
+// FakeModel is an offline Model that answers from fixtures, or echoes the prompt.
+type FakeModel struct {
+	fixtures *FakeFixtures // nil for echo
+	settings fakeSettings
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewFakeModel answers from fixtures, using the file's latency, chunk and error settings.
+func NewFakeModel(fixtures *FakeFixtures) *FakeModel {
+	return newFakeModelWith(fixtures, fixtures.settings())
+}
+
+// NewEchoModel answers every prompt with the prompt itself.
+func NewEchoModel() *FakeModel {
+	return newFakeModelWith(nil, fakeSettings{})
+}
+
+func newFakeModelWith(fixtures *FakeFixtures, settings fakeSettings) *FakeModel {
+	seed := settings.seed
+	if seed == 0 {
+		seed = 1
+	}
+	return &FakeModel{fixtures: fixtures, settings: settings, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (f *FakeFixtures) settings() fakeSettings {
+	return fakeSettings{latency: f.Latency, chunkSize: f.ChunkSize, chunkDelay: f.ChunkDelay, errorRate: f.ErrorRate, seed: f.Seed}
+}
+
+// Call implements Model.
+func (m *FakeModel) Call(ctx context.Context, prompt string, opts ...CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, opts...)
+}
+
+// GenerateContent implements Model, streaming the scripted response when a
+// streaming function is set.
+func (m *FakeModel) GenerateContent(ctx context.Context, messages []MessageContent, opts ...CallOption) (*ContentResponse, error) {
+	var callOpts llms.CallOptions
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+
+	prompt := MessageText(messages)
+	reply, err := m.respond(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, reply.latency); err != nil {
+		return nil, err
+	}
+	if len(reply.toolCalls) > 0 && len(callOpts.Tools) > 0 && !hasToolResults(messages) {
+		return fakeToolCalls(reply.toolCalls)
+	}
+	if callOpts.StreamingFunc != nil {
+		if err := m.stream(ctx, reply.text, callOpts.StreamingFunc); err != nil {
+			return nil, err
+		}
+	}
+	if reply.stopReason == "" {
+		reply.stopReason = "stop"
+	}
+	return &ContentResponse{Choices: []*llms.ContentChoice{{Content: reply.text, StopReason: reply.stopReason}}}, nil
+}
+
+// fakeToolCalls returns a response requesting the scripted tool calls.
+func fakeToolCalls(calls []FakeToolCall) (*ContentResponse, error) {
+	choice := &llms.ContentChoice{StopReason: "tool_calls"}
+	for i, call := range calls {
+		args, err := json.Marshal(call.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("fake provider: tool call %s: %w", call.Name, err)
+		}
+		choice.ToolCalls = append(choice.ToolCalls, ToolCall{
+			ID:           fmt.Sprintf("call_%d", i+1),
+			Type:         "function",
+			FunctionCall: &llms.FunctionCall{Name: call.Name, Arguments: string(args)},
+		})
+	}
+	return &ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+// hasToolResults reports whether the conversation already carries tool results.
+func hasToolResults(messages []MessageContent) bool {
+	for _, msg := range messages {
+		if msg.Role == llms.ChatMessageTypeTool {
+			return true
+		}
+	}
+	return false
+}
+
+// respond picks the scripted answer for prompt.
+func (m *FakeModel) respond(prompt string) (fakeReply, error) {
+	m.mu.Lock()
+	inject := m.settings.errorRate > 0 && m.rng.Float64() < m.settings.errorRate
+	m.mu.Unlock()
+	if inject {
+		return fakeReply{}, errors.New("fake provider: injected error")
+	}
+	if m.fixtures == nil {
+		return fakeReply{text: prompt, latency: m.settings.latency}, nil
+	}
+	return m.fixtures.respond(prompt, m.settings.latency)
+}
+
+// respond finds the first fixture matching prompt. Counters for sequenced
+// responses live on the fixtures, so they advance across models sharing the file.
+func (f *FakeFixtures) respond(prompt string, latency time.Duration) (fakeReply, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hash := PromptHash(prompt)
+	for i := range f.Fixtures {
+		fx := &f.Fixtures[i]
+		if (fx.pattern == nil || !fx.pattern.MatchString(prompt)) && (fx.Hash == "" || !strings.EqualFold(fx.Hash, hash)) {
+			continue
+		}
+		if fx.Latency > 0 {
+			latency = fx.Latency
+		}
+		if fx.Error != "" {
+			return fakeReply{latency: latency}, fmt.Errorf("fake provider: %s", fx.Error)
+		}
+		text, stopReason := fx.Response, fx.StopReason
+		if len(fx.Responses) > 0 {
+			i := min(fx.calls, len(fx.Responses)-1)
+			text = fx.Responses[i]
+			if i < len(fx.StopReasons) {
+				stopReason = fx.StopReasons[i]
+			}
+		}
+		fx.calls++
+		return fakeReply{text: text, stopReason: stopReason, toolCalls: fx.ToolCalls, latency: latency}, nil
+	}
+	if f.Default != "" {
+		return fakeReply{text: f.Default, latency: latency}, nil
+	}
+	return fakeReply{}, fmt.Errorf("fake provider: no fixture matches prompt (sha256 %s)", hash)
+}
+
+func (m *FakeModel) stream(ctx context.Context, text string, fn func(ctx context.Context, chunk []byte) error) error {
+	size := m.settings.chunkSize
+	if size <= 0 {
+		size = 16
+	}
+	runes := []rune(text)
+	for start := 0; start < len(runes); start += size {
+		if start > 0 {
+			if err := sleep(ctx, m.settings.chunkDelay); err != nil {
+				return err
+			}
+		}
+		end := min(start+size, len(runes))
+		if err := fn(ctx, []byte(string(runes[start:end]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	fixturesMu     sync.Mutex
+	loadedFixtures = map[string]*FakeFixtures{}
+)
+
+// sharedFixtures loads a fixtures file once per process.
+func sharedFixtures(path string) (*FakeFixtures, error) {
+	fixturesMu.Lock()
+	defer fixturesMu.Unlock()
+	if f, ok := loadedFixtures[path]; ok {
+		return f, nil
+	}
+	f, err := LoadFakeFixtures(path)
+	if err != nil {
+		return nil, err
+	}
+	loadedFixtures[path] = f
+	return f, nil
+}
+
+// applyFakeOptions overrides settings with the latency, chunk and error options.
+func applyFakeOptions(provider string, settings fakeSettings, opts map[string]any, ignore ...string) (fakeSettings, error) {
+	for k, v := range opts {
+		if slices.Contains(ignore, k) {
+			continue
+		}
+		var err error
+		switch k {
+		case "latency":
+			settings.latency, err = durationValue(v)
+		case "chunk_delay":
+			settings.chunkDelay, err = durationValue(v)
+		case "chunk_size":
+			settings.chunkSize, err = intValue(v)
+		case "error_rate":
+			settings.errorRate, err = floatValue(v)
+		case "seed":
+			var seed int
+			seed, err = intValue(v)
+			settings.seed = int64(seed)
+		default:
+			return settings, fmt.Errorf("unsupported %s option: %s", provider, k)
+		}
+		if err != nil {
+			return settings, fmt.Errorf("invalid %s option %s: %w", provider, k, err)
+		}
+	}
+	return settings, nil
+}
+
+// newFakeProviderModel reads the fixtures named by the "fixtures" option or FakeFixturesEnv.
+func newFakeProviderModel(_ string, opts ProviderOptions) (Model, error) {
+	path, _ := opts.Options["fixtures"].(string)
+	if path == "" {
+		path = os.Getenv(FakeFixturesEnv)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("fake provider requires a fixtures file: set --option fixtures=<file> or %s", FakeFixturesEnv)
+	}
+	fixtures, err := sharedFixtures(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := applyFakeOptions("fake", fixtures.settings(), opts.Options, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return newFakeModelWith(fixtures, settings), nil
+}
+
+func newEchoProviderModel(_ string, opts ProviderOptions) (Model, error) {
+	settings, err := applyFakeOptions("echo", fakeSettings{}, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	return newFakeModelWith(nil, settings), nil
+}
+
+func durationValue(v any) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		return time.ParseDuration(s)
+	}
+	n, err := intValue(v)
+	return time.Duration(n) * time.Millisecond, err
+}
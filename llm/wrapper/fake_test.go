@@ -0,0 +1,172 @@
+package wrapper_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func writeFixtures(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "answer.md"), []byte("# From file"), 0644))
+	path := filepath.Join(dir, "fixtures.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func generate(t *testing.T, model wrapper.Model, prompt string, opts ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+	t.Helper()
+	return model.GenerateContent(context.Background(), []wrapper.MessageContent{wrapper.HumanMessage(prompt)}, opts...)
+}
+
+func TestFakeProvider_MatchesFixtures(t *testing.T) {
+	path := writeFixtures(t, `
+fixtures:
+  - match: "(?i)git"
+    response_file: answer.md
+  - hash: "`+wrapper.PromptHash("  exact prompt ")+`"
+    response: hashed
+  - match: "continue"
+    responses: ["part one", "part two"]
+    stop_reason: length
+  - match: "truncate"
+    responses: ["first half", "second half"]
+    stop_reasons: [length]
+  - match: "outage"
+    error: "503 service unavailable"
+`)
+	factory, ok := wrapper.LookupProvider("fake")
+	require.True(t, ok)
+	model, err := factory("any", wrapper.ProviderOptions{Options: map[string]any{"fixtures": path}})
+	require.NoError(t, err)
+
+	resp, err := generate(t, model, "Explain Git")
+	require.NoError(t, err)
+	assert.Equal(t, "# From file", resp.Choices[0].Content)
+	assert.Equal(t, "stop", resp.Choices[0].StopReason)
+
+	resp, err = generate(t, model, "exact prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "hashed", resp.Choices[0].Content)
+
+	var texts []string
+	for i := 0; i < 3; i++ {
+		resp, err = generate(t, model, "please continue")
+		require.NoError(t, err)
+		texts = append(texts, resp.Choices[0].Content)
+	}
+	assert.Equal(t, []string{"part one", "part two", "part two"}, texts)
+	assert.Equal(t, "length", resp.Choices[0].StopReason)
+
+	resp, err = generate(t, model, "truncate me")
+	require.NoError(t, err)
+	assert.Equal(t, "length", resp.Choices[0].StopReason)
+	resp, err = generate(t, model, "truncate me")
+	require.NoError(t, err)
+	assert.Equal(t, "second half", resp.Choices[0].Content)
+	assert.Equal(t, "stop", resp.Choices[0].StopReason)
+
+	_, err = generate(t, model, "simulate an outage")
+	assert.ErrorContains(t, err, "503 service unavailable")
+
+	_, err = generate(t, model, "unmatched")
+	assert.ErrorContains(t, err, "sha256 "+wrapper.PromptHash("unmatched"))
+}
+
+func TestFakeProvider_RequestsToolCalls(t *testing.T) {
+	path := writeFixtures(t, `
+fixtures:
+  - match: "commits"
+    tool_calls:
+      - name: git_log
+        arguments: {limit: 3}
+    response: "Three commits."
+`)
+	fixtures, err := wrapper.LoadFakeFixtures(path)
+	require.NoError(t, err)
+	model := wrapper.NewFakeModel(fixtures)
+	assert.True(t, wrapper.SupportsTools(model))
+	assert.False(t, wrapper.SupportsTools(wrapper.NewEchoModel()))
+	tools := wrapper.WithTools([]wrapper.Tool{wrapper.FunctionTool("git_log", "Show commits", nil)})
+
+	resp, err := generate(t, model, "Show recent commits")
+	require.NoError(t, err)
+	assert.Equal(t, "Three commits.", resp.Choices[0].Content, "no tools offered")
+
+	resp, err = generate(t, model, "Show recent commits", tools)
+	require.NoError(t, err)
+	require.Len(t, resp.Choices[0].ToolCalls, 1)
+	call := resp.Choices[0].ToolCalls[0]
+	assert.Equal(t, "call_1", call.ID)
+	assert.Equal(t, "git_log", call.FunctionCall.Name)
+	assert.JSONEq(t, `{"limit": 3}`, call.FunctionCall.Arguments)
+
+	messages := []wrapper.MessageContent{
+		wrapper.HumanMessage("Show recent commits"),
+		wrapper.ToolCallMessage("", resp.Choices[0].ToolCalls),
+		wrapper.ToolResultMessage(call.ID, "git_log", "abc123 Add README"),
+	}
+	resp, err = model.GenerateContent(context.Background(), messages, tools)
+	require.NoError(t, err)
+	assert.Equal(t, "Three commits.", resp.Choices[0].Content)
+	assert.Empty(t, resp.Choices[0].ToolCalls)
+}
+
+func TestFakeProvider_RequiresFixtures(t *testing.T) {
+	t.Setenv(wrapper.FakeFixturesEnv, "")
+	factory, _ := wrapper.LookupProvider("fake")
+	_, err := factory("any", wrapper.ProviderOptions{})
+	assert.ErrorContains(t, err, "requires a fixtures file")
+
+	t.Setenv(wrapper.FakeFixturesEnv, writeFixtures(t, "default: fallback\n"))
+	model, err := factory("any", wrapper.ProviderOptions{})
+	require.NoError(t, err)
+	resp, err := generate(t, model, "anything")
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", resp.Choices[0].Content)
+}
+
+func TestEchoProvider_StreamsPromptInChunks(t *testing.T) {
+	factory, ok := wrapper.LookupProvider("echo")
+	require.True(t, ok)
+	model, err := factory("any", wrapper.ProviderOptions{Options: map[string]any{"chunk_size": "4", "latency": "10ms"}})
+	require.NoError(t, err)
+
+	var chunks []string
+	start := time.Now()
+	resp, err := generate(t, model, "hello world", wrapper.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.Equal(t, "hello world", resp.Choices[0].Content)
+	assert.Equal(t, []string{"hell", "o wo", "rld"}, chunks)
+	assert.Equal(t, "hello world", strings.Join(chunks, ""))
+
+	_, err = factory("any", wrapper.ProviderOptions{Options: map[string]any{"fixtures": "x.yaml"}})
+	assert.ErrorContains(t, err, "unsupported echo option: fixtures")
+}
+
+func TestEchoProvider_InjectsErrors(t *testing.T) {
+	factory, _ := wrapper.LookupProvider("echo")
+	model, err := factory("any", wrapper.ProviderOptions{Options: map[string]any{"error_rate": 1.0}})
+	require.NoError(t, err)
+	_, err = generate(t, model, "hi")
+	assert.ErrorContains(t, err, "injected error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow, err := factory("any", wrapper.ProviderOptions{Options: map[string]any{"latency": "1s"}})
+	require.NoError(t, err)
+	_, err = slow.GenerateContent(ctx, []wrapper.MessageContent{wrapper.HumanMessage("hi")})
+	assert.ErrorIs(t, err, context.Canceled)
+}
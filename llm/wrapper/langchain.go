@@ -2,7 +2,10 @@ package wrapper
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
@@ -15,6 +18,8 @@ type (
 	CallOption      = llms.CallOption
 	MessageContent  = llms.MessageContent
 	ContentResponse = llms.ContentResponse
+	Tool            = llms.Tool
+	ToolCall        = llms.ToolCall
 )
 
 // ---------- LLM Provider Abstraction ----------
@@ -25,18 +30,19 @@ type Provider interface {
 }
 
 // LangchaingoProvider is a concrete LLM provider using langchaingo.
-type LangchaingoProvider struct{}
+type LangchaingoProvider struct {
+	Options    map[string]any // Provider-specific passthrough options
+	HTTPClient *http.Client   // Optional client for provider calls, e.g. a record/replay cassette
+}
 
-// Init returns a new Model for the given provider and model name.
+// Init returns a new Model for the given provider and model name,
+// resolved through the provider registry.
 func (p *LangchaingoProvider) Init(providerName, modelName string) (Model, error) {
-	switch providerName {
-	case "ollama":
-		return ollama.New(ollama.WithModel(modelName))
-	case "openai":
-		return openai.New(openai.WithModel(modelName))
-	default:
+	factory, ok := LookupProvider(providerName)
+	if !ok {
 		return nil, fmt.Errorf("unsupported LLM provider: %s", providerName)
 	}
+	return factory(modelName, ProviderOptions{HTTPClient: p.HTTPClient, Options: p.Options})
 }
 
 // ---------- Embedding Abstraction ----------
@@ -75,6 +81,76 @@ func NewEmbedderFromBase(e embeddings.Embedder) *EmbedderImpl {
 
 // ---------- LLM Generation Helpers ----------
 
+// GenerateContent calls the model with a full message list, returning the raw response.
+func GenerateContent(ctx context.Context, model Model, messages []MessageContent, opts ...CallOption) (*ContentResponse, error) {
+	return model.GenerateContent(ctx, messages, opts...)
+}
+
+// HumanMessage builds a single-text user message.
+func HumanMessage(text string) MessageContent {
+	return llms.TextParts(llms.ChatMessageTypeHuman, text)
+}
+
+// AIMessage returns an assistant message, e.g. an earlier answer in a conversation.
+func AIMessage(text string) MessageContent {
+	return llms.TextParts(llms.ChatMessageTypeAI, text)
+}
+
+// ToolCallMessage returns the assistant turn that requested calls, with any text
+// the model wrote alongside them.
+func ToolCallMessage(text string, calls []ToolCall) MessageContent {
+	msg := MessageContent{Role: llms.ChatMessageTypeAI}
+	if text != "" {
+		msg.Parts = append(msg.Parts, llms.TextContent{Text: text})
+	}
+	for _, call := range calls {
+		msg.Parts = append(msg.Parts, call)
+	}
+	return msg
+}
+
+// ToolResultMessage returns the result of the tool call with the given id.
+func ToolResultMessage(id, name, content string) MessageContent {
+	return MessageContent{
+		Role:  llms.ChatMessageTypeTool,
+		Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: id, Name: name, Content: content}},
+	}
+}
+
+// SupportsTools reports whether model accepts tool definitions. Ollama's
+// langchaingo client does not send them.
+func SupportsTools(model Model) bool {
+	switch m := model.(type) {
+	case *openai.LLM:
+		return true
+	case *FakeModel:
+		return m.fixtures != nil
+	}
+	return false
+}
+
+// ImagePart builds an image part in the form model expects: raw bytes for
+// Ollama, a base64 data URL for OpenAI-style APIs.
+func ImagePart(model Model, mimeType string, data []byte) llms.ContentPart {
+	if _, ok := model.(*ollama.LLM); ok {
+		return llms.BinaryPart(mimeType, data)
+	}
+	return llms.ImageURLPart("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// MessageText joins the text parts of messages, one per line.
+func MessageText(messages []MessageContent) string {
+	var parts []string
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if t, ok := part.(llms.TextContent); ok {
+				parts = append(parts, t.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 // GenerateFromSinglePrompt is an alias to langchaingo's llms.GenerateFromSinglePrompt
 func GenerateFromSinglePrompt(ctx context.Context, model Model, prompt string, opts ...CallOption) (string, error) {
 	return llms.GenerateFromSinglePrompt(ctx, model, prompt, opts...)
@@ -89,3 +165,58 @@ func WithTemperature(temp float64) CallOption {
 func WithStreamingFunc(f func(ctx context.Context, chunk []byte) error) CallOption {
 	return llms.WithStreamingFunc(f)
 }
+
+// WithMaxTokens wraps llms.WithMaxTokens
+func WithMaxTokens(maxTokens int) CallOption {
+	return llms.WithMaxTokens(maxTokens)
+}
+
+// WithTopP wraps llms.WithTopP
+func WithTopP(topP float64) CallOption {
+	return llms.WithTopP(topP)
+}
+
+// WithTopK wraps llms.WithTopK
+func WithTopK(topK int) CallOption {
+	return llms.WithTopK(topK)
+}
+
+// WithStopWords wraps llms.WithStopWords
+func WithStopWords(stop []string) CallOption {
+	return llms.WithStopWords(stop)
+}
+
+// WithSeed wraps llms.WithSeed
+func WithSeed(seed int) CallOption {
+	return llms.WithSeed(seed)
+}
+
+// WithFrequencyPenalty wraps llms.WithFrequencyPenalty
+func WithFrequencyPenalty(penalty float64) CallOption {
+	return llms.WithFrequencyPenalty(penalty)
+}
+
+// WithPresencePenalty wraps llms.WithPresencePenalty
+func WithPresencePenalty(penalty float64) CallOption {
+	return llms.WithPresencePenalty(penalty)
+}
+
+// WithRepetitionPenalty wraps llms.WithRepetitionPenalty
+func WithRepetitionPenalty(penalty float64) CallOption {
+	return llms.WithRepetitionPenalty(penalty)
+}
+
+// WithTools wraps llms.WithTools
+func WithTools(tools []Tool) CallOption {
+	return llms.WithTools(tools)
+}
+
+// FunctionTool describes a function the model may call.
+func FunctionTool(name, description string, parameters map[string]any) Tool {
+	return Tool{Type: "function", Function: &llms.FunctionDefinition{Name: name, Description: description, Parameters: parameters}}
+}
+
+// WithJSONMode wraps llms.WithJSONMode
+func WithJSONMode() CallOption {
+	return llms.WithJSONMode()
+}
@@ -49,7 +49,8 @@ func TestProvider_Init(t *testing.T) {
 	}{
 		{"openai", "openai", "gpt-3.5", false},
 		{"ollama", "ollama", "phi4", false},
-		{"invalid", "fake", "none", true},
+		{"echo", "echo", "any", false},
+		{"invalid", "unknown", "none", true},
 	}
 
 	prov := &wrapper.LangchaingoProvider{}
@@ -0,0 +1,142 @@
+package wrapper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+// ---------- Provider-Specific Options ----------
+
+// ollamaOptionSetters maps Ollama option names to the matching langchaingo option.
+var ollamaOptionSetters = map[string]func(v any) (ollama.Option, error){
+	"num_ctx":          intOption(ollama.WithRunnerNumCtx),
+	"num_keep":         intOption(ollama.WithRunnerNumKeep),
+	"num_batch":        intOption(ollama.WithRunnerNumBatch),
+	"num_thread":       intOption(ollama.WithRunnerNumThread),
+	"num_gqa":          intOption(ollama.WithRunnerNumGQA),
+	"num_gpu":          intOption(ollama.WithRunnerNumGPU),
+	"main_gpu":         intOption(ollama.WithRunnerMainGPU),
+	"low_vram":         boolOption(ollama.WithRunnerLowVRAM),
+	"f16_kv":           boolOption(ollama.WithRunnerF16KV),
+	"use_mmap":         boolOption(ollama.WithRunnerUseMMap),
+	"use_mlock":        boolOption(ollama.WithRunnerUseMLock),
+	"numa":             boolOption(ollama.WithRunnerUseNUMA),
+	"repeat_last_n":    intOption(ollama.WithPredictRepeatLastN),
+	"mirostat":         intOption(ollama.WithPredictMirostat),
+	"mirostat_tau":     float32Option(ollama.WithPredictMirostatTau),
+	"mirostat_eta":     float32Option(ollama.WithPredictMirostatEta),
+	"tfs_z":            float32Option(ollama.WithPredictTFSZ),
+	"typical_p":        float32Option(ollama.WithPredictTypicalP),
+	"penalize_newline": boolOption(ollama.WithPredictPenalizeNewline),
+	"keep_alive":       stringOption(ollama.WithKeepAlive),
+	"format":           stringOption(ollama.WithFormat),
+	"system":           stringOption(ollama.WithSystemPrompt),
+}
+
+// ollamaOptions converts a passthrough map into Ollama options, rejecting unknown keys.
+func ollamaOptions(opts map[string]any) ([]ollama.Option, error) {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []ollama.Option
+	for _, k := range keys {
+		setter, ok := ollamaOptionSetters[k]
+		if !ok {
+			return nil, fmt.Errorf("unsupported ollama option: %s", k)
+		}
+		opt, err := setter(opts[k])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ollama option %s: %w", k, err)
+		}
+		out = append(out, opt)
+	}
+	return out, nil
+}
+
+// rejectOptions reports passthrough options for providers that accept none.
+func rejectOptions(provider string, opts map[string]any) error {
+	for k := range opts {
+		return fmt.Errorf("unsupported %s option: %s", provider, k)
+	}
+	return nil
+}
+
+// Values arrive as YAML scalars or as strings from --option key=value flags.
+
+func intValue(v any) (int, error) {
+	switch x := v.(type) {
+	case int:
+		return x, nil
+	case int64:
+		return int(x), nil
+	case float64:
+		return int(x), nil
+	case string:
+		return strconv.Atoi(x)
+	}
+	return 0, fmt.Errorf("expected integer, got %T", v)
+}
+
+func floatValue(v any) (float64, error) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), nil
+	case float64:
+		return x, nil
+	case string:
+		return strconv.ParseFloat(x, 64)
+	}
+	return 0, fmt.Errorf("expected number, got %T", v)
+}
+
+func boolValue(v any) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		return strconv.ParseBool(x)
+	}
+	return false, fmt.Errorf("expected boolean, got %T", v)
+}
+
+func intOption(f func(int) ollama.Option) func(any) (ollama.Option, error) {
+	return func(v any) (ollama.Option, error) {
+		n, err := intValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return f(n), nil
+	}
+}
+
+func float32Option(f func(float32) ollama.Option) func(any) (ollama.Option, error) {
+	return func(v any) (ollama.Option, error) {
+		n, err := floatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return f(float32(n)), nil
+	}
+}
+
+func boolOption(f func(bool) ollama.Option) func(any) (ollama.Option, error) {
+	return func(v any) (ollama.Option, error) {
+		b, err := boolValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return f(b), nil
+	}
+}
+
+func stringOption(f func(string) ollama.Option) func(any) (ollama.Option, error) {
+	return func(v any) (ollama.Option, error) {
+		return f(fmt.Sprint(v)), nil
+	}
+}
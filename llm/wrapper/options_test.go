@@ -0,0 +1,41 @@
+package wrapper_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func TestProviderOptions_Ollama(t *testing.T) {
+	prov := &wrapper.LangchaingoProvider{Options: map[string]any{
+		"num_ctx":    8192,   // YAML integer
+		"num_thread": "8",    // --option flag string
+		"low_vram":   "true", // --option flag string
+		"keep_alive": "10m",  // string option
+		"typical_p":  0.7,    // YAML float
+	}}
+	model, err := prov.Init("ollama", "phi4")
+	assert.NoError(t, err)
+	assert.NotNil(t, model)
+}
+
+func TestProviderOptions_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider string
+		options  map[string]any
+		contains string
+	}{
+		{"unknown ollama key", "ollama", map[string]any{"num_cxt": 1}, "unsupported ollama option: num_cxt"},
+		{"bad ollama value", "ollama", map[string]any{"num_ctx": "lots"}, "invalid ollama option num_ctx"},
+		{"openai rejects options", "openai", map[string]any{"num_ctx": 1}, "unsupported openai option: num_ctx"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("OPENAI_API_KEY", "dummy-key")
+			_, err := (&wrapper.LangchaingoProvider{Options: c.options}).Init(c.provider, "m")
+			assert.ErrorContains(t, err, c.contains)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package wrapper
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// ---------- Provider Registry ----------
+
+// ProviderOptions carries per-model settings handed to a ProviderFactory.
+type ProviderOptions struct {
+	HTTPClient *http.Client   // Optional; nil uses the provider's default client
+	Options    map[string]any // Provider-specific passthrough, e.g. Ollama's num_ctx
+}
+
+// ProviderFactory builds a Model for the given model name.
+type ProviderFactory func(modelName string, opts ProviderOptions) (Model, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]ProviderFactory{}
+)
+
+func init() {
+	RegisterProvider("ollama", NewOllamaFactory("", nil))
+	RegisterProvider("openai", newOpenAIModel)
+	RegisterProvider("fake", newFakeProviderModel)
+	RegisterProvider("echo", newEchoProviderModel)
+}
+
+// RegisterProvider makes a provider available under name, replacing any existing entry.
+func RegisterProvider(name string, factory ProviderFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// LookupProvider returns the factory registered under name.
+func LookupProvider(name string) (ProviderFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// Providers returns the sorted names of all registered providers.
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newOpenAIModel(modelName string, opts ProviderOptions) (Model, error) {
+	if err := rejectOptions("openai", opts.Options); err != nil {
+		return nil, err
+	}
+	openaiOpts := []openai.Option{openai.WithModel(modelName)}
+	if opts.HTTPClient != nil {
+		openaiOpts = append(openaiOpts, openai.WithHTTPClient(opts.HTTPClient))
+	}
+	if replaying(opts.HTTPClient) && os.Getenv("OPENAI_API_KEY") == "" {
+		openaiOpts = append(openaiOpts, openai.WithToken(placeholderAPIKey))
+	}
+	return openai.New(openaiOpts...)
+}
+
+// ---------- Configurable Providers ----------
+
+// OpenAICompatibleConfig describes a server that speaks the OpenAI API
+// (vLLM, LM Studio, llama.cpp server, ...).
+type OpenAICompatibleConfig struct {
+	BaseURL      string
+	APIKeyEnv    string // Env var holding the API key; empty for servers without auth
+	Organization string
+	Headers      map[string]string // Sent with every request
+}
+
+// placeholderAPIKey satisfies the OpenAI client for servers that ignore auth.
+const placeholderAPIKey = "not-needed"
+
+// NewOpenAICompatibleFactory returns a factory for an OpenAI-compatible server.
+func NewOpenAICompatibleFactory(cfg OpenAICompatibleConfig) ProviderFactory {
+	return func(modelName string, opts ProviderOptions) (Model, error) {
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("openai-compatible provider requires a base_url")
+		}
+		if err := rejectOptions("openai-compatible", opts.Options); err != nil {
+			return nil, err
+		}
+
+		token := placeholderAPIKey
+		if cfg.APIKeyEnv != "" {
+			token = os.Getenv(cfg.APIKeyEnv)
+			if token == "" {
+				return nil, fmt.Errorf("environment variable %s is not set", cfg.APIKeyEnv)
+			}
+		}
+
+		client := opts.HTTPClient
+		if client == nil {
+			client = http.DefaultClient
+		}
+		if len(cfg.Headers) > 0 {
+			client = withHeaders(client, cfg.Headers)
+		}
+
+		openaiOpts := []openai.Option{
+			openai.WithModel(modelName),
+			openai.WithBaseURL(cfg.BaseURL),
+			openai.WithToken(token),
+			openai.WithHTTPClient(client),
+		}
+		if cfg.Organization != "" {
+			openaiOpts = append(openaiOpts, openai.WithOrganization(cfg.Organization))
+		}
+		return openai.New(openaiOpts...)
+	}
+}
+
+// NewOllamaFactory returns a factory for an Ollama server at a custom URL.
+func NewOllamaFactory(serverURL string, headers map[string]string) ProviderFactory {
+	return func(modelName string, opts ProviderOptions) (Model, error) {
+		extra, err := ollamaOptions(opts.Options)
+		if err != nil {
+			return nil, err
+		}
+		ollamaOpts := append([]ollama.Option{ollama.WithModel(modelName)}, extra...)
+		if serverURL != "" {
+			ollamaOpts = append(ollamaOpts, ollama.WithServerURL(serverURL))
+		}
+		client := opts.HTTPClient
+		if len(headers) > 0 {
+			if client == nil {
+				client = http.DefaultClient
+			}
+			client = withHeaders(client, headers)
+		}
+		if client != nil {
+			ollamaOpts = append(ollamaOpts, ollama.WithHTTPClient(client))
+		}
+		return ollama.New(ollamaOpts...)
+	}
+}
+
+// headerTransport adds fixed headers to every outgoing request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+func withHeaders(client *http.Client, headers map[string]string) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &headerTransport{base: base, headers: headers}
+	return &wrapped
+}
@@ -0,0 +1,89 @@
+package wrapper_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// newOpenAIStandIn serves a minimal /chat/completions endpoint and records the last request.
+func newOpenAIStandIn(t *testing.T, reply string) (*httptest.Server, *http.Request) {
+	t.Helper()
+	var last http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = *r.Clone(context.Background())
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 1,
+			"model":   body["model"],
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": reply},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]any{"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &last
+}
+
+func TestRegisterProvider_UsedByInit(t *testing.T) {
+	called := ""
+	wrapper.RegisterProvider("test-registry", func(modelName string, _ wrapper.ProviderOptions) (wrapper.Model, error) {
+		called = modelName
+		return new(mockLLM), nil
+	})
+
+	model, err := (&wrapper.LangchaingoProvider{}).Init("test-registry", "tiny")
+	require.NoError(t, err)
+	assert.NotNil(t, model)
+	assert.Equal(t, "tiny", called)
+	assert.Contains(t, wrapper.Providers(), "test-registry")
+	assert.Contains(t, wrapper.Providers(), "ollama")
+	assert.Contains(t, wrapper.Providers(), "openai")
+}
+
+func TestOpenAICompatibleFactory(t *testing.T) {
+	srv, last := newOpenAIStandIn(t, "hello from vllm")
+	t.Setenv("VLLM_TEST_KEY", "secret-key")
+
+	factory := wrapper.NewOpenAICompatibleFactory(wrapper.OpenAICompatibleConfig{
+		BaseURL:   srv.URL,
+		APIKeyEnv: "VLLM_TEST_KEY",
+		Headers:   map[string]string{"X-Team": "explorers"},
+	})
+	model, err := factory("llama-3-8b", wrapper.ProviderOptions{})
+	require.NoError(t, err)
+
+	resp, err := wrapper.GenerateFromSinglePrompt(context.Background(), model, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello from vllm", resp)
+	assert.Equal(t, "/chat/completions", last.URL.Path)
+	assert.Equal(t, "Bearer secret-key", last.Header.Get("Authorization"))
+	assert.Equal(t, "explorers", last.Header.Get("X-Team"))
+}
+
+func TestOpenAICompatibleFactory_Errors(t *testing.T) {
+	_, err := wrapper.NewOpenAICompatibleFactory(wrapper.OpenAICompatibleConfig{})("m", wrapper.ProviderOptions{})
+	assert.ErrorContains(t, err, "base_url")
+
+	os.Unsetenv("MISSING_TEST_KEY")
+	_, err = wrapper.NewOpenAICompatibleFactory(wrapper.OpenAICompatibleConfig{
+		BaseURL:   "http://localhost:1",
+		APIKeyEnv: "MISSING_TEST_KEY",
+	})("m", wrapper.ProviderOptions{})
+	assert.ErrorContains(t, err, "MISSING_TEST_KEY")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
 // Declare package-level variables for dependency injection.
@@ -26,3 +27,12 @@ func FormatList(items []string) string {
 	}
 	return "\n- " + strings.Join(items, "\n- ")
 }
+
+// Slug turns a name into an id or a directory name, e.g. "Staging Area & Index"
+// → "staging-area-index". Letters and digits of any script are kept, along with
+// the marks that combine with them; every other run of characters becomes a dash.
+func Slug(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	}), "-")
+}
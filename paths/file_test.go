@@ -93,3 +93,24 @@ func TestEnsureDirectoryExistsError(t *testing.T) {
 	EnsureDirectoryExists(testFilePath)
 	// If no panic occurs, the test will fail in the deferred function.
 }
+
+// TestSlug verifies Slug keeps ASCII names to letters, digits and dashes and
+// keeps other names readable.
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"Staging Area & Index": "staging-area-index",
+		" Git 2.0! ":           "git-2-0",
+		"分支":                   "分支",
+		"合并 / 变基":              "合并-变基",
+		"Über Git":             "über-git",
+		"ber git":              "ber-git",
+		"Git für Anfänger":     "git-für-anfänger",
+		"शाखा":                 "शाखा",
+		"":                     "",
+	}
+	for name, expected := range cases {
+		if result := Slug(name); result != expected {
+			t.Errorf("Slug(%q): expected %q, got %q", name, expected, result)
+		}
+	}
+}
@@ -4,11 +4,17 @@ import "fmt"
 
 // Default paths
 const (
-	BasePath         = "resources/templates"
-	ConfigPathFormat = BasePath + "/configs/%s.yaml"
-	OutputPathFormat = BasePath + "/output/%s/prompt.txt"
-	AnswerPathFormat = BasePath + "/output/%s/answer.md"
-	TemplateFilePath = BasePath + "/topic.yaml"
+	BasePath          = "resources"
+	ConfigPathFormat  = BasePath + "/configs/%s.yaml"
+	OutputPathFormat  = BasePath + "/output/%s/prompt.txt"
+	AnswerPathFormat  = BasePath + "/output/%s/answer.md"
+	TemplateFilePath  = BasePath + "/templates/topic.yaml"
+	PipelineDirFormat = BasePath + "/output/pipelines/%s"
+	ChartRunDirFormat = BasePath + "/output/charts/%s"
+	ExploreDirFormat  = BasePath + "/output/explore/%s"
+	ConfigDir         = BasePath + "/configs"
+	OutputDir         = BasePath + "/output"
+	GraphDir          = BasePath + "/output/graph"
 )
 
 // GetConfigPath returns the config file path for a given topic
@@ -42,3 +48,35 @@ func GetTemplatePath(customPath string) string {
 	}
 	return TemplateFilePath
 }
+
+// GetPipelineOutputDir returns the directory a pipeline saves its step outputs in
+func GetPipelineOutputDir(name string, customPath string) string {
+	if customPath != "" {
+		return customPath
+	}
+	return fmt.Sprintf(PipelineDirFormat, name)
+}
+
+// GetChartRunDir returns the directory a chart run saves its step results and report in
+func GetChartRunDir(name string, customPath string) string {
+	if customPath != "" {
+		return customPath
+	}
+	return fmt.Sprintf(ChartRunDirFormat, name)
+}
+
+// GetExploreDir returns the directory a topic's knowledge tree is saved in
+func GetExploreDir(topic string, customPath string) string {
+	if customPath != "" {
+		return customPath
+	}
+	return fmt.Sprintf(ExploreDirFormat, topic)
+}
+
+// GetGraphDir returns the directory the knowledge graph exports are saved in
+func GetGraphDir(customPath string) string {
+	if customPath != "" {
+		return customPath
+	}
+	return GraphDir
+}
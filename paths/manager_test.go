@@ -2,6 +2,8 @@ package paths
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -71,3 +73,51 @@ func TestGetTemplatePath_Default(t *testing.T) {
 		t.Errorf("Expected default template path %q, got %q", expected, result)
 	}
 }
+
+func TestGetPipelineOutputDir(t *testing.T) {
+	if result := GetPipelineOutputDir("git", "out/custom"); result != "out/custom" {
+		t.Errorf("Expected custom dir %q, got %q", "out/custom", result)
+	}
+	expected := fmt.Sprintf(PipelineDirFormat, "git")
+	if result := GetPipelineOutputDir("git", ""); result != expected {
+		t.Errorf("Expected default dir %q, got %q", expected, result)
+	}
+}
+
+func TestGetChartRunDir(t *testing.T) {
+	if result := GetChartRunDir("flowchart", "out/custom"); result != "out/custom" {
+		t.Errorf("Expected custom dir %q, got %q", "out/custom", result)
+	}
+	expected := fmt.Sprintf(ChartRunDirFormat, "flowchart")
+	if result := GetChartRunDir("flowchart", ""); result != expected {
+		t.Errorf("Expected default dir %q, got %q", expected, result)
+	}
+}
+
+func TestGetExploreDir(t *testing.T) {
+	if result := GetExploreDir("git", "out/custom"); result != "out/custom" {
+		t.Errorf("Expected custom dir %q, got %q", "out/custom", result)
+	}
+	expected := fmt.Sprintf(ExploreDirFormat, "git")
+	if result := GetExploreDir("git", ""); result != expected {
+		t.Errorf("Expected default dir %q, got %q", expected, result)
+	}
+}
+
+func TestGetGraphDir(t *testing.T) {
+	if result := GetGraphDir("out/custom"); result != "out/custom" {
+		t.Errorf("Expected custom dir %q, got %q", "out/custom", result)
+	}
+	if result := GetGraphDir(""); result != GraphDir {
+		t.Errorf("Expected default dir %q, got %q", GraphDir, result)
+	}
+}
+
+func TestDefaultPaths_ExistInResources(t *testing.T) {
+	// The defaults are relative to the repository root.
+	for _, path := range []string{TemplateFilePath, fmt.Sprintf(ConfigPathFormat, "git"), ConfigDir} {
+		if _, err := os.Stat(filepath.Join("..", path)); err != nil {
+			t.Errorf("Expected default path %q to exist: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// -------------------- Definition --------------------
+
+// Pipeline is a DAG of LLM calls. Each step renders a prompt from a template
+// or an inline prompt, with the outputs of the steps it needs in its context.
+type Pipeline struct {
+	Name      string   `yaml:"name"`
+	OutputDir string   `yaml:"output_dir"` // Where step outputs and the run state are saved
+	Defaults  Settings `yaml:"defaults"`   // Provider and model for steps that do not set them
+	Steps     []Step   `yaml:"steps"`
+}
+
+// Settings selects the model of a step.
+type Settings struct {
+	Provider    string   `yaml:"provider"`
+	Model       string   `yaml:"model"`
+	Temperature *float64 `yaml:"temperature"`
+}
+
+// Step is one LLM call of a pipeline.
+type Step struct {
+	ID       string   `yaml:"id"`       // Also the step's variable in later templates
+	Template string   `yaml:"template"` // Template YAML, as used by the prompt command
+	Config   string   `yaml:"config"`   // Topic or chart config whose fields feed the template
+	Prompt   string   `yaml:"prompt"`   // Inline template, instead of Template
+	Needs    []string `yaml:"needs"`    // Steps whose outputs feed this step
+	JSON     bool     `yaml:"json"`     // Request and parse a JSON answer
+	Schema   string   `yaml:"schema"`   // JSON Schema the answer must match; implies JSON
+	Output   string   `yaml:"output"`   // File name in the output dir (default <id>.md, or <id>.json)
+
+	Settings `yaml:",inline"`
+}
+
+// stepID must be usable as a template variable.
+var stepID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Load reads a pipeline file. Template, config and schema paths resolve against
+// the file's directory; the pipeline is validated before it is returned.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pipeline '%s': %w", path, err)
+	}
+	var p Pipeline
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid pipeline '%s': %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	dir := filepath.Dir(path)
+	if p.OutputDir != "" {
+		p.OutputDir = resolve(dir, p.OutputDir)
+	}
+	for i := range p.Steps {
+		s := &p.Steps[i]
+		s.Template = resolve(dir, s.Template)
+		s.Config = resolve(dir, s.Config)
+		s.Schema = resolve(dir, s.Schema)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline '%s': %w", path, err)
+	}
+	return &p, nil
+}
+
+func resolve(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Validate checks step ids, prompt sources, output names and that the steps form a DAG.
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	steps := map[string]*Step{}
+	outputs := map[string]string{}
+	for i := range p.Steps {
+		s := &p.Steps[i]
+		if !stepID.MatchString(s.ID) {
+			return fmt.Errorf("step %d: id %q must be letters, digits and underscores, not starting with a digit", i+1, s.ID)
+		}
+		if steps[s.ID] != nil {
+			return fmt.Errorf("duplicate step id %q", s.ID)
+		}
+		steps[s.ID] = s
+		if (s.Template == "") == (s.Prompt == "") {
+			return fmt.Errorf("step %q: set exactly one of template or prompt", s.ID)
+		}
+		if filepath.IsAbs(s.Output) || slices.Contains(strings.Split(filepath.ToSlash(s.Output), "/"), "..") {
+			return fmt.Errorf("step %q: output %q must be a path inside the output dir", s.ID, s.Output)
+		}
+		output := path.Clean(filepath.ToSlash(s.OutputFile()))
+		if output == StateFile {
+			return fmt.Errorf("step %q: output %q is reserved for the pipeline state", s.ID, s.Output)
+		}
+		if other, ok := outputs[output]; ok {
+			return fmt.Errorf("steps %q and %q both write %s", other, s.ID, output)
+		}
+		outputs[output] = s.ID
+	}
+	for _, s := range p.Steps {
+		if other, ok := outputs[s.PromptFile()]; ok {
+			return fmt.Errorf("step %q: output %s is reserved for the prompt of step %q", other, s.PromptFile(), s.ID)
+		}
+	}
+	for _, s := range p.Steps {
+		for _, need := range s.Needs {
+			if steps[need] == nil {
+				return fmt.Errorf("step %q needs unknown step %q", s.ID, need)
+			}
+		}
+	}
+	_, err := p.Order()
+	return err
+}
+
+// Order returns the steps in dependency order, keeping file order among independent steps.
+func (p *Pipeline) Order() ([]Step, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	index := map[string]int{}
+	for i, s := range p.Steps {
+		index[s.ID] = i
+	}
+	state := make([]int, len(p.Steps))
+	var order []Step
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		s := p.Steps[i]
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("steps form a cycle: %s", strings.Join(append(path, s.ID), " → "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, need := range s.Needs {
+			if err := visit(index[need], append(path, s.ID)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, s)
+		return nil
+	}
+	for i := range p.Steps {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// WantsJSON reports whether the step's answer is parsed as JSON.
+func (s Step) WantsJSON() bool {
+	return s.JSON || s.Schema != ""
+}
+
+// OutputFile returns the file name the step's answer is saved under.
+func (s Step) OutputFile() string {
+	if s.Output != "" {
+		return s.Output
+	}
+	if s.WantsJSON() {
+		return s.ID + ".json"
+	}
+	return s.ID + ".md"
+}
+
+// PromptFile returns the file name the step's rendered prompt is saved under.
+func (s Step) PromptFile() string {
+	return s.ID + ".prompt.txt"
+}
+
+// settings returns the step's model settings, falling back to defaults.
+func (s Step) settings(defaults Settings) Settings {
+	out := defaults
+	if s.Provider != "" {
+		out.Provider = s.Provider
+	}
+	if s.Model != "" {
+		out.Model = s.Model
+	}
+	if s.Temperature != nil {
+		out.Temperature = s.Temperature
+	}
+	return out
+}
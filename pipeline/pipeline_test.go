@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePipeline(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "study.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad_resolvesPathsAndDefaults(t *testing.T) {
+	path := writePipeline(t, `
+steps:
+  - id: analogy
+    template: templates/topic.yaml
+    config: /abs/git.yaml
+  - id: quiz
+    needs: [analogy]
+    schema: schemas/quiz.json
+    prompt: "Quiz on {{ analogy.text }}"
+    provider: openai
+`)
+	p, err := Load(path)
+	require.NoError(t, err)
+	dir := filepath.Dir(path)
+	assert.Equal(t, "study", p.Name)
+	assert.Equal(t, filepath.Join(dir, "templates/topic.yaml"), p.Steps[0].Template)
+	assert.Equal(t, "/abs/git.yaml", p.Steps[0].Config)
+	assert.Equal(t, filepath.Join(dir, "schemas/quiz.json"), p.Steps[1].Schema)
+	assert.Equal(t, "quiz.json", p.Steps[1].OutputFile())
+	assert.Equal(t, "analogy.md", p.Steps[0].OutputFile())
+	assert.Equal(t, "openai", p.Steps[1].Provider)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  string
+	}{
+		{"no steps", nil, "no steps"},
+		{"bad id", []Step{{ID: "key-terms", Prompt: "x"}}, "letters, digits and underscores"},
+		{"duplicate", []Step{{ID: "a", Prompt: "x"}, {ID: "a", Prompt: "y"}}, "duplicate step id"},
+		{"no source", []Step{{ID: "a"}}, "exactly one of template or prompt"},
+		{"two sources", []Step{{ID: "a", Prompt: "x", Template: "t.yaml"}}, "exactly one of template or prompt"},
+		{"unknown need", []Step{{ID: "a", Prompt: "x", Needs: []string{"b"}}}, `needs unknown step "b"`},
+		{"absolute output", []Step{{ID: "a", Prompt: "x", Output: "/etc/a.md"}}, `output "/etc/a.md" must be a path inside the output dir`},
+		{"escaping output", []Step{{ID: "a", Prompt: "x", Output: "notes/../../a.md"}}, "must be a path inside the output dir"},
+		{"same output", []Step{{ID: "a", Prompt: "x"}, {ID: "b", Prompt: "y", Output: "a.md"}}, "both write a.md"},
+		{"same cleaned output", []Step{{ID: "a", Prompt: "x"}, {ID: "b", Prompt: "y", Output: "./a.md"}}, "both write a.md"},
+		{"state file output", []Step{{ID: "a", Prompt: "x", Output: "pipeline-state.json"}}, "reserved for the pipeline state"},
+		{"prompt file output", []Step{{ID: "a", Prompt: "x"}, {ID: "b", Prompt: "y", Output: "a.prompt.txt"}}, `output a.prompt.txt is reserved for the prompt of step "a"`},
+		{"cycle", []Step{
+			{ID: "a", Prompt: "x", Needs: []string{"c"}},
+			{ID: "b", Prompt: "y", Needs: []string{"a"}},
+			{ID: "c", Prompt: "z", Needs: []string{"b"}},
+		}, "cycle: a → c → b → a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Pipeline{Steps: tt.steps}).Validate()
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestOrder_dependenciesFirst(t *testing.T) {
+	p := &Pipeline{Steps: []Step{
+		{ID: "quiz", Prompt: "q", Needs: []string{"terms", "analogy"}},
+		{ID: "terms", Prompt: "t", Needs: []string{"analogy"}},
+		{ID: "analogy", Prompt: "a"},
+		{ID: "summary", Prompt: "s"},
+	}}
+	order, err := p.Order()
+	require.NoError(t, err)
+	var ids []string
+	for _, s := range order {
+		ids = append(ids, s.ID)
+	}
+	assert.Equal(t, []string{"analogy", "terms", "quiz", "summary"}, ids)
+}
@@ -0,0 +1,363 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// -------------------- Execution --------------------
+
+// StateFile records finished steps in the output dir so a failed run resumes
+// where it stopped.
+const StateFile = "pipeline-state.json"
+
+// Step statuses.
+const (
+	StatusDone    = "done"    // Called the model and saved the answer
+	StatusReused  = "reused"  // Same prompt as a previous run; its saved answer was used
+	StatusFailed  = "failed"  // The step itself failed
+	StatusSkipped = "skipped" // A step it needs did not succeed
+)
+
+// Client is the model call a step makes; *llm.Client implements it.
+type Client interface {
+	Generate(ctx context.Context, prompt string) (*llm.Response, error)
+	ChatJSON(ctx context.Context, prompt string, schema *llm.Schema, maxRepairs int) (string, error)
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	ID       string
+	Status   string
+	Output   string // Path of the saved answer
+	Target   string // provider:model
+	Duration time.Duration
+	Error    string
+}
+
+// Result summarizes a run, with steps in dependency order.
+type Result struct {
+	OutputDir string
+	Steps     []StepResult
+}
+
+// Failed returns the ids of steps that failed or were skipped.
+func (r *Result) Failed() []string {
+	var ids []string
+	for _, s := range r.Steps {
+		if s.Status == StatusFailed || s.Status == StatusSkipped {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// Runner executes pipelines, running independent steps concurrently.
+type Runner struct {
+	NewClient   func(s Settings) (Client, error)
+	Concurrency int              // Steps in flight at once (default 4)
+	JSONRepairs int              // Re-prompts allowed when a JSON answer fails validation
+	Fresh       bool             // Ignore answers saved by previous runs
+	OnResult    func(StepResult) // Called as each step finishes
+}
+
+// stepOutput is what a finished step passes to the steps that need it.
+type stepOutput struct {
+	text string
+	json any
+}
+
+// stepState is a finished step in the state file.
+type stepState struct {
+	PromptHash string `json:"prompt_sha256"`
+	Output     string `json:"output"`
+}
+
+// run holds the shared state of one Run.
+type run struct {
+	*Runner
+	pipeline *Pipeline
+	dir      string
+
+	mu      sync.Mutex
+	outputs map[string]stepOutput
+	results map[string]StepResult
+	state   map[string]stepState
+}
+
+// Run executes the pipeline, saving each answer in outDir. Steps whose rendered
+// prompt matches a step finished by an earlier run reuse its answer unless Fresh
+// is set. Step failures are reported in the result; Run returns an error when
+// the pipeline cannot start or ctx is cancelled.
+func (r *Runner) Run(ctx context.Context, p *Pipeline, outDir string) (*Result, error) {
+	order, err := p.Order()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output dir '%s': %w", outDir, err)
+	}
+	state := map[string]stepState{}
+	if !r.Fresh {
+		if state, err = loadState(outDir); err != nil {
+			return nil, err
+		}
+	}
+
+	x := &run{Runner: r, pipeline: p, dir: outDir, outputs: map[string]stepOutput{}, results: map[string]StepResult{}, state: state}
+	workers := r.Concurrency
+	if workers < 1 {
+		workers = 4
+	}
+	sem := make(chan struct{}, workers)
+	done := map[string]chan struct{}{}
+	for _, s := range order {
+		done[s.ID] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range order {
+		wg.Add(1)
+		go func(s Step) {
+			defer wg.Done()
+			defer close(done[s.ID])
+			for _, need := range s.Needs {
+				<-done[need]
+			}
+			if blocked := x.blockedBy(s); blocked != "" {
+				x.finish(StepResult{ID: s.ID, Status: StatusSkipped, Error: fmt.Sprintf("needs %q, which did not succeed", blocked)})
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				x.finish(StepResult{ID: s.ID, Status: StatusFailed, Error: ctx.Err().Error()})
+				return
+			}
+			defer func() { <-sem }()
+			x.finish(x.runStep(ctx, s))
+		}(s)
+	}
+	wg.Wait()
+
+	res := &Result{OutputDir: outDir}
+	for _, s := range order {
+		res.Steps = append(res.Steps, x.results[s.ID])
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	return res, nil
+}
+
+// blockedBy returns the first need of s that did not succeed.
+func (x *run) blockedBy(s Step) string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, need := range s.Needs {
+		if st := x.results[need].Status; st != StatusDone && st != StatusReused {
+			return need
+		}
+	}
+	return ""
+}
+
+func (x *run) finish(res StepResult) {
+	x.mu.Lock()
+	x.results[res.ID] = res
+	x.mu.Unlock()
+	if x.OnResult != nil {
+		x.OnResult(res)
+	}
+}
+
+func (x *run) runStep(ctx context.Context, s Step) StepResult {
+	start := time.Now()
+	settings := s.settings(x.pipeline.Defaults)
+	res := StepResult{ID: s.ID, Target: settings.Provider + ":" + settings.Model, Output: filepath.Join(x.dir, s.OutputFile())}
+	fail := func(err error) StepResult {
+		res.Status, res.Error, res.Duration = StatusFailed, err.Error(), time.Since(start)
+		return res
+	}
+
+	text, schema, err := x.render(s)
+	if err != nil {
+		return fail(err)
+	}
+	hash := promptHash(settings, text)
+
+	if out, ok := x.reuse(s, hash, res.Output); ok {
+		x.setOutput(s.ID, out)
+		res.Status, res.Duration = StatusReused, time.Since(start)
+		return res
+	}
+
+	client, err := x.NewClient(settings)
+	if err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(filepath.Join(x.dir, s.PromptFile()), []byte(text), 0644); err != nil {
+		return fail(err)
+	}
+
+	var answer string
+	var resp *llm.Response
+	if schema != nil {
+		answer, err = client.ChatJSON(ctx, text, schema, x.JSONRepairs)
+	} else if resp, err = client.Generate(ctx, text); err == nil {
+		answer = resp.Text
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	out, err := parseOutput(s, answer)
+	if err != nil {
+		return fail(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(res.Output), 0755); err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(res.Output, []byte(answer), 0644); err != nil {
+		return fail(err)
+	}
+	target := llm.Target{Provider: settings.Provider, Model: settings.Model}
+	if err := llm.SaveMetadata(res.Output, llm.NewResponseMetadata(target, resp)); err != nil {
+		return fail(err)
+	}
+	x.setOutput(s.ID, out)
+	if err := x.saveState(s.ID, stepState{PromptHash: hash, Output: s.OutputFile()}); err != nil {
+		return fail(err)
+	}
+	res.Status, res.Duration = StatusDone, time.Since(start)
+	return res
+}
+
+// render builds the step's prompt from its template or inline prompt, with the
+// config's fields and the outputs of the steps it needs, and returns the JSON
+// schema for JSON steps.
+func (x *run) render(s Step) (string, *llm.Schema, error) {
+	ctx := pongo2.Context{}
+	if s.Config != "" {
+		cfgCtx, err := prompt.ConfigContext(s.Config)
+		if err != nil {
+			return "", nil, err
+		}
+		ctx.Update(cfgCtx)
+	}
+	x.mu.Lock()
+	for _, need := range s.Needs {
+		out := x.outputs[need]
+		ctx[need] = map[string]any{"text": out.text, "json": out.json}
+	}
+	x.mu.Unlock()
+
+	source, outputSchema := s.Prompt, map[string]any(nil)
+	if s.Template != "" {
+		tpl, err := promptConfig.ReadTemplate(s.Template)
+		if err != nil {
+			return "", nil, fmt.Errorf("error reading template '%s': %w", s.Template, err)
+		}
+		source, outputSchema = tpl.Template, tpl.OutputSchema
+	}
+	text, err := prompt.Render(source, ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !s.WantsJSON() {
+		return text, nil, nil
+	}
+	var schema *llm.Schema
+	switch {
+	case s.Schema != "":
+		schema, err = llm.LoadSchema(s.Schema)
+	case len(outputSchema) > 0:
+		schema, err = llm.SchemaFromMap(outputSchema)
+	default:
+		schema, err = llm.SchemaFromMap(map[string]any{})
+	}
+	return text, schema, err
+}
+
+// reuse returns the saved answer of a step finished by an earlier run with the same prompt.
+func (x *run) reuse(s Step, hash, path string) (stepOutput, bool) {
+	x.mu.Lock()
+	prev, ok := x.state[s.ID]
+	x.mu.Unlock()
+	if !ok || prev.PromptHash != hash || prev.Output != s.OutputFile() {
+		return stepOutput{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stepOutput{}, false
+	}
+	out, err := parseOutput(s, string(data))
+	return out, err == nil
+}
+
+func (x *run) setOutput(id string, out stepOutput) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.outputs[id] = out
+}
+
+func (x *run) saveState(id string, st stepState) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.state[id] = st
+	data, err := json.MarshalIndent(x.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(x.dir, StateFile), append(data, '\n'), 0644)
+}
+
+func loadState(dir string) (map[string]stepState, error) {
+	state := map[string]stepState{}
+	data, err := os.ReadFile(filepath.Join(dir, StateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid pipeline state '%s': %w", filepath.Join(dir, StateFile), err)
+	}
+	return state, nil
+}
+
+// parseOutput parses JSON answers so later templates can use their fields.
+func parseOutput(s Step, answer string) (stepOutput, error) {
+	out := stepOutput{text: answer}
+	if s.WantsJSON() {
+		if err := json.Unmarshal([]byte(answer), &out.json); err != nil {
+			return out, fmt.Errorf("step %q: answer is not JSON: %w", s.ID, err)
+		}
+	}
+	return out, nil
+}
+
+// promptHash identifies a step's call, so changed prompts or model settings are re-run.
+func promptHash(s Settings, text string) string {
+	temperature := "default"
+	if s.Temperature != nil {
+		temperature = fmt.Sprint(*s.Temperature)
+	}
+	sum := sha256.Sum256([]byte(s.Provider + ":" + s.Model + "@" + temperature + "\n" + text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// recordingClient answers from a function and records the prompts it is sent.
+type recordingClient struct {
+	mu      sync.Mutex
+	prompts []string
+	answer  func(prompt string) (string, error)
+}
+
+func (c *recordingClient) Generate(_ context.Context, prompt string) (*llm.Response, error) {
+	text, err := c.call(prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.Response{Text: text}, nil
+}
+
+func (c *recordingClient) ChatJSON(_ context.Context, prompt string, _ *llm.Schema, _ int) (string, error) {
+	return c.call(prompt)
+}
+
+func (c *recordingClient) call(prompt string) (string, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	c.mu.Unlock()
+	return c.answer(prompt)
+}
+
+func (c *recordingClient) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.prompts)
+}
+
+func (c *recordingClient) runner() *Runner {
+	return &Runner{NewClient: func(Settings) (Client, error) { return c, nil }}
+}
+
+func chainPipeline() *Pipeline {
+	return &Pipeline{Name: "chain", Steps: []Step{
+		{ID: "terms", Prompt: "List terms", JSON: true},
+		{ID: "summary", Prompt: "Summarize {{ terms.json.terms|join:\", \" }}", Needs: []string{"terms"}},
+	}}
+}
+
+func chainAnswer(prompt string) (string, error) {
+	if prompt == "List terms" {
+		return `{"terms": ["commit", "branch"]}`, nil
+	}
+	return "Summary of " + strings.TrimPrefix(prompt, "Summarize "), nil
+}
+
+func TestRun_passesOutputsToLaterSteps(t *testing.T) {
+	dir := t.TempDir()
+	client := &recordingClient{answer: chainAnswer}
+
+	res, err := client.runner().Run(context.Background(), chainPipeline(), dir)
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed())
+	assert.Equal(t, StatusDone, res.Steps[1].Status)
+
+	data, err := os.ReadFile(filepath.Join(dir, "summary.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Summary of commit, branch", string(data))
+	assert.FileExists(t, filepath.Join(dir, "terms.json"))
+	assert.FileExists(t, filepath.Join(dir, "summary.prompt.txt"))
+	assert.FileExists(t, filepath.Join(dir, "summary.meta.json"))
+
+	var state map[string]stepState
+	data, err = os.ReadFile(filepath.Join(dir, StateFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, "summary.md", state["summary"].Output)
+}
+
+func TestRun_createsOutputSubdirs(t *testing.T) {
+	dir := t.TempDir()
+	client := &recordingClient{answer: chainAnswer}
+	p := chainPipeline()
+	p.Steps[1].Output = "notes/git/summary.md"
+
+	res, err := client.runner().Run(context.Background(), p, dir)
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed())
+	assert.FileExists(t, filepath.Join(dir, "notes", "git", "summary.md"))
+}
+
+func TestRun_skipsStepsAfterFailureAndResumes(t *testing.T) {
+	dir := t.TempDir()
+	p := &Pipeline{Name: "resume", Steps: []Step{
+		{ID: "a", Prompt: "A"},
+		{ID: "b", Prompt: "B {{ a.text }}", Needs: []string{"a"}},
+		{ID: "c", Prompt: "C {{ b.text }}", Needs: []string{"b"}},
+	}}
+	failB := true
+	client := &recordingClient{answer: func(prompt string) (string, error) {
+		if strings.HasPrefix(prompt, "B") && failB {
+			return "", errors.New("provider down")
+		}
+		return strings.ToLower(prompt), nil
+	}}
+
+	res, err := client.runner().Run(context.Background(), p, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, res.Failed())
+	assert.Equal(t, StatusFailed, res.Steps[1].Status)
+	assert.Contains(t, res.Steps[1].Error, "provider down")
+	assert.Equal(t, StatusSkipped, res.Steps[2].Status)
+	assert.Equal(t, 2, client.calls())
+
+	failB = false
+	res, err = client.runner().Run(context.Background(), p, dir)
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed())
+	assert.Equal(t, StatusReused, res.Steps[0].Status)
+	assert.Equal(t, StatusDone, res.Steps[1].Status)
+	assert.Equal(t, StatusDone, res.Steps[2].Status)
+	assert.Equal(t, 4, client.calls())
+
+	data, err := os.ReadFile(filepath.Join(dir, "c.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "c b a", string(data))
+}
+
+func TestRun_rerunsChangedAndFreshSteps(t *testing.T) {
+	dir := t.TempDir()
+	client := &recordingClient{answer: chainAnswer}
+	_, err := client.runner().Run(context.Background(), chainPipeline(), dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, client.calls())
+
+	changed := chainPipeline()
+	changed.Steps[1].Prompt = "Summarize briefly {{ terms.text }}"
+	res, err := client.runner().Run(context.Background(), changed, dir)
+	require.NoError(t, err)
+	assert.Equal(t, StatusReused, res.Steps[0].Status)
+	assert.Equal(t, StatusDone, res.Steps[1].Status)
+	assert.Equal(t, 3, client.calls())
+
+	runner := client.runner()
+	runner.Fresh = true
+	_, err = runner.Run(context.Background(), changed, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 5, client.calls())
+}
+
+func TestRun_rejectsNonJSONAnswers(t *testing.T) {
+	client := &recordingClient{answer: func(string) (string, error) { return "not json", nil }}
+	res, err := client.runner().Run(context.Background(), chainPipeline(), t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, res.Steps[0].Status)
+	assert.Contains(t, res.Steps[0].Error, "answer is not JSON")
+	assert.Equal(t, StatusSkipped, res.Steps[1].Status)
+}
+
+func TestRun_usesStepSettings(t *testing.T) {
+	temp := 0.7
+	p := &Pipeline{
+		Defaults: Settings{Provider: "fake", Model: "base"},
+		Steps: []Step{
+			{ID: "a", Prompt: "A"},
+			{ID: "b", Prompt: "B", Settings: Settings{Model: "other", Temperature: &temp}},
+		},
+	}
+	var mu sync.Mutex
+	seen := map[string]Settings{}
+	client := &recordingClient{answer: func(p string) (string, error) { return p, nil }}
+	runner := &Runner{NewClient: func(s Settings) (Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[s.Model] = s
+		return client, nil
+	}}
+
+	res, err := runner.Run(context.Background(), p, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "fake:base", res.Steps[0].Target)
+	assert.Equal(t, "fake:other", res.Steps[1].Target)
+	assert.Nil(t, seen["base"].Temperature)
+	assert.Equal(t, 0.7, *seen["other"].Temperature)
+}
@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"fmt"
 	"log"
 	"os"
 
@@ -16,7 +17,22 @@ func BuildTopicPrompt(templateFile, configFile, outputFile string) {
 	log.Printf("[topic] Loading config: %s", configFile)
 	cfg := mustReadTopicConfig(configFile)
 
-	ctx := pongo2.Context{
+	renderAndSave(tpl.Template, TopicContext(cfg), outputFile)
+}
+
+func BuildChartPrompt(templateFile, configFile, outputFile string) {
+	log.Printf("[chart] Loading template: %s", templateFile)
+	tpl := mustReadTemplate(templateFile)
+
+	log.Printf("[chart] Loading config: %s", configFile)
+	cfg := mustReadChartConfig(configFile)
+
+	renderAndSave(tpl.Template, ChartContext(cfg), outputFile)
+}
+
+// TopicContext returns the template variables of a topic config.
+func TopicContext(cfg promptConfig.TopicConfig) pongo2.Context {
+	return pongo2.Context{
 		"audience":                 cfg.Audience,
 		"learning_stage":           cfg.LearningStage,
 		"topic":                    cfg.Topic,
@@ -30,28 +46,99 @@ func BuildTopicPrompt(templateFile, configFile, outputFile string) {
 		"purpose":                  cfg.Purpose,
 		"tone":                     cfg.Tone,
 	}
+}
 
-	renderAndSave(tpl.Template, ctx, outputFile)
+// ChartContext returns the template variables of a flowchart config. Phases,
+// steps and links are maps keyed like the YAML, and all_steps lists the steps
+// of both phases.
+func ChartContext(cfg promptConfig.ChartConfig) pongo2.Context {
+	allSteps := append(stepsContext(cfg.PlanningPhase.Steps), stepsContext(cfg.ExecutionPhase.Steps)...)
+	return pongo2.Context{
+		"flow_direction":  cfg.FlowDirection,
+		"style":           cfg.Style,
+		"planning_phase":  phaseContext(cfg.PlanningPhase),
+		"planning_links":  linksContext(cfg.PlanningLinks),
+		"execution_phase": phaseContext(cfg.ExecutionPhase),
+		"execution_links": linksContext(cfg.ExecutionLinks),
+		"transition_link": linkContext(cfg.TransitionLink),
+		"all_steps":       allSteps,
+	}
 }
 
-func BuildChartPrompt(templateFile, configFile, outputFile string) {
-	log.Printf("[chart] Loading template: %s", templateFile)
-	tpl := mustReadTemplate(templateFile)
+func phaseContext(p promptConfig.Phase) map[string]any {
+	return map[string]any{
+		"title":     p.Title,
+		"emoji":     p.Emoji,
+		"direction": p.Direction,
+		"steps":     stepsContext(p.Steps),
+	}
+}
 
-	log.Printf("[chart] Loading config: %s", configFile)
-	cfg := mustReadChartConfig(configFile)
+func stepsContext(steps []promptConfig.Step) []map[string]any {
+	out := make([]map[string]any, len(steps))
+	for i, s := range steps {
+		out[i] = map[string]any{"id": s.ID, "emoji": s.Emoji, "title": s.Title, "description": s.Description}
+	}
+	return out
+}
 
-	ctx := pongo2.Context{
-		"flow_direction":  cfg.FlowDirection,
-		"style":           cfg.Style,
-		"planning_phase":  cfg.PlanningPhase,
-		"planning_links":  cfg.PlanningLinks,
-		"execution_phase": cfg.ExecutionPhase,
-		"execution_links": cfg.ExecutionLinks,
-		"transition_link": cfg.TransitionLink,
+func linksContext(links []promptConfig.Link) []map[string]any {
+	out := make([]map[string]any, len(links))
+	for i, l := range links {
+		out[i] = linkContext(l)
 	}
+	return out
+}
 
-	renderAndSave(tpl.Template, ctx, outputFile)
+func linkContext(l promptConfig.Link) map[string]any {
+	return map[string]any{"from": l.From, "to": l.To}
+}
+
+// DetectConfigType peeks into the YAML keys of a config to tell a "topic"
+// config from a "chart" config; anything else is "unknown".
+func DetectConfigType(configFile string) (string, error) {
+	raw, err := promptConfig.ReadYAML[map[string]any](configFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	switch {
+	case hasKey(raw, "planning_phase") && hasKey(raw, "execution_phase"):
+		return "chart", nil
+	case hasKey(raw, "audience"):
+		return "topic", nil
+	default:
+		return "unknown", nil
+	}
+}
+
+// ConfigContext reads a topic or chart config and returns its template variables.
+func ConfigContext(configFile string) (pongo2.Context, error) {
+	kind, err := DetectConfigType(configFile)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "topic":
+		cfg, err := promptConfig.ReadTopicConfig(configFile)
+		return TopicContext(cfg), err
+	case "chart":
+		cfg, err := promptConfig.ReadChartConfig(configFile)
+		return ChartContext(cfg), err
+	}
+	return nil, fmt.Errorf("unsupported prompt type detected from config '%s'", configFile)
+}
+
+// Render executes a template string with ctx.
+func Render(tplStr string, ctx pongo2.Context) (string, error) {
+	tpl, err := pongo2.FromString(tplStr)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+	output, err := tpl.Execute(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error rendering template: %w", err)
+	}
+	return output, nil
 }
 
 // -------------------- Internal Helpers --------------------
@@ -96,6 +183,11 @@ func renderAndSave(tplStr string, ctx pongo2.Context, outputPath string) {
 	writePrompt(outputPath, output)
 }
 
+func hasKey(m map[string]any, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
 func writePrompt(path, content string) {
 	log.Printf("[output] Writing to: %s", path)
 	paths.EnsureDirectoryExists(path)
@@ -50,6 +50,50 @@ func TestBuildTopicPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildChartPrompt(t *testing.T) {
+	dir := t.TempDir()
+	tplPath := filepath.Join(dir, "template.yaml")
+	cfgPath := filepath.Join(dir, "config.yaml")
+	outPath := filepath.Join(dir, "output.txt")
+
+	writeFile(t, tplPath, `template: |
+  flowchart {{ flow_direction }}
+  subgraph [{{ planning_phase.emoji }} {{ planning_phase.title }}]
+  {% for step in planning_phase.steps %}{{ step.id }}([{{ step.title }}: {{ step.description }}])
+  {% endfor %}{% for link in planning_links %}{{ link.from }} --> {{ link.to }}
+  {% endfor %}{{ transition_link.from }} --> {{ transition_link.to }}
+  class {% for step in all_steps %}{{ step.id }}{% if not forloop.Last %},{% endif %}{% endfor %} box`)
+	writeFile(t, cfgPath, `
+flow_direction: LR
+planning_phase:
+  title: Plan
+  emoji: "🧠"
+  steps:
+    - {id: A, title: List, description: Write it down}
+    - {id: B, title: Sort, description: Group it}
+planning_links:
+  - {from: A, to: B}
+execution_phase:
+  title: Do
+  steps:
+    - {id: C, title: Start, description: Begin}
+transition_link: {from: B, to: C}
+`)
+
+	BuildChartPrompt(tplPath, cfgPath, outPath)
+
+	expected := `flowchart LR
+subgraph [🧠 Plan]
+A([List: Write it down])
+B([Sort: Group it])
+A --> B
+B --> C
+class A,B,C box`
+	if got := strings.TrimSpace(readFile(t, outPath)); got != expected {
+		t.Errorf("\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
 // Helpers
 
 func writeFile(t *testing.T, path, content string) {
@@ -0,0 +1,13 @@
+package scaffold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert.Empty(t, Diff("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "  a\n- b\n+ B\n  c\n+ d\n", Diff("a\nb\nc\n", "a\nB\nc\nd\n"))
+	assert.Equal(t, "- old\n+ new\n", Diff("old", "new\n"))
+}
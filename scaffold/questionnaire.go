@@ -0,0 +1,109 @@
+package scaffold
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Questionnaire --------------------
+
+// Prompter asks questions on out and reads the answers from in, one per line.
+// Like bufio.Scanner, it stops at the first read error and reports it from Err.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+	err error
+}
+
+// NewPrompter returns a prompter reading answers from in.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Err returns the first error reading answers, if any.
+func (p *Prompter) Err() error {
+	return p.err
+}
+
+// readLine returns the next line without its newline. At the end of the input
+// it returns what is left, and "" from then on.
+func (p *Prompter) readLine() string {
+	if p.eof || p.err != nil {
+		return ""
+	}
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		p.eof = true
+	} else if err != nil {
+		p.err = err
+		return ""
+	}
+	return strings.TrimSpace(line)
+}
+
+// Ask asks question and returns the answer, or def when the answer is blank.
+func (p *Prompter) Ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if answer := p.readLine(); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// AskList reads one item per line until a blank line. A blank first line keeps def.
+func (p *Prompter) AskList(question string, def []string) []string {
+	fmt.Fprintf(p.out, "%s (one per line, blank line to finish", question)
+	if len(def) > 0 {
+		fmt.Fprintf(p.out, "; blank now keeps the %d current entries", len(def))
+	}
+	fmt.Fprintln(p.out, "):")
+	var items []string
+	for {
+		fmt.Fprint(p.out, "  - ")
+		item := p.readLine()
+		if item == "" {
+			break
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return def
+	}
+	return items
+}
+
+// Confirm asks a yes/no question; anything but "y" or "yes" is no.
+func (p *Prompter) Confirm(question string) bool {
+	fmt.Fprintf(p.out, "%s [y/N]: ", question)
+	answer := strings.ToLower(p.readLine())
+	return answer == "y" || answer == "yes"
+}
+
+// AskTopic walks through every field of a topic config, offering the values of
+// base as defaults.
+func AskTopic(p *Prompter, base promptConfig.TopicConfig) (promptConfig.TopicConfig, error) {
+	cfg := base
+	cfg.Topic = p.Ask("Topic", cfg.Topic)
+	cfg.Audience = p.Ask("Audience", cfg.Audience)
+	cfg.LearningStage = p.Ask("Learning stage", cfg.LearningStage)
+	cfg.Context = p.Ask("Context (e.g. student)", cfg.Context)
+	cfg.Analogies = p.Ask("Analogies", cfg.Analogies)
+	cfg.Concepts = p.AskList(`Concepts, as "**Term**: what to explain"`, cfg.Concepts)
+	cfg.ExplanationRequirements = p.AskList("Explanation requirements", cfg.ExplanationRequirements)
+	cfg.Formatting = p.AskList("Formatting rules", cfg.Formatting)
+	cfg.Constraints = p.AskList("Constraints", cfg.Constraints)
+	cfg.OutputFormat = p.AskList("Output format", cfg.OutputFormat)
+	cfg.Purpose = p.Ask("Purpose", cfg.Purpose)
+	cfg.Tone = p.Ask("Tone", cfg.Tone)
+	return cfg, p.Err()
+}
@@ -0,0 +1,58 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+func TestAskTopic(t *testing.T) {
+	answers := strings.Join([]string{
+		"",               // Topic: keep "Docker"
+		"backend devs",   // Audience
+		"",               // Learning stage: keep "intermediate"
+		"work",           // Context
+		"shipping",       // Analogies
+		"**Images**: a",  // Concepts
+		"**Volumes**: b", //
+		"",               //
+		"",               // Explanation requirements: keep the defaults
+		"Use headers",    // Formatting
+		"",               //
+		"",               // Constraints: none
+		"",               // Output format: none
+		"packaging",      // Purpose
+	}, "\n") // Tone is missing: the input ends
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader(answers), &out)
+
+	base := promptConfig.TopicConfig{Topic: "Docker", LearningStage: "intermediate", ExplanationRequirements: []string{"Show a Dockerfile"}}
+	cfg, err := AskTopic(p, base)
+	require.NoError(t, err)
+
+	assert.Equal(t, promptConfig.TopicConfig{
+		Topic:                   "Docker",
+		Audience:                "backend devs",
+		LearningStage:           "intermediate",
+		Context:                 "work",
+		Analogies:               "shipping",
+		Concepts:                []string{"**Images**: a", "**Volumes**: b"},
+		ExplanationRequirements: []string{"Show a Dockerfile"},
+		Formatting:              []string{"Use headers"},
+		Purpose:                 "packaging",
+	}, cfg)
+	assert.Contains(t, out.String(), "Topic [Docker]: Audience: ")
+	assert.Contains(t, out.String(), "Explanation requirements (one per line, blank line to finish; blank now keeps the 1 current entries):\n  - ")
+}
+
+func TestPrompter_Confirm(t *testing.T) {
+	p := NewPrompter(strings.NewReader("Yes\nn\n"), &strings.Builder{})
+	assert.True(t, p.Confirm("Write?"))
+	assert.False(t, p.Confirm("Write?"))
+	assert.False(t, p.Confirm("Write?"), "no input is no")
+	assert.NoError(t, p.Err())
+}
@@ -0,0 +1,169 @@
+package scaffold
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// -------------------- Topic Configs --------------------
+
+// TopicRequest is what the user decides about a new topic; the rest is proposed.
+type TopicRequest struct {
+	Topic    string // Display name, e.g. "Docker"
+	Audience string
+	Stage    string // Learning stage, e.g. "intermediate"
+}
+
+// Base returns the config with only the requested fields set.
+func (r TopicRequest) Base() promptConfig.TopicConfig {
+	return promptConfig.TopicConfig{Topic: r.Topic, Audience: r.Audience, LearningStage: r.Stage}
+}
+
+// conceptFormat is how concepts are written, so coverage and the glossary tool
+// can tell the term from what to explain about it.
+var conceptFormat = regexp.MustCompile(`^\*\*[^*]+\*\*: \S`)
+
+// TopicSchema is the JSON Schema of the fields the model proposes.
+func TopicSchema() map[string]any {
+	text := map[string]any{"type": "string", "minLength": 1}
+	list := func(min int, item map[string]any) map[string]any {
+		return map[string]any{"type": "array", "minItems": min, "items": item}
+	}
+	return map[string]any{
+		"type": "object",
+		"required": []any{"context", "analogies", "concepts", "explanation_requirements",
+			"formatting", "constraints", "output_format", "purpose", "tone"},
+		"properties": map[string]any{
+			"context":   text,
+			"analogies": text,
+			"concepts": list(3, map[string]any{
+				"type": "string", "pattern": conceptFormat.String(),
+			}),
+			"explanation_requirements": list(2, text),
+			"formatting":               list(2, text),
+			"constraints":              list(2, text),
+			"output_format":            list(2, text),
+			"purpose":                  text,
+			"tone":                     text,
+		},
+	}
+}
+
+// TopicPrompt asks for the teaching plan of the requested topic.
+func TopicPrompt(r TopicRequest) string {
+	return fmt.Sprintf("You plan tutorials. Propose how to teach the topic below, as the fields of a tutorial config.\n\n"+
+		"Topic: %s\nAudience: %s\nLearning stage: %s\n\n"+
+		"Fields:\n"+
+		"- context: the setting the audience learns in, in a few words (e.g. \"student\")\n"+
+		"- analogies: one or two everyday situations the tutorial can compare the topic to\n"+
+		"- concepts: 5 to 8 key concepts, each written as \"**Term**: what to explain about it\"\n"+
+		"- explanation_requirements: 4 to 6 things the explanation must do\n"+
+		"- formatting: 3 to 5 formatting rules\n"+
+		"- constraints: 3 to 5 things to avoid or keep in mind for this audience\n"+
+		"- output_format: 4 to 7 parts the tutorial should have, in order\n"+
+		"- purpose: what the topic is used for, in a few words\n"+
+		"- tone: the voice of the tutorial, in a few words\n",
+		r.Topic, r.Audience, r.Stage)
+}
+
+// ProposeTopic asks gen to fill in the config of the requested topic.
+func ProposeTopic(ctx context.Context, gen llm.JSONGenerator, r TopicRequest, maxRepairs int) (promptConfig.TopicConfig, error) {
+	var cfg promptConfig.TopicConfig
+	schema, err := llm.SchemaFromMap(TopicSchema())
+	if err != nil {
+		return cfg, err
+	}
+	doc, err := gen.ChatJSON(ctx, TopicPrompt(r), schema, maxRepairs)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal([]byte(doc), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid topic config: %w", err)
+	}
+	cfg.Topic, cfg.Audience, cfg.LearningStage = r.Topic, r.Audience, r.Stage
+	return cfg, nil
+}
+
+// ValidateTopic returns the problems that keep cfg from being used as a topic config.
+func ValidateTopic(cfg promptConfig.TopicConfig) []string {
+	var problems []string
+	for _, f := range []struct{ name, value string }{
+		{"audience", cfg.Audience}, {"learning_stage", cfg.LearningStage}, {"topic", cfg.Topic},
+		{"purpose", cfg.Purpose}, {"tone", cfg.Tone},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			problems = append(problems, fmt.Sprintf("%s is empty", f.name))
+		}
+	}
+	if len(cfg.Concepts) == 0 {
+		problems = append(problems, "concepts is empty")
+	}
+	for _, c := range cfg.Concepts {
+		if !conceptFormat.MatchString(c) {
+			problems = append(problems, fmt.Sprintf("concept %q should read \"**Term**: what to explain\"", c))
+		}
+	}
+	for _, f := range listFields(cfg) {
+		seen := map[string]bool{}
+		for _, item := range f.items {
+			key := strings.ToLower(strings.TrimSpace(item))
+			if key == "" {
+				problems = append(problems, fmt.Sprintf("%s has an empty entry", f.name))
+			} else if seen[key] {
+				problems = append(problems, fmt.Sprintf("%s lists %q twice", f.name, item))
+			}
+			seen[key] = true
+		}
+	}
+	return problems
+}
+
+type listField struct {
+	name  string
+	items []string
+}
+
+func listFields(cfg promptConfig.TopicConfig) []listField {
+	return []listField{
+		{"concepts", cfg.Concepts},
+		{"explanation_requirements", cfg.ExplanationRequirements},
+		{"formatting", cfg.Formatting},
+		{"constraints", cfg.Constraints},
+		{"output_format", cfg.OutputFormat},
+	}
+}
+
+// MarshalTopic writes cfg as YAML laid out like resources/configs/git.yaml.
+func MarshalTopic(cfg promptConfig.TopicConfig) []byte {
+	var b strings.Builder
+	scalar := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\n", name, strconv.Quote(value))
+	}
+	scalar("audience", cfg.Audience)
+	scalar("learning_stage", cfg.LearningStage)
+	scalar("topic", cfg.Topic)
+	scalar("context", cfg.Context)
+	scalar("analogies", cfg.Analogies)
+	for _, f := range listFields(cfg) {
+		if len(f.items) == 0 {
+			fmt.Fprintf(&b, "\n%s: []\n", f.name)
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", f.name)
+		for _, item := range f.items {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(item))
+		}
+	}
+	b.WriteString("\n")
+	scalar("purpose", cfg.Purpose)
+	scalar("tone", cfg.Tone)
+	return []byte(b.String())
+}
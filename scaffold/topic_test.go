@@ -0,0 +1,110 @@
+package scaffold
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+
+	promptConfig "raja.aiml/ai.explorer/config/prompt"
+)
+
+// fixedDraft returns doc as the model's JSON answer and records the prompt.
+type fixedDraft struct {
+	doc    string
+	prompt string
+}
+
+func (d *fixedDraft) ChatJSON(_ context.Context, prompt string, schema *llm.Schema, _ int) (string, error) {
+	d.prompt = prompt
+	if errs := schema.Validate([]byte(d.doc)); len(errs) > 0 {
+		return "", &llm.SchemaValidationError{Errors: errs, Response: d.doc}
+	}
+	return d.doc, nil
+}
+
+const draftJSON = `{
+  "topic": "ignored", "audience": "ignored",
+  "context": "backend service work",
+  "analogies": "shipping containers",
+  "concepts": ["**Images**: What they package", "**Containers**: How they run", "**Volumes**: Where data lives"],
+  "explanation_requirements": ["Show a Dockerfile", "Compare images and containers"],
+  "formatting": ["Use headers", "Use code blocks"],
+  "constraints": ["Assume Linux", "Skip Kubernetes"],
+  "output_format": ["Overview", "Cheat sheet"],
+  "purpose": "packaging services",
+  "tone": "practical"
+}`
+
+func TestProposeTopic(t *testing.T) {
+	draft := &fixedDraft{doc: draftJSON}
+	req := TopicRequest{Topic: "Docker", Audience: "backend devs", Stage: "intermediate"}
+
+	cfg, err := ProposeTopic(context.Background(), draft, req, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, draft.prompt, "Topic: Docker\nAudience: backend devs\nLearning stage: intermediate\n")
+	assert.Equal(t, "Docker", cfg.Topic, "the requested fields win over the model's")
+	assert.Equal(t, "backend devs", cfg.Audience)
+	assert.Equal(t, "intermediate", cfg.LearningStage)
+	assert.Equal(t, []string{"**Images**: What they package", "**Containers**: How they run", "**Volumes**: Where data lives"}, cfg.Concepts)
+	assert.Equal(t, "practical", cfg.Tone)
+	assert.Empty(t, ValidateTopic(cfg))
+}
+
+func TestProposeTopic_RejectsMalformedConcepts(t *testing.T) {
+	doc := `{"context": "c", "analogies": "a", "concepts": ["Images", "**B**: b", "**C**: c"],
+	  "explanation_requirements": ["x", "y"], "formatting": ["x", "y"], "constraints": ["x", "y"],
+	  "output_format": ["x", "y"], "purpose": "p", "tone": "t"}`
+	_, err := ProposeTopic(context.Background(), &fixedDraft{doc: doc}, TopicRequest{Topic: "Docker"}, 0)
+
+	var invalid *llm.SchemaValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Contains(t, invalid.Errors[0], `$.concepts[0]: value "Images" does not match pattern`)
+}
+
+func TestValidateTopic(t *testing.T) {
+	cfg := promptConfig.TopicConfig{
+		Audience:    "devs",
+		Topic:       "Docker",
+		Concepts:    []string{"**Images**: packaging", "Containers", "**images**: packaging"},
+		Constraints: []string{"Skip Kubernetes", " "},
+		Purpose:     "packaging",
+	}
+	assert.Equal(t, []string{
+		"learning_stage is empty",
+		"tone is empty",
+		`concept "Containers" should read "**Term**: what to explain"`,
+		`concepts lists "**images**: packaging" twice`,
+		"constraints has an empty entry",
+	}, ValidateTopic(cfg))
+
+	assert.Contains(t, ValidateTopic(promptConfig.TopicConfig{}), "concepts is empty")
+}
+
+func TestMarshalTopic(t *testing.T) {
+	// The bundled config is laid out the way MarshalTopic writes it.
+	data, err := os.ReadFile("../resources/configs/git.yaml")
+	require.NoError(t, err)
+	cfg, err := promptConfig.ReadTopicConfig("../resources/configs/git.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(data)+"\n", string(MarshalTopic(cfg)))
+
+	cfg = promptConfig.TopicConfig{Topic: `Say "hi"`, Tone: "line\nbreak", Concepts: []string{"**A**: ✨"}}
+	back, err := readTopic(t, MarshalTopic(cfg))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Topic, back.Topic)
+	assert.Equal(t, cfg.Tone, back.Tone)
+	assert.Equal(t, cfg.Concepts, back.Concepts)
+	assert.Empty(t, back.Formatting)
+}
+
+func readTopic(t *testing.T, data []byte) (promptConfig.TopicConfig, error) {
+	path := filepath.Join(t.TempDir(), "topic.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return promptConfig.ReadTopicConfig(path)
+}